@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListApprovals returns approvals with status (ApprovalPending if empty) ordered by deadline,
+// of bookings of a resource, or of all of them if resourceId is 0
+func (c *Client) ListApprovals(ctx context.Context, status string, resourceId int) ([]Approval, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if resourceId != 0 {
+		query.Set("resource_id", strconv.Itoa(resourceId))
+	}
+	var approvals []Approval
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/approvals", query: query}, &approvals)
+	return approvals, err
+}
+
+// ApproveBooking confirms pending booking id; reason is optional
+func (c *Client) ApproveBooking(ctx context.Context, id int, reason string) (Approval, error) {
+	var approval Approval
+	req := request{method: http.MethodPost, path: fmt.Sprintf("/approvals/%d/approve", id), body: Review{Reason: reason}}
+	_, err := c.do(ctx, req, &approval)
+	return approval, err
+}
+
+// RejectBooking cancels pending booking id free of charge for reason
+func (c *Client) RejectBooking(ctx context.Context, id int, reason string) (Approval, error) {
+	var approval Approval
+	req := request{method: http.MethodPost, path: fmt.Sprintf("/approvals/%d/reject", id), body: Review{Reason: reason}}
+	_, err := c.do(ctx, req, &approval)
+	return approval, err
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CreateBooking books a resource. If the slot is taken and waitlist is true the request joins the
+// waitlist instead, and the returned entry is not nil; without waitlist IsConflict(err) is true.
+func (c *Client) CreateBooking(ctx context.Context, booking Booking, waitlist bool) (*WaitlistEntry, error) {
+	query := url.Values{}
+	if waitlist {
+		query.Set("waitlist", "true")
+	}
+
+	var entry WaitlistEntry
+	status, err := c.do(ctx, request{method: http.MethodPost, path: "/booking", query: query, body: booking}, &entry)
+	if err != nil || status != http.StatusAccepted {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetBooking returns the booking with id
+func (c *Client) GetBooking(ctx context.Context, id int) (Booking, error) {
+	var booking Booking
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/booking/%d", id)}, &booking)
+	return booking, err
+}
+
+// ListBookings returns all bookings, cancelled ones only with includeCancelled
+func (c *Client) ListBookings(ctx context.Context, includeCancelled bool) ([]Booking, error) {
+	query := url.Values{}
+	if includeCancelled {
+		query.Set("include_cancelled", "true")
+	}
+
+	var bookings []Booking
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/bookings", query: query}, &bookings)
+	return bookings, err
+}
+
+// UpdateBooking changes resource, time or text of booking id; zero fields keep their values
+func (c *Client) UpdateBooking(ctx context.Context, id int, booking Booking) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/booking/%d", id), body: booking}, nil)
+	return err
+}
+
+// CancelBooking cancels booking id; the booking is kept with status cancelled. The cancellation
+// reports the fee of a late cancellation and the refund; it is zero if the booking did not exist
+// or was already cancelled.
+func (c *Client) CancelBooking(ctx context.Context, id int) (Cancellation, error) {
+	var cancellation Cancellation
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/booking/%d", id)}, &cancellation)
+	return cancellation, err
+}
+
+// ExtendBooking adds minutes to the end of booking id and returns the repriced booking. If the
+// following time is taken IsConflict(err) is true.
+func (c *Client) ExtendBooking(ctx context.Context, id, minutes int) (Booking, error) {
+	var booking Booking
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/booking/%d/extend", id), body: Extension{Minutes: minutes}}, &booking)
+	return booking, err
+}
+
+// EndBookingNow ends ongoing booking id at the current time and returns the repriced booking
+func (c *Client) EndBookingNow(ctx context.Context, id int) (Booking, error) {
+	var booking Booking
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/booking/%d/end-now", id)}, &booking)
+	return booking, err
+}
+
+// GetBookingHistory returns extensions, early ends, relocations and swaps of booking id, oldest first
+func (c *Client) GetBookingHistory(ctx context.Context, id int) ([]BookingChange, error) {
+	var changes []BookingChange
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/booking/%d/history", id)}, &changes)
+	return changes, err
+}
+
+// SwapBookings exchanges the resources of bookings a and b and returns both of them. If one does not
+// fit into the resource of the other IsConflict(err) is true.
+func (c *Client) SwapBookings(ctx context.Context, a, b int) ([]Booking, error) {
+	var bookings []Booking
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/bookings/swap", body: Swap{BookingIds: []int{a, b}}}, &bookings)
+	return bookings, err
+}
+
+// GetBookingCalendar returns booking id as an iCalendar file
+func (c *Client) GetBookingCalendar(ctx context.Context, id int) ([]byte, error) {
+	return c.read(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/booking/%d.ics", id)})
+}
+
+// GetCalendarFeed returns the iCalendar feed of the user with token
+func (c *Client) GetCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	return c.read(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/calendar/%s.ics", url.PathEscape(token))})
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ImportOptions are the options of an import
+type ImportOptions struct {
+	// Format is FormatCSV or FormatNDJSON (default)
+	Format string
+	// Mode is ImportModeAllOrNothing (default) or ImportModeBestEffort
+	Mode string
+	// DryRun only validates the records
+	DryRun bool
+}
+
+func (c *Client) importRecords(ctx context.Context, entity string, records io.Reader, opts ImportOptions) (ImportResult, error) {
+	query := url.Values{}
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if opts.Mode != "" {
+		query.Set("mode", opts.Mode)
+	}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+
+	contentType := "application/x-ndjson"
+	if opts.Format == FormatCSV {
+		contentType = "text/csv"
+	}
+
+	var result ImportResult
+	req := request{method: http.MethodPost, path: "/import/" + entity, query: query, body: records, contentType: contentType}
+	_, err := c.do(ctx, req, &result)
+
+	// rejected all-or-nothing imports come with the errors of the records
+	var e *Error
+	if errors.As(err, &e) {
+		json.Unmarshal(e.Body, &result)
+	}
+	return result, err
+}
+
+// ImportUsers imports user records read from records (admin). If the import is rejected the
+// result lists the errors of the records.
+func (c *Client) ImportUsers(ctx context.Context, records io.Reader, opts ImportOptions) (ImportResult, error) {
+	return c.importRecords(ctx, "users", records, opts)
+}
+
+// ImportBookings imports booking records read from records (admin). If the import is rejected
+// the result lists the errors of the records.
+func (c *Client) ImportBookings(ctx context.Context, records io.Reader, opts ImportOptions) (ImportResult, error) {
+	return c.importRecords(ctx, "bookings", records, opts)
+}
+
+func (c *Client) export(ctx context.Context, entity string, w io.Writer, format string) error {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/export/" + entity, query: query})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// ExportUsers writes all users to w in format, FormatNDJSON if it is empty (admin)
+func (c *Client) ExportUsers(ctx context.Context, w io.Writer, format string) error {
+	return c.export(ctx, "users", w, format)
+}
+
+// ExportBookings writes all bookings to w in format, FormatNDJSON if it is empty (admin)
+func (c *Client) ExportBookings(ctx context.Context, w io.Writer, format string) error {
+	return c.export(ctx, "bookings", w, format)
+}
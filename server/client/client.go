@@ -0,0 +1,306 @@
+// Package client is the Go client of the booking service REST API. Every route of the server has
+// a typed method; failed requests return *Error with the status code, message and violated policy
+// rules. GET and PUT requests are retried on network errors and 5xx responses, DELETE requests only
+// on network errors.
+//
+//	c := client.New("http://localhost:8000", client.WithBasicAuth("admin", "password"))
+//	bookings, err := c.ListBookings(ctx, false)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRetries is the number of times a failed idempotent request is repeated
+	DefaultRetries = 2
+	// DefaultBackoff is the pause before the first retry, doubled before every next one
+	DefaultBackoff = 200 * time.Millisecond
+	// DefaultTimeout limits requests of the default http client
+	DefaultTimeout = 30 * time.Second
+)
+
+// Client calls the booking service at a base url. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	username string
+	password string
+	zone     string
+
+	retries int
+	backoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBasicAuth authenticates requests as a user; required for staff and admin routes
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient replaces the default http client, e.g. to change timeouts or transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times failed idempotent requests are repeated and the pause before
+// the first retry; 0 disables retries
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithTimeZone asks the server to render times of responses in an IANA zone, e.g. Europe/Moscow
+func WithTimeZone(zone string) Option {
+	return func(c *Client) {
+		c.zone = zone
+	}
+}
+
+// New returns a client of the service at baseURL, e.g. http://localhost:8000
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a response of the service with an error status
+type Error struct {
+	StatusCode int
+	// Message is the error text of the server
+	Message string
+	// Violations are the policy rules broken by a booking
+	Violations []Violation
+	// Body is the raw response, which some routes fill with a result, e.g. conflicts of a series
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	if len(e.Violations) > 0 {
+		messages := make([]string, len(e.Violations))
+		for i, v := range e.Violations {
+			messages[i] = v.Error()
+		}
+		return fmt.Sprintf("booking service: %d: %s", e.StatusCode, strings.Join(messages, "; "))
+	}
+	return fmt.Sprintf("booking service: %d: %s", e.StatusCode, e.Message)
+}
+
+// IsConflict reports whether err is a time slot which is already taken
+func IsConflict(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusConflict
+}
+
+// IsInsufficientFunds reports whether err is a booking which costs more than the balance of its user
+func IsInsufficientFunds(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusPaymentRequired
+}
+
+// IsNotFound reports whether err is a request for an entry which does not exist. The service
+// reports those with 400 like other incorrect input, so the message is checked as well.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && (e.StatusCode == http.StatusNotFound ||
+		e.StatusCode == http.StatusBadRequest && strings.Contains(e.Message, "was found"))
+}
+
+// decodeError reads the error of resp. Policy violations are sent as their json list, other
+// errors as plain text.
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	e := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body)), Body: body}
+
+	var violations []Violation
+	if json.Unmarshal(body, &violations) == nil && len(violations) > 0 {
+		e.Violations = violations
+		e.Message = ""
+	}
+	if e.Message == "" && len(e.Violations) == 0 {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+// retryable reports whether a request with method may be repeated after status. A DELETE which
+// failed with 5xx may have cancelled a booking already, and repeating it would report a missing
+// entry instead of the cancellation, so it is only repeated if it got no response or 429.
+func retryable(method string, status int) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut:
+		return status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	case http.MethodDelete:
+		return status == 0 || status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// request describes a call of a route
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	// body is encoded as json unless it is an io.Reader
+	body any
+	// contentType of a reader body
+	contentType string
+}
+
+// send executes req, retrying idempotent requests, and returns the response with a successful
+// status. The caller closes its body.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var payload []byte
+	var reader io.Reader
+	contentType := req.contentType
+
+	switch body := req.body.(type) {
+	case nil:
+	case io.Reader:
+		// a stream can not be read twice, so it is sent once
+		reader = body
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("booking service: failed to encode request: %w", err)
+		}
+		contentType = "application/json"
+	}
+
+	query := req.query
+	if c.zone != "" {
+		query = cloneQuery(query)
+		query.Set("tz", c.zone)
+	}
+	target := c.baseURL + req.path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		body := reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range req.header {
+			httpReq.Header[key] = values
+		}
+		if contentType != "" {
+			httpReq.Header.Set("Content-Type", contentType)
+		}
+		if c.username != "" {
+			httpReq.SetBasicAuth(c.username, c.password)
+		}
+
+		status := 0
+		resp, err := c.httpClient.Do(httpReq)
+		if err == nil {
+			if resp.StatusCode < http.StatusBadRequest {
+				return resp, nil
+			}
+			status = resp.StatusCode
+			err = decodeError(resp)
+			resp.Body.Close()
+		}
+
+		if attempt >= c.retries || reader != nil || !retryable(req.method, status) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// do executes req and decodes the json response into out, unless out is nil or there is no content.
+// It returns the status code of the response.
+func (c *Client) do(ctx context.Context, req request, out any) (int, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return resp.StatusCode, fmt.Errorf("booking service: failed to decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range query {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// timeQuery adds t to query under key unless it is zero
+func timeQuery(query url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		query.Set(key, t.Format(time.RFC3339))
+	}
+}
+
+// read executes req and returns the whole response, e.g. a calendar
+func (c *Client) read(ctx context.Context, req request) ([]byte, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// resourceQuery filters lists by resource, unless resourceId is 0
+func resourceQuery(resourceId int) url.Values {
+	query := url.Values{}
+	if resourceId != 0 {
+		query.Set("resource_id", strconv.Itoa(resourceId))
+	}
+	return query
+}
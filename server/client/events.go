@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AvailabilityEvent is a change of availability of a resource
+type AvailabilityEvent struct {
+	// Id is passed as lastEventId to resume a stream after this event
+	Id int64
+	// Type is resource.status
+	Type   string
+	Status ResourceStatus
+}
+
+// StreamAvailability calls handle with availability events of resources in zone, or of resource
+// resourceId, until ctx is done, the stream ends or handle returns an error. Empty zone and 0
+// resourceId match everything. With lastEventId the events after it are sent first.
+func (c *Client) StreamAvailability(ctx context.Context, zone string, resourceId int, lastEventId int64, handle func(AvailabilityEvent) error) error {
+	query := url.Values{}
+	if zone != "" {
+		query.Set("zone", zone)
+	}
+	if resourceId != 0 {
+		query.Set("resource_id", strconv.Itoa(resourceId))
+	}
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if lastEventId != 0 {
+		header.Set("Last-Event-ID", strconv.FormatInt(lastEventId, 10))
+	}
+
+	// the stream is long-lived, so the timeout of the http client does not apply
+	stream := *c
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	stream.httpClient = &httpClient
+
+	resp, err := stream.send(ctx, request{method: http.MethodGet, path: "/events/availability", query: query, header: header})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var event AvailabilityEvent
+	var data []byte
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		field, value, _ := strings.Cut(scanner.Text(), ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			event.Id, _ = strconv.ParseInt(value, 10, 64)
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value...)
+		case "":
+			// an empty line ends an event, comments (heartbeats) have an empty field
+			if event.Type == "" || scanner.Text() != "" {
+				continue
+			}
+			if err := json.Unmarshal(data, &event.Status); err != nil {
+				return err
+			}
+			if err := handle(event); err != nil {
+				return err
+			}
+			event = AvailabilityEvent{}
+			data = nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
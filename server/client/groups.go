@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateGroup books all resources of group for the same interval at once. If any of them could not
+// be booked nothing is, the error is a conflict and the conflicts are returned with it.
+func (c *Client) CreateGroup(ctx context.Context, group BookingGroup) (BookingGroup, []Conflict, error) {
+	var created BookingGroup
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/groups", body: group}, &created)
+
+	var conflicts []Conflict
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusConflict {
+		json.Unmarshal(e.Body, &conflicts)
+	}
+	return created, conflicts, err
+}
+
+// GetGroup returns group id with its bookings and participants
+func (c *Client) GetGroup(ctx context.Context, id int) (BookingGroup, error) {
+	var group BookingGroup
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/groups/%d", id)}, &group)
+	return group, err
+}
+
+// ListGroups returns groups organised by user userId or which they are invited to; 0 is the
+// authenticated user
+func (c *Client) ListGroups(ctx context.Context, userId int) ([]BookingGroup, error) {
+	query := url.Values{}
+	if userId != 0 {
+		query.Set("user_id", strconv.Itoa(userId))
+	}
+	var groups []BookingGroup
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/groups", query: query}, &groups)
+	return groups, err
+}
+
+// CancelGroup cancels the bookings of group id which have not started yet, deletes the group and
+// returns the cancellations
+func (c *Client) CancelGroup(ctx context.Context, id int) ([]Cancellation, error) {
+	var cancellations []Cancellation
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/groups/%d", id)}, &cancellations)
+	return cancellations, err
+}
+
+// InviteToGroup invites user userId to group id
+func (c *Client) InviteToGroup(ctx context.Context, id, userId int) (GroupParticipant, error) {
+	var participant GroupParticipant
+	req := request{method: http.MethodPost, path: fmt.Sprintf("/groups/%d/participants", id), body: GroupParticipant{UserId: userId}}
+	_, err := c.do(ctx, req, &participant)
+	return participant, err
+}
+
+// RespondToGroup accepts or declines the invitation of user userId to group id with status
+// ParticipantAccepted or ParticipantDeclined
+func (c *Client) RespondToGroup(ctx context.Context, id, userId int, status string) (GroupParticipant, error) {
+	var participant GroupParticipant
+	req := request{method: http.MethodPut, path: fmt.Sprintf("/groups/%d/participants/%d", id, userId), body: GroupParticipant{UserId: userId, Status: status}}
+	_, err := c.do(ctx, req, &participant)
+	return participant, err
+}
+
+// RemoveFromGroup removes user userId from the participants of group id
+func (c *Client) RemoveFromGroup(ctx context.Context, id, userId int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/groups/%d/participants/%d", id, userId)}, nil)
+	return err
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GetBalance returns the prepaid balance of user id (the user themselves or staff)
+func (c *Client) GetBalance(ctx context.Context, userId int) (Balance, error) {
+	var balance Balance
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d/balance", userId)}, &balance)
+	return balance, err
+}
+
+// ListLedgerEntries returns the ledger of user id, latest entries first (the user themselves or staff)
+func (c *Client) ListLedgerEntries(ctx context.Context, userId int) ([]LedgerEntry, error) {
+	var entries []LedgerEntry
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d/ledger", userId)}, &entries)
+	return entries, err
+}
+
+// TopUp credits the balance of user id with a positive amount (staff)
+func (c *Client) TopUp(ctx context.Context, userId int, change BalanceChange) (LedgerEntry, error) {
+	var entry LedgerEntry
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/user/%d/top-up", userId), body: change}, &entry)
+	return entry, err
+}
+
+// AdjustBalance corrects the balance of user id by a positive or negative amount (admin)
+func (c *Client) AdjustBalance(ctx context.Context, userId int, change BalanceChange) (LedgerEntry, error) {
+	var entry LedgerEntry
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/user/%d/adjustment", userId), body: change}, &entry)
+	return entry, err
+}
+
+// ListAccountBalances returns totals of the venue accounts and of all user balances (admin)
+func (c *Client) ListAccountBalances(ctx context.Context) ([]AccountBalance, error) {
+	var accounts []AccountBalance
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/ledger/accounts"}, &accounts)
+	return accounts, err
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// AddPolicyRule adds a booking policy rule (admin)
+func (c *Client) AddPolicyRule(ctx context.Context, rule PolicyRule) (PolicyRule, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/policy-rules", body: rule}, &rule)
+	return rule, err
+}
+
+// GetPolicyRule returns the policy rule with id
+func (c *Client) GetPolicyRule(ctx context.Context, id int) (PolicyRule, error) {
+	var rule PolicyRule
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/policy-rules/%d", id)}, &rule)
+	return rule, err
+}
+
+// ListPolicyRules returns policy rules applying to a resource type and role; empty values list
+// the rules of every scope
+func (c *Client) ListPolicyRules(ctx context.Context, resourceType, role string) ([]PolicyRule, error) {
+	query := url.Values{}
+	if resourceType != "" {
+		query.Set("resource_type", resourceType)
+	}
+	if role != "" {
+		query.Set("role", role)
+	}
+
+	var rules []PolicyRule
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/policy-rules", query: query}, &rules)
+	return rules, err
+}
+
+// UpdatePolicyRule replaces policy rule id (admin)
+func (c *Client) UpdatePolicyRule(ctx context.Context, id int, rule PolicyRule) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/policy-rules/%d", id), body: rule}, nil)
+	return err
+}
+
+// DeletePolicyRule deletes policy rule id (admin)
+func (c *Client) DeletePolicyRule(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/policy-rules/%d", id)}, nil)
+	return err
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Quote prices a booking without creating it
+func (c *Client) Quote(ctx context.Context, req QuoteRequest) (Quote, error) {
+	var quote Quote
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/quote", body: req}, &quote)
+	return quote, err
+}
+
+// AddTariff adds a tariff (admin)
+func (c *Client) AddTariff(ctx context.Context, tariff Tariff) (Tariff, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/tariffs", body: tariff}, &tariff)
+	return tariff, err
+}
+
+// GetTariff returns the tariff with id
+func (c *Client) GetTariff(ctx context.Context, id int) (Tariff, error) {
+	var tariff Tariff
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/tariffs/%d", id)}, &tariff)
+	return tariff, err
+}
+
+// ListTariffs returns tariffs applying to a resource type; an empty type lists all tariffs
+func (c *Client) ListTariffs(ctx context.Context, resourceType string) ([]Tariff, error) {
+	query := url.Values{}
+	if resourceType != "" {
+		query.Set("resource_type", resourceType)
+	}
+
+	var tariffs []Tariff
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/tariffs", query: query}, &tariffs)
+	return tariffs, err
+}
+
+// UpdateTariff replaces tariff id (admin)
+func (c *Client) UpdateTariff(ctx context.Context, id int, tariff Tariff) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/tariffs/%d", id), body: tariff}, nil)
+	return err
+}
+
+// DeleteTariff deletes tariff id (admin)
+func (c *Client) DeleteTariff(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/tariffs/%d", id)}, nil)
+	return err
+}
+
+// AddDiscount adds a discount (admin)
+func (c *Client) AddDiscount(ctx context.Context, discount Discount) (Discount, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/discounts", body: discount}, &discount)
+	return discount, err
+}
+
+// ListDiscounts returns discounts applying to a role; an empty role lists all discounts
+func (c *Client) ListDiscounts(ctx context.Context, role string) ([]Discount, error) {
+	query := url.Values{}
+	if role != "" {
+		query.Set("role", role)
+	}
+
+	var discounts []Discount
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/discounts", query: query}, &discounts)
+	return discounts, err
+}
+
+// UpdateDiscount replaces discount id (admin)
+func (c *Client) UpdateDiscount(ctx context.Context, id int, discount Discount) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/discounts/%d", id), body: discount}, nil)
+	return err
+}
+
+// DeleteDiscount deletes discount id (admin)
+func (c *Client) DeleteDiscount(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/discounts/%d", id)}, nil)
+	return err
+}
+
+// AddCancellationRule adds a cancellation rule (admin)
+func (c *Client) AddCancellationRule(ctx context.Context, rule CancellationRule) (CancellationRule, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/cancellation-rules", body: rule}, &rule)
+	return rule, err
+}
+
+// GetCancellationRule returns the cancellation rule with id
+func (c *Client) GetCancellationRule(ctx context.Context, id int) (CancellationRule, error) {
+	var rule CancellationRule
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/cancellation-rules/%d", id)}, &rule)
+	return rule, err
+}
+
+// ListCancellationRules returns cancellation rules applying to a resource type; an empty type lists all rules
+func (c *Client) ListCancellationRules(ctx context.Context, resourceType string) ([]CancellationRule, error) {
+	query := url.Values{}
+	if resourceType != "" {
+		query.Set("resource_type", resourceType)
+	}
+
+	var rules []CancellationRule
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/cancellation-rules", query: query}, &rules)
+	return rules, err
+}
+
+// UpdateCancellationRule replaces cancellation rule id (admin)
+func (c *Client) UpdateCancellationRule(ctx context.Context, id int, rule CancellationRule) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/cancellation-rules/%d", id), body: rule}, nil)
+	return err
+}
+
+// DeleteCancellationRule deletes cancellation rule id (admin)
+func (c *Client) DeleteCancellationRule(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/cancellation-rules/%d", id)}, nil)
+	return err
+}
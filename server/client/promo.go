@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AddPromoCode adds a promo code (admin)
+func (c *Client) AddPromoCode(ctx context.Context, code PromoCode) (PromoCode, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/promo-codes", body: code}, &code)
+	return code, err
+}
+
+// GetPromoCode returns the promo code with id and the number of its redemptions (staff)
+func (c *Client) GetPromoCode(ctx context.Context, id int) (PromoCode, error) {
+	var code PromoCode
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/promo-codes/%d", id)}, &code)
+	return code, err
+}
+
+// ListPromoCodes returns all promo codes (staff)
+func (c *Client) ListPromoCodes(ctx context.Context) ([]PromoCode, error) {
+	var codes []PromoCode
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/promo-codes"}, &codes)
+	return codes, err
+}
+
+// UpdatePromoCode replaces promo code id (admin)
+func (c *Client) UpdatePromoCode(ctx context.Context, id int, code PromoCode) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/promo-codes/%d", id), body: code}, nil)
+	return err
+}
+
+// DeletePromoCode deletes promo code id unless it was redeemed (admin)
+func (c *Client) DeletePromoCode(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/promo-codes/%d", id)}, nil)
+	return err
+}
+
+// ListPromoRedemptions returns redemptions of promo code id made within [from, to), latest first;
+// zero times are no bound (staff)
+func (c *Client) ListPromoRedemptions(ctx context.Context, id int, from, to time.Time) ([]PromoRedemption, error) {
+	query := url.Values{}
+	timeQuery(query, "from", from)
+	timeQuery(query, "to", to)
+
+	var redemptions []PromoRedemption
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/promo-codes/%d/redemptions", id), query: query}, &redemptions)
+	return redemptions, err
+}
+
+// AddMembershipPlan adds a membership plan (admin)
+func (c *Client) AddMembershipPlan(ctx context.Context, plan MembershipPlan) (MembershipPlan, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/membership-plans", body: plan}, &plan)
+	return plan, err
+}
+
+// ListMembershipPlans returns all membership plans
+func (c *Client) ListMembershipPlans(ctx context.Context) ([]MembershipPlan, error) {
+	var plans []MembershipPlan
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/membership-plans"}, &plans)
+	return plans, err
+}
+
+// UpdateMembershipPlan replaces membership plan id (admin)
+func (c *Client) UpdateMembershipPlan(ctx context.Context, id int, plan MembershipPlan) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/membership-plans/%d", id), body: plan}, nil)
+	return err
+}
+
+// DeleteMembershipPlan deletes membership plan id unless it has memberships (admin)
+func (c *Client) DeleteMembershipPlan(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/membership-plans/%d", id)}, nil)
+	return err
+}
+
+// AddMembership subscribes a user to a membership plan (staff)
+func (c *Client) AddMembership(ctx context.Context, membership Membership) (Membership, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/memberships", body: membership}, &membership)
+	return membership, err
+}
+
+// GetMembership returns membership id with its usage in period (YYYY-MM); an empty period is
+// the current month (staff)
+func (c *Client) GetMembership(ctx context.Context, id int, period string) (Membership, error) {
+	query := url.Values{}
+	if period != "" {
+		query.Set("period", period)
+	}
+
+	var membership Membership
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/memberships/%d", id), query: query}, &membership)
+	return membership, err
+}
+
+// ListMemberships returns memberships of user id, or of all users if it is 0, with their usage in
+// period (YYYY-MM); an empty period is the current month (staff)
+func (c *Client) ListMemberships(ctx context.Context, userId int, period string) ([]Membership, error) {
+	query := url.Values{}
+	if userId != 0 {
+		query.Set("user_id", strconv.Itoa(userId))
+	}
+	if period != "" {
+		query.Set("period", period)
+	}
+
+	var memberships []Membership
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/memberships", query: query}, &memberships)
+	return memberships, err
+}
+
+// UpdateMembership replaces membership id, e.g. to end it (staff)
+func (c *Client) UpdateMembership(ctx context.Context, id int, membership Membership) (Membership, error) {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/memberships/%d", id), body: membership}, &membership)
+	return membership, err
+}
+
+// DeleteMembership deletes membership id (staff)
+func (c *Client) DeleteMembership(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/memberships/%d", id)}, nil)
+	return err
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CreateResource adds a bookable resource and returns it with its id
+func (c *Client) CreateResource(ctx context.Context, resource Resource) (Resource, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/resource", body: resource}, &resource)
+	return resource, err
+}
+
+// GetResource returns the resource with id
+func (c *Client) GetResource(ctx context.Context, id int) (Resource, error) {
+	var resource Resource
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/resource/%d", id)}, &resource)
+	return resource, err
+}
+
+// ListResources returns all resources
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/resources"}, &resources)
+	return resources, err
+}
+
+// UpdateResource changes name, type or zone of resource id; empty fields keep their values
+func (c *Client) UpdateResource(ctx context.Context, id int, resource Resource) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/resource/%d", id), body: resource}, nil)
+	return err
+}
+
+// DeleteResource deletes resource id
+func (c *Client) DeleteResource(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/resource/%d", id)}, nil)
+	return err
+}
+
+// GetAvailability returns free intervals of resource id between from and to with the places left
+// during each of them, leaving out intervals where fewer than partySize places are free. Zero from
+// is now, zero to is a day after from, zero partySize is 1.
+func (c *Client) GetAvailability(ctx context.Context, id int, from, to time.Time, partySize int) ([]Slot, error) {
+	query := url.Values{}
+	timeQuery(query, "from", from)
+	timeQuery(query, "to", to)
+	if partySize != 0 {
+		query.Set("party_size", strconv.Itoa(partySize))
+	}
+
+	var free []Slot
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/resource/%d/availability", id), query: query}, &free)
+	return free, err
+}
+
+// RelocateBookings moves bookings of resource id selected by rel to compatible resources; with dryRun
+// the moves are only previewed. A rejected all-or-nothing relocation is a conflict and its result
+// with the conflicts is returned with the error.
+func (c *Client) RelocateBookings(ctx context.Context, id int, rel Relocation, dryRun bool) (RelocationResult, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+
+	var result RelocationResult
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/resource/%d/relocate", id), query: query, body: rel}, &result)
+
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusConflict {
+		json.Unmarshal(e.Body, &result)
+	}
+	return result, err
+}
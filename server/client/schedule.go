@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// AddOpeningHours adds weekly opening hours, of a resource if ResourceId is set (admin)
+func (c *Client) AddOpeningHours(ctx context.Context, hours OpeningHours) (OpeningHours, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/opening-hours", body: hours}, &hours)
+	return hours, err
+}
+
+// ListOpeningHours returns opening hours of a resource, or all of them if resourceId is 0
+func (c *Client) ListOpeningHours(ctx context.Context, resourceId int) ([]OpeningHours, error) {
+	var hours []OpeningHours
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/opening-hours", query: resourceQuery(resourceId)}, &hours)
+	return hours, err
+}
+
+// UpdateOpeningHours replaces opening hours id (admin)
+func (c *Client) UpdateOpeningHours(ctx context.Context, id int, hours OpeningHours) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/opening-hours/%d", id), body: hours}, nil)
+	return err
+}
+
+// DeleteOpeningHours deletes opening hours id (admin)
+func (c *Client) DeleteOpeningHours(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/opening-hours/%d", id)}, nil)
+	return err
+}
+
+// AddScheduleException adds a holiday or changed hours of a date (admin)
+func (c *Client) AddScheduleException(ctx context.Context, exception ScheduleException) (ScheduleException, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/schedule-exceptions", body: exception}, &exception)
+	return exception, err
+}
+
+// ListScheduleExceptions returns schedule exceptions of a resource, or all of them if resourceId is 0
+func (c *Client) ListScheduleExceptions(ctx context.Context, resourceId int) ([]ScheduleException, error) {
+	var exceptions []ScheduleException
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/schedule-exceptions", query: resourceQuery(resourceId)}, &exceptions)
+	return exceptions, err
+}
+
+// UpdateScheduleException replaces schedule exception id (admin)
+func (c *Client) UpdateScheduleException(ctx context.Context, id int, exception ScheduleException) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/schedule-exceptions/%d", id), body: exception}, nil)
+	return err
+}
+
+// DeleteScheduleException deletes schedule exception id (admin)
+func (c *Client) DeleteScheduleException(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/schedule-exceptions/%d", id)}, nil)
+	return err
+}
+
+// AddMaintenanceWindow blocks a resource for maintenance (staff)
+func (c *Client) AddMaintenanceWindow(ctx context.Context, window MaintenanceWindow) (MaintenanceWindow, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/maintenance", body: window}, &window)
+	return window, err
+}
+
+// ListMaintenanceWindows returns maintenance windows of a resource, or all of them if resourceId is 0
+func (c *Client) ListMaintenanceWindows(ctx context.Context, resourceId int) ([]MaintenanceWindow, error) {
+	var windows []MaintenanceWindow
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/maintenance", query: resourceQuery(resourceId)}, &windows)
+	return windows, err
+}
+
+// UpdateMaintenanceWindow replaces maintenance window id (staff)
+func (c *Client) UpdateMaintenanceWindow(ctx context.Context, id int, window MaintenanceWindow) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/maintenance/%d", id), body: window}, nil)
+	return err
+}
+
+// DeleteMaintenanceWindow deletes maintenance window id (staff)
+func (c *Client) DeleteMaintenanceWindow(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/maintenance/%d", id)}, nil)
+	return err
+}
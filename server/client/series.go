@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// seriesResult keeps the conflicts the server sends with 409 when no occurrence could be booked
+func seriesResult(result SeriesResult, err error) (SeriesResult, error) {
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusConflict {
+		json.Unmarshal(e.Body, &result)
+	}
+	return result, err
+}
+
+func scopeQuery(scope string) url.Values {
+	query := url.Values{}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	return query
+}
+
+// CreateSeries books the occurrences of a recurring series. If none of them could be booked the
+// error is a conflict and the result lists the conflicting occurrences.
+func (c *Client) CreateSeries(ctx context.Context, series BookingSeries) (SeriesResult, error) {
+	var result SeriesResult
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/series", body: series}, &result)
+	return seriesResult(result, err)
+}
+
+// GetSeries returns series id with its bookings
+func (c *Client) GetSeries(ctx context.Context, id int) (SeriesResult, error) {
+	var result SeriesResult
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/series/%d", id)}, &result)
+	return result, err
+}
+
+// UpdateSeries changes all future occurrences of series id; zero fields keep their values
+func (c *Client) UpdateSeries(ctx context.Context, id int, series BookingSeries) (SeriesResult, error) {
+	var result SeriesResult
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/series/%d", id), body: series}, &result)
+	return seriesResult(result, err)
+}
+
+// DeleteSeries cancels all future occurrences of series id and returns their cancellations
+func (c *Client) DeleteSeries(ctx context.Context, id int) ([]Cancellation, error) {
+	var cancellations []Cancellation
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/series/%d", id)}, &cancellations)
+	return cancellations, err
+}
+
+// UpdateOccurrence changes occurrence bookingId of series id, with ScopeFollowing also all
+// occurrences after it; empty scope is ScopeThis
+func (c *Client) UpdateOccurrence(ctx context.Context, id, bookingId int, scope string, patch BookingSeries) (SeriesResult, error) {
+	var result SeriesResult
+	req := request{method: http.MethodPut, path: fmt.Sprintf("/series/%d/occurrence/%d", id, bookingId), query: scopeQuery(scope), body: patch}
+	_, err := c.do(ctx, req, &result)
+	return seriesResult(result, err)
+}
+
+// DeleteOccurrence cancels occurrence bookingId of series id, with ScopeFollowing also all
+// occurrences after it; empty scope is ScopeThis. It returns the cancellations of the occurrences.
+func (c *Client) DeleteOccurrence(ctx context.Context, id, bookingId int, scope string) ([]Cancellation, error) {
+	req := request{method: http.MethodDelete, path: fmt.Sprintf("/series/%d/occurrence/%d", id, bookingId), query: scopeQuery(scope)}
+	var cancellations []Cancellation
+	_, err := c.do(ctx, req, &cancellations)
+	return cancellations, err
+}
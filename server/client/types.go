@@ -0,0 +1,146 @@
+package client
+
+import (
+	"github.com/alexey-dobry/booking-service/server/internal/bulk"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/policy"
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+)
+
+// The entities are those of the server, so that their json always matches the API
+type (
+	User              = models.User
+	UserRecord        = models.UserRecord
+	Resource          = models.Resource
+	ResourceStatus    = models.ResourceStatus
+	Booking           = models.Booking
+	BookingChange     = models.BookingChange
+	Extension         = models.Extension
+	Relocation        = models.Relocation
+	Move              = models.Move
+	RelocationResult  = models.RelocationResult
+	Swap              = models.Swap
+	BookingSeries     = models.BookingSeries
+	SeriesResult      = models.SeriesResult
+	Conflict          = models.Conflict
+	BookingGroup      = models.BookingGroup
+	GroupParticipant  = models.GroupParticipant
+	Approval          = models.Approval
+	Review            = models.Review
+	WaitlistEntry     = models.WaitlistEntry
+	OpeningHours      = models.OpeningHours
+	ScheduleException = models.ScheduleException
+	MaintenanceWindow = models.MaintenanceWindow
+	PolicyRule        = models.PolicyRule
+	Violation         = policy.Violation
+	Interval          = schedule.Interval
+	Slot              = schedule.Slot
+	CalendarFeed      = models.CalendarFeed
+	ImportResult      = models.ImportResult
+	RecordError       = models.RecordError
+	Webhook           = models.Webhook
+	WebhookDelivery   = models.WebhookDelivery
+	Tariff            = models.Tariff
+	Discount          = models.Discount
+	QuoteRequest      = models.QuoteRequest
+	Quote             = pricing.Quote
+	PriceLine         = pricing.Line
+	AppliedDiscount   = pricing.AppliedDiscount
+	LedgerEntry       = models.LedgerEntry
+	Posting           = models.Posting
+	Balance           = models.Balance
+	BalanceChange     = models.BalanceChange
+	AccountBalance    = models.AccountBalance
+	CancellationRule  = models.CancellationRule
+	Cancellation      = models.Cancellation
+	PromoCode         = models.PromoCode
+	PromoRedemption   = models.PromoRedemption
+	AppliedPromo      = pricing.AppliedPromo
+	MembershipPlan    = models.MembershipPlan
+	Membership        = models.Membership
+	AppliedMembership = pricing.AppliedMembership
+)
+
+// User roles
+const (
+	RoleMember = models.RoleMember
+	RoleStaff  = models.RoleStaff
+	RoleAdmin  = models.RoleAdmin
+)
+
+// Booking statuses
+const (
+	BookingConfirmed = models.BookingConfirmed
+	BookingHeld      = models.BookingHeld
+	BookingPending   = models.BookingPending
+	BookingCancelled = models.BookingCancelled
+)
+
+// Statuses of approvals
+const (
+	ApprovalPending   = models.ApprovalPending
+	ApprovalApproved  = models.ApprovalApproved
+	ApprovalRejected  = models.ApprovalRejected
+	ApprovalWithdrawn = models.ApprovalWithdrawn
+)
+
+// Kinds of booking changes
+const (
+	ChangeExtended   = models.ChangeExtended
+	ChangeEndedEarly = models.ChangeEndedEarly
+	ChangeRelocated  = models.ChangeRelocated
+	ChangeSwapped    = models.ChangeSwapped
+)
+
+// Modes of relocations
+const (
+	RelocationModeAllOrNothing  = models.RelocationModeAllOrNothing
+	RelocationModeSkipConflicts = models.RelocationModeSkipConflicts
+)
+
+// Scopes of changes to an occurrence of a series
+const (
+	ScopeThis      = models.ScopeThis
+	ScopeFollowing = models.ScopeFollowing
+)
+
+// Statuses of group participants
+const (
+	ParticipantInvited  = models.ParticipantInvited
+	ParticipantAccepted = models.ParticipantAccepted
+	ParticipantDeclined = models.ParticipantDeclined
+)
+
+// Days of tariffs
+const (
+	DaysAll      = pricing.DaysAll
+	DaysWeekdays = pricing.DaysWeekdays
+	DaysWeekends = pricing.DaysWeekends
+)
+
+// Kinds of promo codes
+const (
+	PromoPercent = pricing.PromoPercent
+	PromoFixed   = pricing.PromoFixed
+)
+
+// Kinds of ledger entries
+const (
+	LedgerTopUp      = models.LedgerTopUp
+	LedgerCharge     = models.LedgerCharge
+	LedgerRefund     = models.LedgerRefund
+	LedgerAdjustment = models.LedgerAdjustment
+)
+
+// Modes of imports
+const (
+	ImportModeAllOrNothing = models.ImportModeAllOrNothing
+	ImportModeBestEffort   = models.ImportModeBestEffort
+)
+
+// Formats of imports and exports
+const (
+	FormatCSV    = bulk.FormatCSV
+	FormatNDJSON = bulk.FormatNDJSON
+)
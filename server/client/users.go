@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CreateUser registers a user with username and password
+func (c *Client) CreateUser(ctx context.Context, user User) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/user", body: user}, nil)
+	return err
+}
+
+// GetUser returns the user with id
+func (c *Client) GetUser(ctx context.Context, id int) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d", id)}, &user)
+	return user, err
+}
+
+// ListUsers returns all users
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/users"}, &users)
+	return users, err
+}
+
+// UpdateUser changes username and password of user id; empty fields keep their values
+func (c *Client) UpdateUser(ctx context.Context, id int, user User) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/user/%d", id), body: user}, nil)
+	return err
+}
+
+// DeleteUser deletes user id
+func (c *Client) DeleteUser(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/user/%d", id)}, nil)
+	return err
+}
+
+// SetUserPriority marks user id as served first from waitlists (staff)
+func (c *Client) SetUserPriority(ctx context.Context, id int, priority bool) error {
+	body := map[string]bool{"priority": priority}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/user/%d/priority", id), body: body}, nil)
+	return err
+}
+
+// SetUserRole changes role of user id to RoleMember, RoleStaff or RoleAdmin (admin)
+func (c *Client) SetUserRole(ctx context.Context, id int, role string) error {
+	body := map[string]string{"role": role}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/user/%d/role", id), body: body}, nil)
+	return err
+}
+
+// GetCalendarToken returns the calendar feed of user id, creating its token if needed
+func (c *Client) GetCalendarToken(ctx context.Context, userId int) (CalendarFeed, error) {
+	var feed CalendarFeed
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d/calendar-token", userId)}, &feed)
+	return feed, err
+}
+
+// ResetCalendarToken replaces the calendar token of user id, so that the old feed url stops working
+func (c *Client) ResetCalendarToken(ctx context.Context, userId int) (CalendarFeed, error) {
+	var feed CalendarFeed
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/user/%d/calendar-token", userId)}, &feed)
+	return feed, err
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// JoinWaitlist queues a request for a taken time slot
+func (c *Client) JoinWaitlist(ctx context.Context, entry WaitlistEntry) (WaitlistEntry, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/waitlist", body: entry}, &entry)
+	return entry, err
+}
+
+// ListWaitlist returns waitlist entries of a resource, or all of them if resourceId is 0.
+// Members only get their own entries.
+func (c *Client) ListWaitlist(ctx context.Context, resourceId int) ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/waitlist", query: resourceQuery(resourceId)}, &entries)
+	return entries, err
+}
+
+// GetWaitlistEntry returns the waitlist entry with id
+func (c *Client) GetWaitlistEntry(ctx context.Context, id int) (WaitlistEntry, error) {
+	var entry WaitlistEntry
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/waitlist/%d", id)}, &entry)
+	return entry, err
+}
+
+// ConfirmWaitlistEntry accepts the slot offered to entry id and returns the confirmed booking
+func (c *Client) ConfirmWaitlistEntry(ctx context.Context, id int) (Booking, error) {
+	var booking Booking
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/waitlist/%d/confirm", id)}, &booking)
+	return booking, err
+}
+
+// DeleteWaitlistEntry leaves the waitlist, releasing a slot held for entry id
+func (c *Client) DeleteWaitlistEntry(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/waitlist/%d", id)}, nil)
+	return err
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CreateWebhook subscribes a url to events (admin)
+func (c *Client) CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/webhooks", body: webhook}, &webhook)
+	return webhook, err
+}
+
+// GetWebhook returns the webhook with id (admin)
+func (c *Client) GetWebhook(ctx context.Context, id int) (Webhook, error) {
+	var webhook Webhook
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/webhooks/%d", id)}, &webhook)
+	return webhook, err
+}
+
+// ListWebhooks returns all webhooks (admin)
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks"}, &webhooks)
+	return webhooks, err
+}
+
+// UpdateWebhook replaces url, events and disabled flag of webhook id, and its secret if set (admin)
+func (c *Client) UpdateWebhook(ctx context.Context, id int, webhook Webhook) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/webhooks/%d", id), body: webhook}, nil)
+	return err
+}
+
+// DeleteWebhook deletes webhook id with its deliveries (admin)
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/webhooks/%d", id)}, nil)
+	return err
+}
+
+// ListWebhookDeliveries returns the latest deliveries of webhook id, only those with status unless
+// it is empty (admin)
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int, status string) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var deliveries []WebhookDelivery
+	_, err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/webhooks/%d/deliveries", id), query: query}, &deliveries)
+	return deliveries, err
+}
+
+// ListDeadDeliveries returns deliveries which failed every attempt (admin)
+func (c *Client) ListDeadDeliveries(ctx context.Context) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/webhook-deliveries/dead"}, &deliveries)
+	return deliveries, err
+}
+
+// RetryDelivery queues delivery id again with a fresh set of attempts (admin)
+func (c *Client) RetryDelivery(ctx context.Context, id int64) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/webhook-deliveries/%d/retry", id)}, nil)
+	return err
+}
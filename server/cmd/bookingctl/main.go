@@ -0,0 +1,284 @@
+// Command bookingctl manages bookings of the booking service from the terminal
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/client"
+)
+
+const usage = `Usage:
+  bookingctl [flags] bookings list [-all] [-user id] [-resource id]
+  bookingctl [flags] bookings get id
+  bookingctl [flags] bookings create -user id -resource id -start time -end time -text text [-party n] [-promo code] [-waitlist]
+  bookingctl [flags] bookings cancel id
+  bookingctl [flags] bookings extend -minutes n id
+  bookingctl [flags] bookings end id
+
+Times are RFC 3339, e.g. 2025-03-01T14:00:00+03:00.
+
+Flags:
+`
+
+// timeLayout is the layout of times in tables
+const timeLayout = "2006-01-02 15:04 -07:00"
+
+// errUsage is returned for unknown commands and arguments
+var errUsage = errors.New("wrong command line arguments")
+
+// cli holds global flags of a command
+type cli struct {
+	client *client.Client
+	output string
+	stdout io.Writer
+}
+
+func main() {
+	flags := flag.NewFlagSet("bookingctl", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	baseURL := flags.String("url", envOr("BOOKING_URL", "http://localhost:8000"), "service url, $BOOKING_URL")
+	username := flags.String("username", os.Getenv("BOOKING_USERNAME"), "basic auth user, $BOOKING_USERNAME")
+	password := flags.String("password", os.Getenv("BOOKING_PASSWORD"), "basic auth password, $BOOKING_PASSWORD")
+	zone := flags.String("tz", "", "IANA zone of printed times, e.g. Europe/Moscow")
+	output := flags.String("o", "table", "output format: table or json")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected table or json\n", *output)
+		os.Exit(2)
+	}
+
+	opts := []client.Option{client.WithTimeZone(*zone)}
+	if *username != "" {
+		opts = append(opts, client.WithBasicAuth(*username, *password))
+	}
+	c := cli{client: client.New(*baseURL, opts...), output: *output, stdout: os.Stdout}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := c.run(ctx, flags.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			flags.Usage()
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	if len(args) < 2 || args[0] != "bookings" {
+		return errUsage
+	}
+
+	switch args[1] {
+	case "list":
+		return c.listBookings(ctx, args[2:])
+	case "get":
+		return c.getBooking(ctx, args[2:])
+	case "create":
+		return c.createBooking(ctx, args[2:])
+	case "cancel":
+		return c.cancelBooking(ctx, args[2:])
+	case "extend":
+		return c.extendBooking(ctx, args[2:])
+	case "end":
+		return c.endBooking(ctx, args[2:])
+	}
+	return errUsage
+}
+
+// parseId reads the only argument of a command as an id
+func parseId(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errUsage
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%q is not an id", args[0])
+	}
+	return id, nil
+}
+
+func (c *cli) listBookings(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bookings list", flag.ContinueOnError)
+	all := flags.Bool("all", false, "also list cancelled bookings")
+	userId := flags.Int("user", 0, "only bookings of user")
+	resourceId := flags.Int("resource", 0, "only bookings of resource")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	bookings, err := c.client.ListBookings(ctx, *all)
+	if err != nil {
+		return err
+	}
+
+	var selected []client.Booking
+	for _, b := range bookings {
+		if (*userId == 0 || b.UserId == *userId) && (*resourceId == 0 || b.ResourceId == *resourceId) {
+			selected = append(selected, b)
+		}
+	}
+	return c.printBookings(selected)
+}
+
+func (c *cli) getBooking(ctx context.Context, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	booking, err := c.client.GetBooking(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printBookings([]client.Booking{booking})
+}
+
+func (c *cli) createBooking(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bookings create", flag.ContinueOnError)
+	userId := flags.Int("user", 0, "user id")
+	resourceId := flags.Int("resource", 0, "resource id")
+	start := flags.String("start", "", "start time, RFC 3339")
+	end := flags.String("end", "", "end time, RFC 3339")
+	text := flags.String("text", "", "description")
+	party := flags.Int("party", 0, "number of people, 1 by default")
+	promo := flags.String("promo", "", "promo code to redeem")
+	waitlist := flags.Bool("waitlist", false, "join the waitlist if the slot is taken")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	startTime, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		return fmt.Errorf("-start: %w", err)
+	}
+	endTime, err := time.Parse(time.RFC3339, *end)
+	if err != nil {
+		return fmt.Errorf("-end: %w", err)
+	}
+
+	booking := client.Booking{UserId: *userId, ResourceId: *resourceId, StartTime: startTime, EndTime: endTime, PartySize: *party, Text: *text, PromoCode: *promo}
+
+	entry, err := c.client.CreateBooking(ctx, booking, *waitlist)
+	if client.IsConflict(err) {
+		return fmt.Errorf("%w\nthe slot is taken, retry with -waitlist to join the waitlist", err)
+	} else if err != nil {
+		return err
+	}
+
+	if entry != nil {
+		if c.output == "json" {
+			return c.printJSON(entry)
+		}
+		fmt.Fprintf(c.stdout, "The slot is taken, added to waitlist as entry %d\n", entry.Id)
+		return nil
+	}
+
+	if c.output == "json" {
+		return c.printJSON(map[string]string{"status": "created"})
+	}
+	fmt.Fprintln(c.stdout, "Booking created")
+	return nil
+}
+
+func (c *cli) cancelBooking(ctx context.Context, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	cancellation, err := c.client.CancelBooking(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "json" {
+		return c.printJSON(map[string]any{"id": id, "status": client.BookingCancelled, "fee": cancellation.Fee, "refund": cancellation.Refund})
+	}
+	fmt.Fprintf(c.stdout, "Booking %d cancelled\n", id)
+	if cancellation.Fee > 0 || cancellation.Refund > 0 {
+		fmt.Fprintf(c.stdout, "Fee %d, refunded %d: %s\n", cancellation.Fee, cancellation.Refund, cancellation.Reason)
+	}
+	return nil
+}
+
+func (c *cli) extendBooking(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bookings extend", flag.ContinueOnError)
+	minutes := flags.Int("minutes", 60, "minutes to add")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	id, err := parseId(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	booking, err := c.client.ExtendBooking(ctx, id, *minutes)
+	if client.IsConflict(err) {
+		return fmt.Errorf("%w\nthe following time is taken", err)
+	} else if err != nil {
+		return err
+	}
+	return c.printBookings([]client.Booking{booking})
+}
+
+func (c *cli) endBooking(ctx context.Context, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	booking, err := c.client.EndBookingNow(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printBookings([]client.Booking{booking})
+}
+
+func (c *cli) printJSON(v any) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (c *cli) printBookings(bookings []client.Booking) error {
+	if c.output == "json" {
+		if bookings == nil {
+			bookings = []client.Booking{}
+		}
+		return c.printJSON(bookings)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tRESOURCE\tSTART\tEND\tSTATUS\tPRICE\tTEXT")
+	for _, b := range bookings {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%s\t%d\t%s\n", b.Id, b.UserId, b.ResourceId,
+			b.StartTime.Format(timeLayout), b.EndTime.Format(timeLayout), b.Status, b.Price, b.Text)
+	}
+	return w.Flush()
+}
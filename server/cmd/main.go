@@ -3,26 +3,75 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"github.com/alexey-dobry/booking-service/server/internal/app"
+	"github.com/alexey-dobry/booking-service/server/internal/cli"
+	"github.com/alexey-dobry/booking-service/server/internal/config"
 	"github.com/alexey-dobry/booking-service/server/internal/database"
 	"github.com/alexey-dobry/booking-service/server/internal/logger"
 )
 
 // @title RESTful API test project for MireaCyberZone
 // @description This project works with PostgresSQL. It has functionality to create users and bookings. One user can have multiple bookings.
+// @description Times are RFC 3339 with offset. Add ?tz=<IANA zone> to any request to render response times in that zone.
 
 func main() {
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration; additional info: %s", err)
+	}
+
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	if command != "serve" && command != "migrate" && !cli.IsCommand(command) {
+		cli.Usage()
+		os.Exit(2)
+	}
+
 	db, err := database.Init()
 	if err != nil {
 		log.Fatal("Failed to create database connection")
 	}
-	defer db.Close(context.Background())
+	defer db.Close()
+
+	if command == "migrate" {
+		if err := cli.Migrate(db, cfg.Location, args[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if cfg.AutoMigrate {
+		if err := database.MigrateUp(context.Background(), db, cfg.Location); err != nil {
+			log.Fatalf("Migrations error: %s", err)
+		}
+	}
+	// the server would fail on missing tables and columns, so it does not start at all
+	if err := database.CheckSchema(context.Background(), db); err != nil {
+		log.Fatalf("Database is not ready: %s", err)
+	}
 
 	logger := logger.NewLogger()
 
-	a := app.New(db, logger)
+	// command line mode keeps stdout for command output
+	if command != "serve" {
+		logger.SetConsoleWriter(os.Stderr)
+	}
+
+	a := app.New(db, logger, cfg)
+
+	if command != "serve" {
+		if err := a.RunCommand(args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	a.Run()
 }
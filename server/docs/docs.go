@@ -15,45 +15,54 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/booking": {
-            "post": {
-                "description": "Creates function which adds new user data to database",
-                "consumes": [
-                    "application/json"
-                ],
-                "summary": "Adds new booking entry",
+        "/approvals": {
+            "get": {
+                "description": "Creates function which retrieves approvals of bookings of resources which require approval, by\ndefault the pending ones which wait for review, ordered by deadline. Requires basic auth of staff.",
+                "summary": "Get approvals queue",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "integer \u003e= 1",
-                        "name": "UserId",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "format = YYYY-MM-DD HH:MM:SS",
-                        "name": "StartTime",
-                        "in": "formData",
-                        "required": true
+                        "description": "pending (default), approved, rejected or withdrawn",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "format = YYYY-MM-DD HH:MM:SS",
-                        "name": "EndTime",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Approval"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
                         "schema": {
                             "type": "integer"
                         }
                     },
                     "400": {
-                        "description": "Wrong ID",
+                        "description": "Unknown status",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "integer"
                         }
@@ -67,13 +76,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/booking/{id}": {
-            "get": {
-                "description": "Creates function which retrieves data of booking specified by id from database",
+        "/approvals/{id}/approve": {
+            "post": {
+                "description": "Creates function which confirms pending booking specified by id and charges it to the balance of\nits user. Requires basic auth of staff.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Get booking data",
+                "summary": "Approve pending booking",
                 "parameters": [
                     {
                         "type": "integer",
@@ -81,65 +90,69 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "optional reason",
+                        "name": "review",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "$ref": "#/definitions/models.Booking"
+                            "$ref": "#/definitions/models.Approval"
                         }
                     },
-                    "500": {
-                        "description": "Error scanning data from db response",
+                    "400": {
+                        "description": "Wrong ID or the booking does not require approval",
                         "schema": {
                             "type": "integer"
                         }
-                    }
-                }
-            },
-            "put": {
-                "description": "Creates function which updates data of booking specified by id in database",
-                "consumes": [
-                    "application/json"
-                ],
-                "summary": "Updates booking data",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "ok",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "integer"
                         }
                     },
-                    "400": {
-                        "description": "Wrong Id",
+                    "402": {
+                        "description": "Booking costs more than the balance of the user",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking is not pending approval",
                         "schema": {
                             "type": "integer"
                         }
                     },
                     "500": {
-                        "description": "Error scanning data from db response",
+                        "description": "Error updating data in database",
                         "schema": {
                             "type": "integer"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Creates function which deletes data of booking specified by id from database",
+            }
+        },
+        "/approvals/{id}/reject": {
+            "post": {
+                "description": "Creates function which cancels pending booking specified by id free of charge and offers its slot\nto the waitlist. The reason is required. Requires basic auth of staff.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Delete specified booking data",
+                "summary": "Reject pending booking",
                 "parameters": [
                     {
                         "type": "integer",
@@ -147,43 +160,50 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "reason",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.Approval"
                         }
                     },
                     "400": {
-                        "description": "Wrong Id",
+                        "description": "Wrong ID, no reason, or the booking does not require approval",
                         "schema": {
                             "type": "integer"
                         }
-                    }
-                }
-            }
-        },
-        "/bookings": {
-            "get": {
-                "description": "Creates function which retrieves data of all bookings from database",
-                "consumes": [
-                    "application/json"
-                ],
-                "summary": "Get booking data",
-                "responses": {
-                    "200": {
-                        "description": "ok",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Booking"
-                            }
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking is not pending approval",
+                        "schema": {
+                            "type": "integer"
                         }
                     },
                     "500": {
-                        "description": "Error scanning data from db response",
+                        "description": "Error updating data in database",
                         "schema": {
                             "type": "integer"
                         }
@@ -191,41 +211,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/user": {
+        "/booking": {
             "post": {
-                "description": "Creates functon which adds new user data to database",
+                "description": "Creates function which adds new user data to database. Bookings of resources which require approval\nare created pending: they block the slot and are charged once staff approve them.\nRequires basic auth of the user of the booking, staff or an admin.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Add new user to database",
+                "summary": "Adds new booking entry",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "6 \u003c= length \u003c= 20",
-                        "name": "Username",
+                        "type": "integer",
+                        "description": "integer \u003e= 1",
+                        "name": "UserId",
                         "in": "formData",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "integer \u003e= 1, the default resource (DEFAULT_RESOURCE_ID) if omitted",
+                        "name": "ResourceId",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
-                        "description": "length = 14",
-                        "name": "password",
+                        "description": "RFC 3339, e.g. 2025-03-01T14:00:00+03:00",
+                        "name": "StartTime",
                         "in": "formData",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "format = YYYY-MM-DD HH:MM:SS",
-                        "name": "CreatedAt",
+                        "description": "RFC 3339, e.g. 2025-03-01T16:00:00+03:00",
+                        "name": "EndTime",
                         "in": "formData",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "format = YYYY-MM-DD HH:MM:SS",
-                        "name": "UpdatedAt",
-                        "in": "formData",
-                        "required": true
+                        "description": "promo code to redeem, e.g. SPRING25",
+                        "name": "PromoCode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "join the waitlist instead of failing when the slot is taken",
+                        "name": "waitlist",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -235,8 +266,41 @@ const docTemplate = `{
                             "type": "integer"
                         }
                     },
+                    "202": {
+                        "description": "slot is taken, request was added to waitlist",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
                     "400": {
-                        "description": "Wrong ID",
+                        "description": "Wrong ID, time breaks opening hours, holiday or maintenance rule, promo code can not be redeemed, or booking breaks policy rules (list of violations)",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/policy.Violation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "402": {
+                        "description": "Booking costs more than the balance of the user",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Time slot is already taken",
                         "schema": {
                             "type": "integer"
                         }
@@ -250,17 +314,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/{id}": {
+        "/booking/{id}": {
             "get": {
-                "description": "Creates function which retrieves data of user specified by id from database",
-                "consumes": [
-                    "application/json"
-                ],
-                "summary": "Get user data",
+                "description": "Creates function which retrieves data of booking specified by id from database",
+                "summary": "Get booking data",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID ",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -268,15 +329,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
-                    },
-                    "400": {
-                        "description": "Wrong ID",
+                        "description": "ok",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.Booking"
                         }
                     },
                     "500": {
@@ -288,15 +343,15 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Creates function which updates data of user specified by id in database",
+                "description": "Creates function which updates data of booking specified by id in database.\nRequires basic auth of the user of the booking, staff or an admin.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Update user data",
+                "summary": "Updates booking data",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -310,7 +365,34 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Wrong ID",
+                        "description": "Wrong Id, time breaks opening hours, holiday or maintenance rule, or booking breaks policy rules (list of violations)",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/policy.Violation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "402": {
+                        "description": "Booking costs more than the balance of the user",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Time slot is already taken",
                         "schema": {
                             "type": "integer"
                         }
@@ -324,15 +406,12 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Creates function which deletes data of user specified by id from database",
-                "consumes": [
-                    "application/json"
-                ],
-                "summary": "Delete specified user data",
+                "description": "Creates function which cancels booking specified by id. The booking is kept with status cancelled,\nso calendar feeds can report the cancellation, and no longer blocks its time slot. A booking cancelled\nlate is charged the fee of the cancellation rules; the response reports the fee, the refund to the\nprepaid balance and the reason. Cancelling a missing or cancelled booking responds with an empty body.\nRequires basic auth of the user of the booking, staff or an admin.",
+                "summary": "Cancel specified booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -342,7 +421,7 @@ const docTemplate = `{
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.Cancellation"
                         }
                     },
                     "400": {
@@ -350,45 +429,6374 @@ const docTemplate = `{
                         "schema": {
                             "type": "integer"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Booking": {
-            "description": "Booking is a struct which contains Id, UserId, StartTime and EndTime",
-            "type": "object",
-            "required": [
-                "end_time",
-                "id",
-                "start_time",
-                "user_id"
-            ],
-            "properties": {
-                "end_time": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "start_time": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
-                }
-            }
         },
-        "models.User": {
-            "description": "User is a struct which contains Id, Username, Password, CreatedAt and UpdatedAt",
-            "type": "object",
-            "required": [
-                "created_at",
-                "id",
-                "password",
-                "updated_at",
-                "username"
+        "/booking/{id}.ics": {
+            "get": {
+                "description": "Creates function which renders booking specified by id as an RFC 5545 calendar with a single event",
+                "summary": "Get booking as iCalendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VCALENDAR with one VEVENT",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/booking/{id}/end-now": {
+            "post": {
+                "description": "Creates function which ends ongoing booking specified by id at the current time. The booking is\nrepriced, the difference is refunded to the balance of the user, the rest of the slot is offered to\nthe waitlist and the change is recorded in the history of the booking.\nRequires basic auth of the user of the booking, staff or an admin.",
+                "summary": "End booking now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID, booking is cancelled, has not started or has ended",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/booking/{id}/extend": {
+            "post": {
+                "description": "Creates function which adds minutes to the end of booking specified by id, e.g. one more hour while\nplaying. The following time on the same resource must be free and open and the longer booking must\nmeet the policy rules, except lead time. The booking is repriced, the difference is charged to the\nbalance of the user and the change is recorded in the history of the booking.\nRequires basic auth of the user of the booking, staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Extend booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "minutes to add",
+                        "name": "extension",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Extension"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID, booking is cancelled or has ended, time breaks opening hours, holiday or maintenance rule, or booking breaks policy rules (list of violations)",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/policy.Violation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "402": {
+                        "description": "Extension costs more than the balance of the user",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Following time is already taken",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/booking/{id}/history": {
+            "get": {
+                "description": "Creates function which retrieves extensions, early ends, relocations and swaps of booking specified by id, oldest first",
+                "summary": "Get booking history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingChange"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings": {
+            "get": {
+                "description": "Creates function which retrieves data of all bookings from database",
+                "summary": "Get booking data",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "also return cancelled bookings",
+                        "name": "include_cancelled",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/swap": {
+            "post": {
+                "description": "Creates function which exchanges the resources of two bookings in one transaction, e.g. the seats\nof two customers. The resources must have the same type and zone and each booking must fit into the\nresource of the other one. Prices are kept and the swap is recorded in the history of both bookings.\nRequires basic auth of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Swap resources of two bookings",
+                "parameters": [
+                    {
+                        "description": "booking_ids",
+                        "name": "swap",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Swap"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID, booking is not active or resources are not compatible",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "A booking does not fit into the other resource",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/{token}.ics": {
+            "get": {
+                "description": "Creates function which renders bookings of the user owning the secret token as an RFC 5545 calendar\nto subscribe to. Cancelled bookings stay in the feed with STATUS:CANCELLED, bookings are kept\nfor 90 days after they end.",
+                "summary": "Get calendar feed of user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VCALENDAR",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown token",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/cancellation-rules": {
+            "get": {
+                "description": "Creates function which retrieves all cancellation rules, narrowest windows first, optionally only\nthose which apply to a resource type",
+                "summary": "Get cancellation rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CancellationRule"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a fee of bookings cancelled less than hours_before hours before their\nstart, optionally only for a resource type. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add cancellation rule",
+                "parameters": [
+                    {
+                        "description": "name, hours_before, fee_percent and optional resource_type",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CancellationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancellationRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/cancellation-rules/{id}": {
+            "get": {
+                "description": "Creates function which retrieves cancellation rule specified by id from database",
+                "summary": "Get cancellation rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cancellation rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancellationRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces cancellation rule specified by id. Fees of bookings cancelled before\nare kept. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace cancellation rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cancellation rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, hours_before, fee_percent and optional resource_type",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CancellationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes cancellation rule specified by id. Requires basic auth of an admin.",
+                "summary": "Delete cancellation rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cancellation rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/discounts": {
+            "get": {
+                "description": "Creates function which retrieves all discounts, optionally only those which apply to a user role",
+                "summary": "Get discounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User role",
+                        "name": "role",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Discount"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a percentage discount of booking prices, optionally only for a user\nrole and/or a resource type. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add discount",
+                "parameters": [
+                    {
+                        "description": "name, percent and optional role and resource_type scope",
+                        "name": "discount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Discount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Discount"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/discounts/{id}": {
+            "put": {
+                "description": "Creates function which replaces discount specified by id. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace discount",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Discount ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, percent and optional role and resource_type scope",
+                        "name": "discount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Discount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes discount specified by id. Requires basic auth of an admin.",
+                "summary": "Delete discount",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Discount ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/availability": {
+            "get": {
+                "description": "Creates function which streams resource.status events as Server-Sent Events whenever a booking or\nmaintenance window of a resource changes: the time slot of the change and whether the resource is\navailable, booked or under maintenance then (models.ResourceStatus). Bookings themselves are not\nstreamed. Every event has an id; a reconnecting client sends the last one in the Last-Event-ID header\nand first receives the events it missed (kept for 7 days) with the current status of their slots.",
+                "summary": "Stream availability changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events of resources in zone",
+                        "name": "zone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only events of resource",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResourceStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error retrieving missed events",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/bookings": {
+            "get": {
+                "description": "Creates function which streams all bookings, including cancelled ones, as CSV or NDJSON.\nRequires basic auth of an admin.",
+                "summary": "Export bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv | ndjson (default)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/users": {
+            "get": {
+                "description": "Creates function which streams all users, with password hashes, as CSV or NDJSON.\nRequires basic auth of an admin.",
+                "summary": "Export users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv | ndjson (default)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Creates function which retrieves groups organised by the user or which the user is invited to,\nlatest first. user_id defaults to the authenticated user. Requires basic auth of the user or of staff.",
+                "summary": "Get booking groups of user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingGroup"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which books several resources for the same interval in a single transaction,\ne.g. adjacent PCs for a team, and invites participants. If any resource is taken or breaks a rule,\nnothing is booked and the conflicts are returned. organiser_id defaults to the authenticated user.\nRequires basic auth of the organiser or of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add booking group",
+                "parameters": [
+                    {
+                        "description": "resource_ids, start_time, end_time, text and optional participant_ids",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Bookings of the group conflict with existing bookings or rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Conflict"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Creates function which retrieves booking group specified by id with its bookings and participants.\nRequires basic auth of the organiser, of a participant or of staff.",
+                "summary": "Get booking group data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which cancels bookings of group specified by id which have not started yet and\ndeletes the group. Late cancellations are charged a fee like single bookings; the response lists\nfee and refund of every cancelled booking. Requires basic auth of the organiser or of staff.",
+                "summary": "Cancel booking group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Cancellation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error deleting data from database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/participants": {
+            "post": {
+                "description": "Creates function which invites a registered user to group specified by id. Inviting a user who\ndeclined invites them again. Requires basic auth of the organiser or of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Invite user to booking group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user_id of the invited user",
+                        "name": "participant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupParticipant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupParticipant"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/participants/{user_id}": {
+            "put": {
+                "description": "Creates function which accepts or declines the invitation of user specified by user_id to group\nspecified by id. Requires basic auth of the invited user or of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Respond to booking group invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "status accepted or declined",
+                        "name": "participant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupParticipant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupParticipant"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which removes user specified by user_id from group specified by id. Requires basic\nauth of the organiser, of the participant or of staff.",
+                "summary": "Remove participant from booking group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error deleting data from database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/bookings": {
+            "post": {
+                "description": "Creates function which creates bookings from a CSV (with header) or NDJSON file. Each record is\nvalidated and checked for overlaps like POST /booking, also against earlier records of the file;\nopening hours and policy rules are not applied. Requires basic auth of an admin.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "summary": "Import bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv | ndjson, by default taken from Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "all_or_nothing (default) | best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate records",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "records",
+                        "name": "records",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Some records are invalid, nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/users": {
+            "post": {
+                "description": "Creates function which creates users from a CSV (with header) or NDJSON file. Each record is\nvalidated like POST /user; the response lists errors of rejected records by line.\nRequires basic auth of an admin.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "summary": "Import users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv | ndjson, by default taken from Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "all_or_nothing (default) | best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate records",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "records",
+                        "name": "records",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Some records are invalid, nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/accounts": {
+            "get": {
+                "description": "Creates function which retrieves totals of the venue accounts (cash, revenue, adjustments) and the\nsum of all user balances. Requires basic auth of an admin.",
+                "summary": "Get venue accounts",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AccountBalance"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance": {
+            "get": {
+                "description": "Creates function which retrieves maintenance windows which have not ended yet, optionally only those of a resource",
+                "summary": "Get maintenance windows",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceWindow"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which blocks a resource for maintenance. Existing bookings are not touched.\nRequires basic auth of staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add maintenance window",
+                "parameters": [
+                    {
+                        "description": "resource_id, start_time, end_time, reason",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance/{id}": {
+            "put": {
+                "description": "Creates function which replaces maintenance window specified by id. Requires basic auth of staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace maintenance window",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maintenance window ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "resource_id, start_time, end_time, reason",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes maintenance window specified by id. Requires basic auth of staff or an admin.",
+                "summary": "Delete maintenance window",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maintenance window ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/membership-plans": {
+            "get": {
+                "description": "Creates function which retrieves all membership plans",
+                "summary": "Get membership plans",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MembershipPlan"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a plan including hours of bookings every month, optionally only of some\nresource types. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add membership plan",
+                "parameters": [
+                    {
+                        "description": "name, included_hours and optional resource_types",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MembershipPlan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.MembershipPlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/membership-plans/{id}": {
+            "put": {
+                "description": "Creates function which replaces membership plan specified by id. Hours already used are kept, the new\nincluded hours apply to bookings made afterwards. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace membership plan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Membership plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, included_hours and optional resource_types",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MembershipPlan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes membership plan specified by id. Plans with memberships can only be\ndisabled. Requires basic auth of an admin.",
+                "summary": "Delete membership plan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Membership plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/memberships": {
+            "get": {
+                "description": "Creates function which retrieves all memberships, optionally only those of a user, with the hours\nincluded and used in a month, the current one by default. Requires basic auth of staff or an admin.",
+                "summary": "Get memberships",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month, YYYY-MM",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Membership"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which subscribes a user to a membership plan from start_date, until end_date if it is\ngiven. Memberships of a user can not overlap. Requires basic auth of staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add membership",
+                "parameters": [
+                    {
+                        "description": "user_id, plan_id, start_date and optional end_date",
+                        "name": "membership",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Membership overlaps another one",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/memberships/{id}": {
+            "get": {
+                "description": "Creates function which retrieves membership specified by id with the hours included and used in a\nmonth, the current one by default. Requires basic auth of staff or an admin.",
+                "summary": "Get membership",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Membership ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month, YYYY-MM",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces membership specified by id, e.g. to end it by setting end_date.\nRequires basic auth of staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace membership",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Membership ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user_id, plan_id, start_date and optional end_date",
+                        "name": "membership",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Membership overlaps another one",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes membership specified by id. Prices of bookings which used its hours\nare kept. Requires basic auth of staff or an admin.",
+                "summary": "Delete membership",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Membership ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/opening-hours": {
+            "get": {
+                "description": "Creates function which retrieves weekly opening hours, optionally only those of a resource",
+                "summary": "Get opening hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OpeningHours"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds weekly opening hours of the venue or of a resource. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add opening hours",
+                "parameters": [
+                    {
+                        "description": "weekday, open_time, close_time and optional resource_id",
+                        "name": "hours",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OpeningHours"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.OpeningHours"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/opening-hours/{id}": {
+            "put": {
+                "description": "Creates function which replaces opening hours specified by id. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace opening hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Opening hours ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "weekday, open_time, close_time and optional resource_id",
+                        "name": "hours",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OpeningHours"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes opening hours specified by id. Requires basic auth of an admin.",
+                "summary": "Delete opening hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Opening hours ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/policy-rules": {
+            "get": {
+                "description": "Creates function which retrieves all policy rules, optionally only those which apply to a\nresource type or a user role",
+                "summary": "Get booking policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User role",
+                        "name": "role",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PolicyRule"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a business limit checked on booking creation and change, e.g.\nminimal duration or maximal hours per user per week. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add booking policy rule",
+                "parameters": [
+                    {
+                        "description": "name, kind, value and optional resource_type and role scope",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PolicyRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.PolicyRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/policy-rules/{id}": {
+            "get": {
+                "description": "Creates function which retrieves policy rule specified by id from database",
+                "summary": "Get booking policy rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Policy rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.PolicyRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces policy rule specified by id. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace booking policy rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Policy rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, kind, value and optional resource_type and role scope",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PolicyRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes policy rule specified by id. Requires basic auth of an admin.",
+                "summary": "Delete booking policy rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Policy rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/promo-codes": {
+            "get": {
+                "description": "Creates function which retrieves all promo codes with the number of their redemptions. Requires basic\nauth of staff or an admin.",
+                "summary": "Get promo codes",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PromoCode"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a percentage or fixed promo code, optionally limited to a validity window,\na number of redemptions in total and per user and to resource types. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add promo code",
+                "parameters": [
+                    {
+                        "description": "code, kind, value and optional resource_types, valid_from, valid_until, max_redemptions and max_per_user",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/promo-codes/{id}": {
+            "get": {
+                "description": "Creates function which retrieves promo code specified by id with the number of its redemptions.\nRequires basic auth of staff or an admin.",
+                "summary": "Get promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces promo code specified by id. Existing redemptions are kept and count\ntowards the new limits. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "code, kind, value and optional resource_types, valid_from, valid_until, max_redemptions and max_per_user",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes promo code specified by id. Codes which were redeemed are kept for\nredemption reports and can only be disabled. Requires basic auth of an admin.",
+                "summary": "Delete promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/promo-codes/{id}/redemptions": {
+            "get": {
+                "description": "Creates function which retrieves redemptions of promo code specified by id, latest first, optionally\nonly those made within [from, to). Redemptions of cancelled bookings are reported with their booking\nstatus. Requires basic auth of staff or an admin.",
+                "summary": "Get promo code redemptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PromoRedemption"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/quote": {
+            "post": {
+                "description": "Creates function which prices a hypothetical booking by the current tariffs, discounts, the\nmembership of the user and a promo code without creating it. The promo code is checked but not\nredeemed. Opening hours, policy rules and availability are not checked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Quote booking price",
+                "parameters": [
+                    {
+                        "description": "resource_id, start_time, end_time and optional user_id and promo_code",
+                        "name": "booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/pricing.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error calculating price",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource": {
+            "post": {
+                "description": "Creates function which adds new bookable resource (PC, console, room) to database. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add new resource to database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "2 \u003c= length \u003c= 50",
+                        "name": "Name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "length \u003c= 30",
+                        "name": "Type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "length \u003c= 30",
+                        "name": "Zone",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "people it holds at the same time, 1 by default",
+                        "name": "Capacity",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minutes kept free before every booking for setup",
+                        "name": "BufferBefore",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minutes kept free after every booking for cleanup",
+                        "name": "BufferAfter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "bookings are pending until staff approve them",
+                        "name": "RequiresApproval",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource/{id}": {
+            "get": {
+                "description": "Creates function which retrieves data of resource specified by id from database",
+                "summary": "Get resource data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Resource"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which updates data of resource specified by id in database. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Update resource data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes data of resource specified by id and its bookings from database. Requires basic auth of an admin.",
+                "summary": "Delete specified resource data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource/{id}/availability": {
+            "get": {
+                "description": "Creates function which calculates free intervals of resource specified by id, taking opening hours,\nschedule exceptions, maintenance windows and existing bookings together with the buffers of the\nresource kept free around them into account. Every interval reports\nhow many places of the capacity of the resource are left during all of it; intervals with fewer\nplaces than party_size are left out.",
+                "summary": "Get resource availability",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339, defaults to now",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339, defaults to from + 24h, at most 31 days after from",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "people to fit, defaults to 1",
+                        "name": "party_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Slot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource/{id}/relocate": {
+            "post": {
+                "description": "Creates function which moves upcoming and ongoing bookings of resource specified by id, e.g. a PC\nwhich broke, to a compatible resource of the same type and zone: target_id, or the first one free at\nthe time of each booking. Prices are kept and every move is recorded in the history of the booking.\nIn all_or_nothing mode (default) nothing is moved if any booking does not fit anywhere and the\nresponse is 409 with the conflicts; in skip_conflicts mode such bookings stay. With dry_run the\nresult is previewed without moving anything. Requires basic auth of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Relocate bookings of resource",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "target_id, from, to and mode",
+                        "name": "relocation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Relocation"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only preview the moves",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.RelocationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID, incorrect input data or no compatible resource",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Some bookings can not be relocated, nothing was moved",
+                        "schema": {
+                            "$ref": "#/definitions/models.RelocationResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources": {
+            "get": {
+                "description": "Creates function which retrieves data of all resources from database",
+                "summary": "Get resource data",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Resource"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule-exceptions": {
+            "get": {
+                "description": "Creates function which retrieves holidays and special opening hours, optionally only those of a resource",
+                "summary": "Get schedule exceptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduleException"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a holiday (closed) or special opening hours for a single date of the\nvenue or of a resource. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add schedule exception",
+                "parameters": [
+                    {
+                        "description": "date, closed or open_time and close_time, reason",
+                        "name": "exception",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduleException"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduleException"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule-exceptions/{id}": {
+            "put": {
+                "description": "Creates function which replaces schedule exception specified by id. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace schedule exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule exception ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "date, closed or open_time and close_time, reason",
+                        "name": "exception",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduleException"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes schedule exception specified by id. Requires basic auth of an admin.",
+                "summary": "Delete schedule exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule exception ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "post": {
+                "description": "Creates function which expands an iCalendar RRULE (with UNTIL or COUNT and optional EXDATE list)\ninto individual bookings. In all_or_nothing mode (default) nothing is booked if any occurrence\nconflicts with an existing booking; in skip_conflicts mode conflicting occurrences are skipped.\nRequires basic auth of the user of the series, staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Adds new recurring booking series",
+                "parameters": [
+                    {
+                        "description": "start_time and end_time describe the first occurrence, rrule e.g. FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Occurrences conflict with existing bookings",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "description": "Creates function which retrieves recurring series specified by id together with its bookings",
+                "summary": "Get recurring series data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which changes definition of series specified by id and rebuilds all of its\noccurrences which have not started yet. Past occurrences are left untouched. Occurrences changed on\ntheir own keep their changes as long as the new definition still gives them.\nRequires basic auth of the user of the series, staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Updates whole recurring series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Occurrences conflict with existing bookings",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes series specified by id and all of its occurrences which have not\nstarted yet. Past occurrences are kept as ordinary bookings. Occurrences cancelled late are charged\nthe fee of the cancellation rules; the response reports the cancellation of every occurrence.\nRequires basic auth of the user of the series, staff or an admin.",
+                "summary": "Cancel whole recurring series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Cancellation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}/occurrence/{booking_id}": {
+            "put": {
+                "description": "Creates function which changes a single occurrence (scope=this, default) or the occurrence and all\nfollowing ones (scope=following). The latter splits the series: the original series ends before\nthe occurrence and a new series with the changed definition is created from it. The split is made\nat the time the rule gives the occurrence, even if the occurrence was moved on its own, or at the\nfirst occurrence which has not started yet if it is in the past; past occurrences are kept.\nRequires basic auth of the user of the series, staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Updates occurrence of recurring series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Booking ID of the occurrence",
+                        "name": "booking_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "this | following",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Occurrences conflict with existing bookings",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which cancels a single occurrence (scope=this, default), adding it to EXDATE of\nthe series, or the occurrence and all following ones (scope=following), ending the series before it.\nFollowing occurrences which have already started are kept, the series ends before the first one which has not.\nOccurrences cancelled late are charged the fee of the cancellation rules; the response reports the\ncancellation of every occurrence.\nRequires basic auth of the user of the series, staff or an admin.",
+                "summary": "Cancel occurrence of recurring series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Booking ID of the occurrence",
+                        "name": "booking_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "this | following",
+                        "name": "scope",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Cancellation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/tariffs": {
+            "get": {
+                "description": "Creates function which retrieves all tariffs, optionally only those which apply to a resource type",
+                "summary": "Get tariffs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tariff"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which adds a price per hour for a band of the day, optionally only on\nweekdays or weekends and for a resource type. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add tariff",
+                "parameters": [
+                    {
+                        "description": "name, days, start_time, end_time, price_per_hour and optional resource_type and priority",
+                        "name": "tariff",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Tariff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tariff"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/tariffs/{id}": {
+            "get": {
+                "description": "Creates function which retrieves tariff specified by id from database",
+                "summary": "Get tariff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tariff"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces tariff specified by id. Prices of existing bookings are kept,\nbookings created or rescheduled afterwards are priced by the new tariff. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace tariff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, days, start_time, end_time, price_per_hour and optional resource_type and priority",
+                        "name": "tariff",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Tariff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes tariff specified by id. Requires basic auth of an admin.",
+                "summary": "Delete tariff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "post": {
+                "description": "Creates functon which adds new user data to database",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add new user to database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "6 \u003c= length \u003c= 20",
+                        "name": "Username",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "length = 14",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339, set by server",
+                        "name": "CreatedAt",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339, set by server",
+                        "name": "UpdatedAt",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "description": "Creates function which retrieves data of user specified by id from database",
+                "summary": "Get user data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID ",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which updates data of user specified by id in database.\nRequires basic auth of the user or an admin; only admins can change the role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Update user data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes data of user specified by id from database.\nRequires basic auth of the user or an admin.",
+                "summary": "Delete specified user data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "User has ledger entries",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/adjustment": {
+            "post": {
+                "description": "Creates function which corrects the balance of user specified by id by a positive or negative\namount, e.g. a compensation or a mistaken top-up. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Adjust user balance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "non-zero amount and description",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.LedgerEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/balance": {
+            "get": {
+                "description": "Creates function which retrieves the prepaid balance of user specified by id. Requires basic auth\nof the user or of staff.",
+                "summary": "Get user balance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Balance"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/calendar-token": {
+            "get": {
+                "description": "Creates function which returns the secret calendar feed link of user specified by id, creating it\non first use. Requires basic auth of the user or of staff.",
+                "summary": "Get calendar feed link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which replaces the calendar token of user specified by id, so the previous\nfeed link stops working. Requires basic auth of the user or of staff.",
+                "summary": "Regenerate calendar feed link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/ledger": {
+            "get": {
+                "description": "Creates function which retrieves all ledger entries of user specified by id, latest first: top-ups,\nbooking charges, refunds and adjustments. Requires basic auth of the user or of staff.",
+                "summary": "Get user ledger",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LedgerEntry"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/priority": {
+            "put": {
+                "description": "Creates function which lets staff designate user specified by id as priority member,\nwho is served before others from waitlists. Requires basic auth of staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Update user waitlist priority",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json object with priority flag",
+                        "name": "priority",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/role": {
+            "put": {
+                "description": "Creates function which sets role (member, staff or admin) of user specified by id.\nRequires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Update user role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json object with role field",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/top-up": {
+            "post": {
+                "description": "Creates function which credits the balance of user specified by id with money paid in at the front\ndesk. Requires basic auth of staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Top up user balance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "positive amount and optional description",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.LedgerEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Creates function which retrieves data of all users from database",
+                "summary": "Get user data",
+                "responses": {
+                    "200": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist": {
+            "get": {
+                "description": "Creates function which retrieves active (waiting and offered) waitlist entries in queue order,\noptionally filtered by resource. Requires basic auth; members only get their own entries.",
+                "summary": "Get waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which queues user for a resource and time window. As soon as the window is free\n(now or after a booking is cancelled or shortened) a held booking is offered which must be\nconfirmed before hold_expires_at. Members with priority are served first, others in FIFO order.\nRequires basic auth of the user of the entry, staff or an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Adds user to waitlist",
+                "parameters": [
+                    {
+                        "description": "user_id, resource_id, start_time, end_time, text",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data or requested booking breaks policy rules (list of violations)",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/policy.Violation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{id}": {
+            "get": {
+                "description": "Creates function which retrieves waitlist entry specified by id. Clients poll it to find out\nwhether a booking was offered. Requires basic auth of the user of the entry, staff or an admin.",
+                "summary": "Get waitlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Waitlist entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which removes waitlist entry specified by id. A pending offer is declined\nand passed on to the next user in the queue. Requires basic auth of the user of the entry,\nstaff or an admin.",
+                "summary": "Leave waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Waitlist entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{id}/confirm": {
+            "post": {
+                "description": "Creates function which turns the held booking offered to waitlist entry specified by id\ninto a confirmed booking, or a pending one if the resource requires approval.\nRequires basic auth of the user of the booking, staff or an admin.",
+                "summary": "Confirm offered booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Waitlist entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "402": {
+                        "description": "Booking costs more than the balance of the user",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing was offered or the offer expired",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-deliveries/dead": {
+            "get": {
+                "description": "Creates function which retrieves deliveries of all webhooks which failed every attempt and are no\nlonger retried, oldest first. Requires basic auth of an admin.",
+                "summary": "Get dead webhook deliveries",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-deliveries/{id}/retry": {
+            "post": {
+                "description": "Creates function which queues delivery specified by id again with a fresh set of attempts,\ne.g. after the receiver of a dead delivery was fixed. Requires basic auth of an admin.",
+                "summary": "Retry webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Creates function which retrieves all webhooks, without their secrets. Requires basic auth of an admin.",
+                "summary": "Get webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates function which subscribes url to events (booking.created, booking.updated, booking.cancelled,\nuser.created, user.updated, user.deleted or * for all). Each event is posted as json with an\nX-Webhook-Signature header: sha256= and hex HMAC-SHA256 of \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\" keyed with\nthe secret. A secret is generated when none is given; it is only returned here.\nRequires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Add webhook subscription",
+                "parameters": [
+                    {
+                        "description": "url, events and optional secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect input data",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error adding data to database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Creates function which retrieves webhook specified by id, without its secret.\nRequires basic auth of an admin.",
+                "summary": "Get webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates function which replaces url, events and disabled flag of webhook specified by id.\nThe secret is only changed when given. Deliveries of a disabled webhook wait until it is\nenabled again. Requires basic auth of an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Replace webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "url, events, disabled and optional new secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating data in database",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Creates function which deletes webhook specified by id together with its delivery log.\nRequires basic auth of an admin.",
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong Id",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Creates function which retrieves the latest 100 deliveries of webhook specified by id, newest first,\nwith status, number of attempts, last response code and error. Requires basic auth of an admin.",
+                "summary": "Get webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending | delivered | dead",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "no content",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "500": {
+                        "description": "Error scanning data from db response",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AccountBalance": {
+            "description": "AccountBalance is the total of the postings to an account, e.g. cash received for top-ups (negative, as it was debited); account users is the sum of all user balances, which the venue owes to its users. The totals of all accounts sum to zero.",
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Approval": {
+            "description": "Approval is the review of a booking of a resource which requires approval. The booking is pending and blocks its slot until staff approve it, which confirms and charges it, or reject it, which cancels it free of charge. Requests not reviewed before the deadline are rejected automatically; the deadline is a day after the request, or the start of the booking if that is earlier.",
+            "type": "object",
+            "properties": {
+                "booking": {
+                    "$ref": "#/definitions/models.Booking"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Balance": {
+            "description": "Balance is the amount (minor currency units) available to a user for bookings",
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BalanceChange": {
+            "description": "BalanceChange is a top-up (positive amount) or a manual adjustment (any non-zero amount) of a user balance in minor currency units",
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.Booking": {
+            "description": "Booking is a struct which contains Id, UserId, ResourceId, StartTime and EndTime. SeriesId is set when the booking is an occurrence of a recurring series, with OccurrenceStart, the time the rule gives the occurrence, and Overridden when the occurrence was changed on its own: such changes are kept when the whole series is updated. GroupId is set when the booking was made as a part of a booking group. PartySize is the number of people, 1 unless set; it counts against the capacity of the resource. Sequence is incremented on every change, as SEQUENCE of the iCalendar event. Price is calculated by the tariffs when the booking is created or rescheduled and kept with its breakdown, so later changes of tariffs do not alter it. CancellationFee is the part of the price kept when the booking was cancelled late. PromoCode is only read when a booking is created; the redeemed code is reported in the price breakdown. New bookings without a resource_id book the default resource of the venue.",
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time",
+                "text"
+            ],
+            "properties": {
+                "cancellation_fee": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "hold_expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurrence_start": {
+                    "type": "string"
+                },
+                "overridden": {
+                    "type": "boolean"
+                },
+                "party_size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "price_breakdown": {
+                    "$ref": "#/definitions/pricing.Quote"
+                },
+                "promo_code": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "resource_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "series_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookingChange": {
+            "description": "BookingChange is an entry of the history of a booking: the resource, times and price before and after an extension, an early end, a relocation or a swap. changed_by is set when the change was made by an authenticated user.",
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "changed_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "new_end_time": {
+                    "type": "string"
+                },
+                "new_price": {
+                    "type": "integer"
+                },
+                "new_resource_id": {
+                    "type": "integer"
+                },
+                "new_start_time": {
+                    "type": "string"
+                },
+                "old_end_time": {
+                    "type": "string"
+                },
+                "old_price": {
+                    "type": "integer"
+                },
+                "old_resource_id": {
+                    "type": "integer"
+                },
+                "old_start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingGroup": {
+            "description": "BookingGroup books several resources for the same interval at once, e.g. adjacent PCs for a team. All bookings are made in a single transaction for the organiser and paid from their balance: if any resource is taken or breaks a rule, nothing is booked. participant_ids are registered users invited when the group is created; more can be invited later. A booking of the group can be cancelled on its own, cancelling the group cancels all of them.",
+            "type": "object",
+            "required": [
+                "end_time",
+                "organiser_id",
+                "resource_ids",
+                "start_time",
+                "text"
+            ],
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Booking"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "organiser_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "participant_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GroupParticipant"
+                    }
+                },
+                "resource_ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "models.BookingSeries": {
+            "description": "BookingSeries is a recurring booking defined by an iCalendar RRULE. StartTime and EndTime describe the first occurrence.",
+            "type": "object",
+            "required": [
+                "end_time",
+                "resource_id",
+                "rrule",
+                "start_time",
+                "text",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "exdates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "all_or_nothing",
+                        "skip_conflicts"
+                    ]
+                },
+                "resource_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "rrule": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.CalendarFeed": {
+            "description": "CalendarFeed is a secret link to the iCalendar feed of user's bookings. Anybody who knows the token can read the feed, so it can be regenerated to revoke a leaked link.",
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Cancellation": {
+            "description": "Cancellation reports the consequences of cancelling a booking in minor currency units: the fee kept by the venue and the refund of what was paid from the prepaid balance. Without prepaid balances paid and refund are 0 and the fee is to be collected at the front desk. Reason explains the fee.",
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "fee": {
+                    "type": "integer"
+                },
+                "paid": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "refund": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CancellationRule": {
+            "description": "CancellationRule charges fee_percent of the booking price when a booking is cancelled less than hours_before hours before its start_time. Bookings which have already started are charged their full price whatever the rules. Of the rules whose window a cancellation falls into the narrowest one applies, so rules of 24 hours with 50% and 2 hours with 100% make cancelling free until a day before the start, cost half of the price until two hours before and the full price afterwards. A rule applies to all resources unless it is scoped to a resource type; disabled rules are kept but not applied.",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "fee_percent": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "hours_before": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "resource_type": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Conflict": {
+            "description": "Conflict describes an occurrence which overlaps an existing booking (ConflictBookingId) or breaks a schedule or policy rule such as opening hours, maintenance or maximal duration, or is not covered by the balance of the user (Rule and Reason). ResourceId is set for bookings of a group, BookingId for bookings which could not be relocated.",
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "conflict_booking_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Discount": {
+            "description": "Discount lowers the price of bookings by a percentage, e.g. for members or staff. It applies to all bookings unless it is scoped to a user role and/or a resource type. Discounts do not stack: a booking gets the largest one which applies.",
+            "type": "object",
+            "required": [
+                "name",
+                "percent"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "percent": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "resource_type": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "staff",
+                        "admin"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Extension": {
+            "description": "Extension is the time added to the end of a booking",
+            "type": "object",
+            "required": [
+                "minutes"
+            ],
+            "properties": {
+                "minutes": {
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.GroupParticipant": {
+            "description": "GroupParticipant is a user invited to a booking group by its organiser",
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "invited_at": {
+                    "type": "string"
+                },
+                "responded_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "invited",
+                        "accepted",
+                        "declined"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.ImportResult": {
+            "description": "ImportResult reports the outcome of an import: number of records read, records which passed all checks, records written to database (none in dry run or when all_or_nothing import failed) and errors of rejected records",
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RecordError"
+                    }
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LedgerEntry": {
+            "description": "LedgerEntry changes the prepaid balance of a user by amount (minor currency units) and names the account of the venue it is booked to: positive amounts credit the user (top_up, refund, adjustment), negative ones debit it (charge, adjustment). The entry is booked double: its postings credit the user and debit the venue account by the same amount, so they sum to zero. Balance is the balance of the user after the entry. Entries are never changed or deleted, mistakes are corrected with adjustments.",
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "postings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Posting"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MaintenanceWindow": {
+            "description": "MaintenanceWindow is a period when a resource can not be booked, e.g. because it is under repair",
+            "type": "object",
+            "required": [
+                "end_time",
+                "resource_id",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "resource_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Membership": {
+            "description": "Membership subscribes a user to a plan from start_date until end_date inclusive (YYYY-MM-DD), or open-ended. Memberships of a user can not overlap. The usage fields report a single month (period, YYYY-MM): the minutes the plan includes and those used by bookings starting in it.",
+            "type": "object",
+            "required": [
+                "plan_id",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "included_minutes": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "used_minutes": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.MembershipPlan": {
+            "description": "MembershipPlan includes hours of bookings every calendar month (in the venue time zone), optionally only of some resource types. Included hours cover the priced minutes of bookings from their start, in the order bookings are made; unused hours do not carry over.",
+            "type": "object",
+            "required": [
+                "included_hours",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "included_hours": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Move": {
+            "description": "Move is a booking relocated to another resource",
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "from_resource_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "to_resource_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OpeningHours": {
+            "description": "OpeningHours is a weekly opening interval of the whole venue (no resource_id) or of a single resource, which then ignores venue hours. Weekday is 0 (Sunday) to 6 (Saturday), times are HH:MM; close_time earlier than or equal to open_time means closing after midnight. Without any opening hours the venue is open around the clock.",
+            "type": "object",
+            "required": [
+                "close_time",
+                "open_time"
+            ],
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "open_time": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "integer"
+                },
+                "weekday": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.PolicyRule": {
+            "description": "PolicyRule is a business limit checked when bookings are created or changed. Kinds and units of value: min_duration, max_duration, granularity, min_lead_time and max_lead_time in minutes, max_active_bookings and max_concurrent_bookings in bookings, max_weekly_hours in hours. A rule applies to all bookings unless it is scoped to a resource type and/or a user role; disabled rules are kept but not checked.",
+            "type": "object",
+            "required": [
+                "kind",
+                "name",
+                "value"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "min_duration",
+                        "max_duration",
+                        "granularity",
+                        "min_lead_time",
+                        "max_lead_time",
+                        "max_active_bookings",
+                        "max_concurrent_bookings",
+                        "max_weekly_hours"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "resource_type": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "staff",
+                        "admin"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.Posting": {
+            "description": "Posting is one side of a ledger entry: amount (minor currency units) booked to an account, positive amounts credit it. Postings to account users name the user whose balance they change.",
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PromoCode": {
+            "description": "PromoCode takes value percent (kind percent) or value minor currency units (kind fixed) off the price of a booking created with it, after membership hours and discounts. A code can be redeemed between valid_from and valid_until, up to max_redemptions times in total and max_per_user times by a single user; a missing bound is no limit. Without resource_types the code applies to all resources. Codes are matched case-insensitively. Redemptions is the number of bookings which hold the code; cancelling a booking releases its redemption.",
+            "type": "object",
+            "required": [
+                "code",
+                "kind",
+                "value"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ]
+                },
+                "max_per_user": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "max_redemptions": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "redemptions": {
+                    "type": "integer"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.PromoRedemption": {
+            "description": "PromoRedemption is a use of a promo code by a booking. Amount is what the code took off its price. booking_status is cancelled for released redemptions and missing once the booking was purged.",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "booking_status": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "promo_code_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QuoteRequest": {
+            "description": "QuoteRequest describes a booking to be priced without creating it. Without user_id no role discounts and memberships are applied. A promo code is checked but not redeemed.",
+            "type": "object",
+            "required": [
+                "end_time",
+                "resource_id",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "promo_code": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "resource_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.RecordError": {
+            "description": "RecordError reports why a record of an import file was rejected",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Relocation": {
+            "description": "Relocation moves bookings of a resource, e.g. a PC which broke, to compatible resources: resources of the same type in the same zone. Bookings which have not ended by from (now by default) and start before to (if set) are moved to target_id, or to the first compatible resource free at their time if target_id is not set. Prices are kept.",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "all_or_nothing",
+                        "skip_conflicts"
+                    ]
+                },
+                "target_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RelocationResult": {
+            "description": "RelocationResult reports the bookings which are moved and those which could not be, with the reason. moved is the number of bookings written to database: none in dry run or when an all_or_nothing relocation failed.",
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Conflict"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "moved": {
+                    "type": "integer"
+                },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Move"
+                    }
+                },
+                "resource_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Resource": {
+            "description": "Resource is a bookable entity (PC, console, room) which contains Id, Name, Type and Zone. Capacity is how many people it holds at the same time, e.g. of a lounge; bookings of a resource may overlap as long as their parties together fit into it. A resource holds a single party by default. BufferBefore and BufferAfter are minutes kept free before and after every booking for setup and cleanup; they block the resource without changing start_time and end_time of bookings. Bookings of a resource which requires approval, e.g. a VIP room, are pending until staff approve them.",
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "buffer_after": {
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 0
+                },
+                "buffer_before": {
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 0
+                },
+                "capacity": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "requires_approval": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "zone": {
+                    "type": "string",
+                    "maxLength": 30
+                }
+            }
+        },
+        "models.ResourceStatus": {
+            "description": "ResourceStatus is the availability of a resource during [start, end) after its bookings or maintenance changed: available while there is room for another party, booked when there is not and maintenance while it is blocked. It tells nothing about who booked the resource.",
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Review": {
+            "description": "Review is a comment of staff on their decision; it is required to reject a booking",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.ScheduleException": {
+            "description": "ScheduleException overrides opening hours on a single date (YYYY-MM-DD) for the venue or one resource: either closed (holiday) or open with special hours.",
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "open_time": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "resource_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SeriesResult": {
+            "description": "SeriesResult reports bookings created for a series and occurrences skipped due to conflicts",
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Booking"
+                    }
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Conflict"
+                    }
+                },
+                "series_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Swap": {
+            "description": "Swap exchanges the resources of two bookings, e.g. the seats of two customers. The resources must be compatible and each booking must fit into the resource of the other one.",
+            "type": "object",
+            "properties": {
+                "booking_ids": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.Tariff": {
+            "description": "Tariff is a price per hour of bookings in minor currency units (e.g. kopecks or cents) during a band of the day, e.g. night hours 22:00-06:00. Times are HH:MM in the venue time zone; end_time earlier than start_time means the band continues after midnight, equal times mean the whole day. days is all, weekdays or weekends. A tariff applies to all resources unless it is scoped to a resource type; where tariffs overlap, the one with the highest priority wins and a scoped tariff wins over a global one of the same priority. Disabled tariffs are kept but not applied.",
+            "type": "object",
+            "required": [
+                "days",
+                "end_time",
+                "name",
+                "start_time"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "days": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "weekdays",
+                        "weekends"
+                    ]
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "price_per_hour": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "resource_type": {
+                    "type": "string",
+                    "maxLength": 30
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "description": "User is a struct which contains Id, Username, Password, CreatedAt and UpdatedAt. Priority members designated by staff are served first from waitlists.",
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                },
+                "priority": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                }
+            }
+        },
+        "models.UserRecord": {
+            "description": "UserRecord is a user in import and export files. On import either password (plain text) or password_hash (bcrypt, as exported) must be set.",
+            "type": "object",
+            "required": [
+                "username"
             ],
             "properties": {
                 "created_at": {
@@ -398,8 +6806,24 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "password": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                },
+                "password_hash": {
                     "type": "string"
                 },
+                "priority": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "staff",
+                        "admin"
+                    ]
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -409,6 +6833,286 @@ const docTemplate = `{
                     "minLength": 6
                 }
             }
+        },
+        "models.WaitlistEntry": {
+            "description": "WaitlistEntry is a request for a resource and time window which is currently taken. When the window frees up the entry is offered: a held booking is created (BookingId) which the user must confirm before HoldExpiresAt.",
+            "type": "object",
+            "required": [
+                "end_time",
+                "resource_id",
+                "start_time",
+                "text",
+                "user_id"
+            ],
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "hold_expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "party_size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "priority": {
+                    "type": "boolean"
+                },
+                "resource_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.Webhook": {
+            "description": "Webhook is a subscription of an external url to events. Events are posted as json signed with the secret, see README. Secret is generated when empty and only returned on creation.",
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 16
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "description": "WebhookDelivery is an event queued for a webhook together with the outcome of its last attempt",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_code": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "policy.Violation": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pricing.AppliedDiscount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "discount_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pricing.AppliedMembership": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "membership_id": {
+                    "type": "integer"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                }
+            }
+        },
+        "pricing.AppliedPromo": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "promo_code_id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pricing.Line": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_per_hour": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "tariff_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pricing.Quote": {
+            "description": "Quote is the price of a booking in minor currency units (e.g. kopecks or cents) with its breakdown: a line per tariff band the booking touches, the hours covered by a membership, the applied discounts, the promo code and the total. Time not covered by any tariff is free and reported as unpriced_minutes.",
+            "type": "object",
+            "properties": {
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.AppliedDiscount"
+                    }
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.Line"
+                    }
+                },
+                "membership": {
+                    "$ref": "#/definitions/pricing.AppliedMembership"
+                },
+                "promo": {
+                    "$ref": "#/definitions/pricing.AppliedPromo"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "unpriced_minutes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.Slot": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "remaining_capacity": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -417,10 +7121,10 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "",
 	Host:             "",
-	BasePath:         "/api",
+	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "RESTful API test project for MireaCyberZone",
-	Description:      "This project works with PostgresSQL. It has functionality to create users and bookings. One user can have multiple bookings.",
+	Description:      "This project works with PostgresSQL. It has functionality to create users and bookings. One user can have multiple bookings.\nTimes are RFC 3339 with offset. Add ?tz=<IANA zone> to any request to render response times in that zone.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -1,26 +1,52 @@
 package app
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"os"
 
+	"github.com/alexey-dobry/booking-service/server/internal/cli"
+	"github.com/alexey-dobry/booking-service/server/internal/config"
+	"github.com/alexey-dobry/booking-service/server/internal/graph"
 	"github.com/alexey-dobry/booking-service/server/internal/logger"
+	"github.com/alexey-dobry/booking-service/server/internal/rpc"
 	"github.com/alexey-dobry/booking-service/server/internal/server"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
 )
 
 type App struct {
-	server *server.Server
+	server   *server.Server
+	grpc     *grpc.Server
+	grpcPort int
 }
 
-func New(database *pgx.Conn, logger *logger.Logger) *App {
+func New(database *pgxpool.Pool, logger *logger.Logger, cfg config.Config) *App {
 	a := App{
-		server: server.New(database, logger),
+		server:   server.New(database, logger, cfg),
+		grpcPort: cfg.GrpcPort,
 	}
+	a.grpc = rpc.New(a.server, logger)
+	a.server.Handle("/graphql", graph.NewHandler(a.server, logger)).Methods("POST")
 	log.Print("App instance created")
 	return &a
 }
 
 func (a *App) Run() {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", a.grpcPort))
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port; additional info: %s", err)
+	}
+	go func() {
+		log.Fatal(a.grpc.Serve(listener))
+	}()
+
 	log.Print("App is started")
 	a.server.Run()
 }
+
+// RunCommand executes a command line command, e.g. import or export, instead of serving requests
+func (a *App) RunCommand(args []string) error {
+	return cli.Run(a.server, args, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,317 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// maxLineSize limits a single NDJSON record
+const maxLineSize = 1 << 20
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ParseFormat returns format by name or by content type, defaulting to NDJSON
+func ParseFormat(name, contentType string) (string, error) {
+	switch {
+	case name == FormatCSV, name == "" && strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV, nil
+	case name == FormatNDJSON, name == "":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected csv or ndjson", name)
+}
+
+// field is a struct field exported as a column named after its json tag
+type field struct {
+	name  string
+	index int
+}
+
+func fields(t reflect.Type) []field {
+	var fs []field
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fs = append(fs, field{name: name, index: i})
+	}
+	return fs
+}
+
+// Decoder reads records one by one
+type Decoder struct {
+	format  string
+	csv     *csv.Reader
+	lines   *bufio.Scanner
+	header  []string
+	line    int
+	started bool
+}
+
+// NewDecoder returns decoder of records in format read from r. CSV input must start with
+// a header naming columns after json fields of decoded structs.
+func NewDecoder(r io.Reader, format string) *Decoder {
+	d := &Decoder{format: format}
+	if format == FormatCSV {
+		d.csv = csv.NewReader(r)
+		d.csv.FieldsPerRecord = -1
+	} else {
+		d.lines = bufio.NewScanner(r)
+		d.lines.Buffer(make([]byte, 64*1024), maxLineSize)
+	}
+	return d
+}
+
+// Line returns the input line of the last decoded record
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// Decode reads the next record into struct pointed by v. It returns io.EOF when input is over.
+// Errors of a single record, which leave the decoder usable, are wrapped in *RecordError.
+func (d *Decoder) Decode(v any) error {
+	if d.format == FormatCSV {
+		return d.decodeCSV(v)
+	}
+
+	for d.lines.Scan() {
+		d.line++
+		line := strings.TrimSpace(d.lines.Text())
+		if line == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(line), v); err != nil {
+			return &RecordError{Line: d.line, Err: err}
+		}
+		return nil
+	}
+	if err := d.lines.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+func (d *Decoder) decodeCSV(v any) error {
+	if !d.started {
+		d.started = true
+		header, err := d.csv.Read()
+		if err != nil {
+			return err
+		}
+		for i := range header {
+			header[i] = strings.TrimSpace(header[i])
+		}
+		d.header = header
+	}
+
+	record, err := d.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			d.line = parseErr.Line
+			return &RecordError{Line: d.line, Err: err}
+		}
+		return err
+	}
+	d.line, _ = d.csv.FieldPos(0)
+
+	rv := reflect.ValueOf(v).Elem()
+	columns := make(map[string]int)
+	for _, f := range fields(rv.Type()) {
+		columns[f.name] = f.index
+	}
+
+	for i, value := range record {
+		if i >= len(d.header) {
+			return &RecordError{Line: d.line, Err: fmt.Errorf("record has more fields than header")}
+		}
+		index, ok := columns[d.header[i]]
+		if !ok {
+			continue
+		}
+		if err := setField(rv.Field(index), value); err != nil {
+			return &RecordError{Line: d.line, Err: fmt.Errorf("%s: %w", d.header[i], err)}
+		}
+	}
+	return nil
+}
+
+// setField parses CSV value into f. Empty values leave f unchanged.
+func setField(f reflect.Value, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if f.Kind() == reflect.Pointer {
+		ptr := reflect.New(f.Type().Elem())
+		if err := setField(ptr.Elem(), value); err != nil {
+			return err
+		}
+		f.Set(ptr)
+		return nil
+	}
+
+	if f.Type() == timeType {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		// nested values are written as json, see formatField
+		return json.Unmarshal([]byte(value), f.Addr().Interface())
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
+
+// formatField renders f as a CSV value
+func formatField(f reflect.Value, loc *time.Location) string {
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return ""
+		}
+		f = f.Elem()
+	}
+
+	if f.Type() == timeType {
+		t := f.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(loc).Format(time.RFC3339)
+	}
+
+	switch f.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		data, _ := json.Marshal(f.Interface())
+		return string(data)
+	}
+	return fmt.Sprint(f.Interface())
+}
+
+// Encoder writes records of a single struct type
+type Encoder struct {
+	format   string
+	w        io.Writer
+	csv      *csv.Writer
+	location *time.Location
+	fields   []field
+}
+
+// NewEncoder returns encoder writing records in format to w with times in loc
+func NewEncoder(w io.Writer, format string, loc *time.Location) *Encoder {
+	e := &Encoder{format: format, w: w, location: loc}
+	if format == FormatCSV {
+		e.csv = csv.NewWriter(w)
+	}
+	return e
+}
+
+// Encode writes struct v as a record. The first call also writes the CSV header.
+func (e *Encoder) Encode(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	if e.fields == nil {
+		e.fields = fields(rv.Type())
+		if e.format == FormatCSV {
+			header := make([]string, len(e.fields))
+			for i, f := range e.fields {
+				header[i] = f.name
+			}
+			if err := e.csv.Write(header); err != nil {
+				return err
+			}
+		}
+	}
+
+	if e.format == FormatCSV {
+		record := make([]string, len(e.fields))
+		for i, f := range e.fields {
+			record[i] = formatField(rv.Field(f.index), e.location)
+		}
+		return e.csv.Write(record)
+	}
+
+	// fields are written in struct order, with times in the encoder's location
+	var line strings.Builder
+	line.WriteByte('{')
+	for i, f := range e.fields {
+		value := rv.Field(f.index)
+		if t, ok := value.Interface().(time.Time); ok {
+			value = reflect.ValueOf(t.In(e.location))
+		} else if t, ok := value.Interface().(*time.Time); ok && t != nil {
+			value = reflect.ValueOf(t.In(e.location))
+		}
+
+		data, err := json.Marshal(value.Interface())
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		fmt.Fprintf(&line, "%q:%s", f.name, data)
+	}
+	line.WriteString("}\n")
+
+	_, err := io.WriteString(e.w, line.String())
+	return err
+}
+
+// Flush writes buffered CSV records
+func (e *Encoder) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// RecordError is an error in a single record of the input
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
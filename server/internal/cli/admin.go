@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+)
+
+// newPassword returns a random password which passes validation of users
+func newPassword() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func runUser(s *server.Server, command string, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("user "+command, flag.ContinueOnError)
+	password := flags.String("password", "", "password, generated if not set")
+	admin := flags.Bool("admin", false, "give the user admin role")
+
+	positional, err := parseInterspersed(flags, args)
+	if err != nil || len(positional) != 1 || command == "reset-password" && *admin {
+		Usage()
+		return ErrUsage
+	}
+	username := positional[0]
+
+	generated := *password == ""
+	if generated {
+		if *password, err = newPassword(); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+
+	switch command {
+	case "create":
+		user, err := s.CreateUser(ctx, models.User{Username: username, Password: *password})
+		if err != nil {
+			return err
+		}
+		if *admin {
+			if err := s.SetUserRole(ctx, user.Id, models.RoleAdmin); err != nil {
+				return err
+			}
+			user.Role = models.RoleAdmin
+		}
+		fmt.Fprintf(stdout, "Created user %s with id %d and role %s\n", user.Username, user.Id, user.Role)
+	case "reset-password":
+		user, err := s.UserByUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+		if _, err := s.UpdateUser(ctx, user.Id, models.User{Password: *password}); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Reset password of user %s\n", user.Username)
+	default:
+		Usage()
+		return ErrUsage
+	}
+
+	if generated {
+		fmt.Fprintf(stdout, "Password: %s\n", *password)
+	}
+	return nil
+}
+
+// parseTime reads an RFC 3339 time or a date, which starts at midnight in loc
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, loc)
+}
+
+func runPurge(s *server.Server, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("bookings purge", flag.ContinueOnError)
+	before := flags.String("before", "", "purge bookings which ended before this time or date")
+	dryRun := flags.Bool("dry-run", false, "only count the bookings")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *before == "" {
+		Usage()
+		return ErrUsage
+	}
+
+	t, err := parseTime(*before, s.Location())
+	if err != nil {
+		return fmt.Errorf("-before: %w", err)
+	}
+
+	count, err := s.PurgeBookings(context.Background(), t, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "%d bookings ended before %s and would be purged\n", count, t.Format(time.RFC3339))
+		return nil
+	}
+	fmt.Fprintf(stdout, "Purged %d bookings which ended before %s\n", count, t.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alexey-dobry/booking-service/server/internal/bulk"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+)
+
+const usage = `Usage:
+  %[1]s [serve]              run the server
+  %[1]s migrate up|down|status
+  %[1]s migrate to version   apply or roll back migrations up to version
+  %[1]s seed                 add demo resources and users
+  %[1]s user create username [-password password] [-admin]
+  %[1]s user reset-password username [-password password]
+  %[1]s bookings purge -before time [-dry-run]
+  %[1]s import users|bookings [-format csv|ndjson] [-mode all_or_nothing|best_effort] [-dry-run] [file]
+  %[1]s export users|bookings [-format csv|ndjson] [file]
+
+Without file import reads standard input and export writes standard output. Without -password a
+random password is generated and printed. Times are RFC 3339 or dates, e.g. 2025-01-01.
+Commands other than migrate refuse to run while migrations are pending, unless AUTO_MIGRATE=true.
+`
+
+// ErrUsage is returned for unknown commands and arguments
+var ErrUsage = errors.New("wrong command line arguments")
+
+// commands are those executed by Run
+var commands = []string{"seed", "user", "bookings", "import", "export"}
+
+// IsCommand reports whether Run executes command name
+func IsCommand(name string) bool {
+	return slices.Contains(commands, name)
+}
+
+// Usage prints the commands of the binary
+func Usage() {
+	fmt.Fprintf(os.Stderr, usage, filepath.Base(os.Args[0]))
+}
+
+// Run executes the command given by args against s
+func Run(s *server.Server, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 1 && args[0] == "seed" {
+		return runSeed(s, stdout)
+	}
+	if len(args) < 2 {
+		Usage()
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "import":
+		return runImport(s, args[1], args[2:], stdin, stdout)
+	case "export":
+		return runExport(s, args[1], args[2:], stdout)
+	case "user":
+		return runUser(s, args[1], args[2:], stdout)
+	case "bookings":
+		if args[1] == "purge" {
+			return runPurge(s, args[2:], stdout)
+		}
+	}
+
+	Usage()
+	return ErrUsage
+}
+
+// parseInterspersed parses flags which may follow positional arguments, e.g. "user create name
+// -admin", and returns the positional arguments
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, ErrUsage
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func runImport(s *server.Server, entity string, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or ndjson, by default taken from file extension")
+	mode := flags.String("mode", models.ImportModeAllOrNothing, "all_or_nothing or best_effort")
+	dryRun := flags.Bool("dry-run", false, "only validate records")
+
+	positional, err := parseInterspersed(flags, args)
+	if err != nil || len(positional) > 1 {
+		Usage()
+		return ErrUsage
+	}
+
+	importer := s.ImportUsers
+	switch entity {
+	case "users":
+	case "bookings":
+		importer = s.ImportBookings
+	default:
+		Usage()
+		return ErrUsage
+	}
+
+	in := stdin
+	if len(positional) == 1 {
+		file := positional[0]
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+
+		if *format == "" && strings.HasSuffix(file, ".csv") {
+			*format = bulk.FormatCSV
+		}
+	}
+
+	parsedFormat, err := bulk.ParseFormat(*format, "")
+	if err != nil {
+		return err
+	}
+
+	result, err := importer(context.Background(), in, models.ImportOptions{Format: parsedFormat, Mode: *mode, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d of %d records were rejected", len(result.Errors), result.Total)
+	}
+	return nil
+}
+
+func runExport(s *server.Server, entity string, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", bulk.FormatNDJSON, "csv or ndjson")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		Usage()
+		return ErrUsage
+	}
+
+	exporter := s.ExportUsers
+	switch entity {
+	case "users":
+	case "bookings":
+		exporter = s.ExportBookings
+	default:
+		Usage()
+		return ErrUsage
+	}
+
+	parsedFormat, err := bulk.ParseFormat(*format, "")
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+	if file := flags.Arg(0); file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return exporter(context.Background(), out, parsedFormat)
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+)
+
+// Migrate executes a migrate command given by args: up, down, status or to version. Legacy wall
+// clock times are converted in venue zone.
+func Migrate(db *pgxpool.Pool, venue *time.Location, args []string, stdout io.Writer) error {
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		Usage()
+		return ErrUsage
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return database.MigrateUp(ctx, db, venue)
+	case args[0] == "down" && len(args) == 1:
+		return database.MigrateDown(ctx, db, venue)
+	case args[0] == "status" && len(args) == 1:
+		return printStatus(ctx, db, stdout)
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			Usage()
+			return ErrUsage
+		}
+		return database.MigrateTo(ctx, db, venue, version)
+	}
+
+	Usage()
+	return ErrUsage
+}
+
+func printStatus(ctx context.Context, db *pgxpool.Pool, stdout io.Writer) error {
+	status, err := database.MigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+	for _, s := range status {
+		appliedAt := "pending"
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, s.Source.Path)
+	}
+	return w.Flush()
+}
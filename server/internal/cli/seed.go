@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+)
+
+// seedPassword is the password of the demo users
+const seedPassword = "demo-password"
+
+var seedResources = []models.Resource{
+	{Name: "PC-01", Type: "pc", Zone: "hall"},
+	{Name: "PC-02", Type: "pc", Zone: "hall"},
+	{Name: "PC-03", Type: "pc", Zone: "hall"},
+	{Name: "PC-04", Type: "pc", Zone: "hall"},
+	{Name: "PC-05", Type: "pc", Zone: "vip"},
+	{Name: "PC-06", Type: "pc", Zone: "vip"},
+	{Name: "PS5-01", Type: "console", Zone: "lounge"},
+	{Name: "PS5-02", Type: "console", Zone: "lounge"},
+	{Name: "Bootcamp room", Type: "room", Zone: "vip"},
+}
+
+var seedUsers = []models.User{
+	{Username: "demo_member", Role: models.RoleMember},
+	{Username: "demo_staff", Role: models.RoleStaff},
+	{Username: "demo_admin", Role: models.RoleAdmin},
+}
+
+// runSeed adds demo resources and users. Entries which already exist, by name and username, are
+// left as they are, so seeding twice changes nothing.
+func runSeed(s *server.Server, stdout io.Writer) error {
+	ctx := context.Background()
+
+	resources, err := s.ListResources(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		existing[r.Name] = true
+	}
+
+	for _, r := range seedResources {
+		if existing[r.Name] {
+			continue
+		}
+		created, err := s.CreateResource(ctx, r)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Created resource %s with id %d\n", created.Name, created.Id)
+	}
+
+	for _, u := range seedUsers {
+		var e *server.Error
+		if _, err := s.UserByUsername(ctx, u.Username); err == nil {
+			continue
+		} else if !errors.As(err, &e) || e.Kind != server.KindNotFound {
+			return err
+		}
+
+		created, err := s.CreateUser(ctx, models.User{Username: u.Username, Password: seedPassword})
+		if err != nil {
+			return err
+		}
+		if u.Role != models.RoleMember {
+			if err := s.SetUserRole(ctx, created.Id, u.Role); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(stdout, "Created user %s with id %d and role %s\n", created.Username, created.Id, u.Role)
+	}
+
+	fmt.Fprintf(stdout, "Demo users have password %s\n", seedPassword)
+	return nil
+}
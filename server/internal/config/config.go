@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the server image has no zoneinfo database
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds service settings read from environment variables
+type Config struct {
+	// Location is the venue time zone used for opening hours, daily and weekly
+	// boundaries and as the default zone of times in responses
+	Location *time.Location
+	// OutboxSinks lists sinks events are relayed to: webhooks, log and broker
+	OutboxSinks []string
+	// GrpcPort is the port of the gRPC API
+	GrpcPort int
+	// AutoMigrate applies pending migrations on start instead of refusing to start
+	AutoMigrate bool
+	// Prepaid charges bookings to user balances and rejects those the balance does not cover
+	Prepaid bool
+	// DefaultResourceId is booked by requests which name no resource, e.g. of clients written
+	// before there were resources; 0 falls back to the resource named general
+	DefaultResourceId int
+}
+
+// Load reads configuration from environment variables, which may also be set in ../.env
+func Load() (Config, error) {
+	var cfg Config
+
+	godotenv.Load("../.env")
+
+	timezone := os.Getenv("VENUE_TIMEZONE")
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return cfg, fmt.Errorf("VENUE_TIMEZONE: %w", err)
+	}
+	cfg.Location = loc
+
+	sinks := os.Getenv("OUTBOX_SINKS")
+	if sinks == "" {
+		sinks = "webhooks"
+	}
+	for _, sink := range strings.Split(sinks, ",") {
+		sink = strings.TrimSpace(sink)
+		switch sink {
+		case "webhooks", "log", "broker":
+			cfg.OutboxSinks = append(cfg.OutboxSinks, sink)
+		case "":
+		default:
+			return cfg, fmt.Errorf("OUTBOX_SINKS: unknown sink %q", sink)
+		}
+	}
+
+	cfg.GrpcPort = 9000
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		if cfg.GrpcPort, err = strconv.Atoi(port); err != nil || cfg.GrpcPort < 1 || cfg.GrpcPort > 65535 {
+			return cfg, fmt.Errorf("GRPC_PORT: %q is not a port number", port)
+		}
+	}
+
+	if v := os.Getenv("AUTO_MIGRATE"); v != "" {
+		if cfg.AutoMigrate, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("AUTO_MIGRATE: %q is not a boolean", v)
+		}
+	}
+
+	if v := os.Getenv("PREPAID"); v != "" {
+		if cfg.Prepaid, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("PREPAID: %q is not a boolean", v)
+		}
+	}
+
+	if v := os.Getenv("DEFAULT_RESOURCE_ID"); v != "" {
+		if cfg.DefaultResourceId, err = strconv.Atoi(v); err != nil || cfg.DefaultResourceId < 1 {
+			return cfg, fmt.Errorf("DEFAULT_RESOURCE_ID: %q is not a resource id", v)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// VenueSetting is the session setting holding the venue time zone during migrations. Migrations
+// which convert wall clock times, written before times were stored with their offset, read it.
+const VenueSetting = "booking.venue_timezone"
+
+// migrate runs fn with a provider of the embedded migrations on connections with VenueSetting set
+// to venue. Applying and rolling back holds a session advisory lock, so replicas starting together
+// migrate one after another instead of racing; the others wait up to five minutes and then see the
+// migrations applied.
+func migrate(ctx context.Context, pool *pgxpool.Pool, venue *time.Location, fn func(p *goose.Provider) error) error {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return err
+	}
+
+	config := pool.Config()
+	config.ConnConfig.RuntimeParams[VenueSetting] = venue.String()
+	venuePool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to connect for migrations; additional info: %w", err)
+	}
+	defer venuePool.Close()
+
+	db := stdlib.OpenDBFromPool(venuePool)
+	defer db.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS,
+		goose.WithSessionLocker(locker),
+		goose.WithVerbose(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations; additional info: %w", err)
+	}
+
+	return fn(provider)
+}
+
+// MigrateUp applies all pending migrations, converting legacy wall clock times in venue zone
+func MigrateUp(ctx context.Context, pool *pgxpool.Pool, venue *time.Location) error {
+	return migrate(ctx, pool, venue, func(p *goose.Provider) error {
+		_, err := p.Up(ctx)
+		return err
+	})
+}
+
+// MigrateDown rolls back the latest applied migration
+func MigrateDown(ctx context.Context, pool *pgxpool.Pool, venue *time.Location) error {
+	return migrate(ctx, pool, venue, func(p *goose.Provider) error {
+		_, err := p.Down(ctx)
+		return err
+	})
+}
+
+// MigrateTo applies or rolls back migrations until version is the latest applied one;
+// version 0 rolls back everything
+func MigrateTo(ctx context.Context, pool *pgxpool.Pool, venue *time.Location, version int64) error {
+	return migrate(ctx, pool, venue, func(p *goose.Provider) error {
+		current, err := p.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if version < current {
+			_, err = p.DownTo(ctx, version)
+		} else {
+			_, err = p.UpTo(ctx, version)
+		}
+		return err
+	})
+}
+
+// MigrationStatus returns every embedded migration with the time it was applied, if it was
+func MigrationStatus(ctx context.Context, pool *pgxpool.Pool) ([]*goose.MigrationStatus, error) {
+	var status []*goose.MigrationStatus
+	err := migrate(ctx, pool, time.UTC, func(p *goose.Provider) (err error) {
+		status, err = p.Status(ctx)
+		return err
+	})
+	return status, err
+}
+
+// SchemaError reports a database schema which is older than the migrations of the binary
+type SchemaError struct {
+	Current int64
+	Target  int64
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("database schema is at version %d, but this binary expects %d; run \"%s migrate up\" or set AUTO_MIGRATE=true",
+		e.Current, e.Target, filepath.Base(os.Args[0]))
+}
+
+// CheckSchema returns *SchemaError unless every embedded migration is applied
+func CheckSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	return migrate(ctx, pool, time.UTC, func(p *goose.Provider) error {
+		pending, err := p.HasPending(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check schema version; additional info: %w", err)
+		}
+		if !pending {
+			return nil
+		}
+
+		current, target, err := p.GetVersions(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check schema version; additional info: %w", err)
+		}
+		return &SchemaError{Current: current, Target: target}
+	})
+}
@@ -0,0 +1,160 @@
+// Package graph serves a GraphQL API of users and bookings on top of the service methods of
+// server.Server. Nested fields are loaded in batches per request, so listing users with their
+// bookings takes one query per level instead of one per row.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alexey-dobry/booking-service/server/internal/logger"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/policy"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schema string
+
+const (
+	// maxDepth limits nesting of queries, e.g. user { bookings { user { bookings ... } } }
+	maxDepth = 8
+	// maxParallelism limits resolvers of a request running at once
+	maxParallelism = 10
+)
+
+// Error codes reported in extensions of errors
+const (
+	codeInvalid  = "BAD_USER_INPUT"
+	codeNotFound = "NOT_FOUND"
+	codeConflict = "CONFLICT"
+	codePolicy   = "POLICY_VIOLATION"
+	codeFunds    = "INSUFFICIENT_FUNDS"
+	codeAuth     = "UNAUTHENTICATED"
+	codeDenied   = "FORBIDDEN"
+	codeInternal = "INTERNAL"
+)
+
+// gqlError is an error of a resolver with its code and violated policy rules in extensions
+type gqlError struct {
+	Message    string
+	Code       string
+	Violations []policy.Violation
+}
+
+func (e *gqlError) Error() string {
+	return e.Message
+}
+
+func (e *gqlError) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.Code}
+	if len(e.Violations) > 0 {
+		extensions["violations"] = e.Violations
+	}
+	return extensions
+}
+
+// kindOf returns kind of an error of a service method
+func kindOf(err error) server.ErrorKind {
+	var e *server.Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return server.KindInternal
+}
+
+// toError converts an error of a service method
+func toError(err error) error {
+	var e *server.Error
+	if !errors.As(err, &e) {
+		return &gqlError{Message: err.Error(), Code: codeInternal}
+	}
+
+	switch e.Kind {
+	case server.KindInvalid:
+		return &gqlError{Message: e.Message, Code: codeInvalid}
+	case server.KindNotFound:
+		return &gqlError{Message: e.Message, Code: codeNotFound}
+	case server.KindConflict:
+		return &gqlError{Message: e.Message, Code: codeConflict}
+	case server.KindPolicy:
+		return &gqlError{Message: e.Message, Code: codePolicy, Violations: e.Violations}
+	case server.KindInsufficientFunds:
+		return &gqlError{Message: e.Message, Code: codeFunds}
+	case server.KindUnauthenticated:
+		return &gqlError{Message: e.Message, Code: codeAuth}
+	case server.KindForbidden:
+		return &gqlError{Message: e.Message, Code: codeDenied}
+	default:
+		return &gqlError{Message: e.Message, Code: codeInternal}
+	}
+}
+
+type contextKey int
+
+const loadersContextKey contextKey = iota
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersContextKey).(*loaders)
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// NewHandler returns a handler executing GraphQL requests posted as json. Mutations charging
+// bookings require HTTP basic auth of a member, staff or an admin, like the REST routes.
+func NewHandler(s *server.Server, logger *logger.Logger) http.Handler {
+	parsed := graphql.MustParseSchema(schema, &resolver{server: s},
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(maxParallelism),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req request
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		ctx := r.Context()
+		if username, password, ok := r.BasicAuth(); ok {
+			user, err := s.Authenticate(ctx, username, password, models.RoleMember, models.RoleStaff, models.RoleAdmin)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Basic realm="booking-service"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				logger.Debug(err.Error())
+				return
+			}
+			ctx = server.ContextWithUser(ctx, user)
+		}
+
+		ctx = context.WithValue(ctx, loadersContextKey, newLoaders(s))
+		response := parsed.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+		for _, err := range response.Errors {
+			var e *gqlError
+			if errors.As(err, &e) && e.Code == codeInternal {
+				logger.Error(fmt.Sprintf("GraphQL error: %s", err))
+			} else {
+				logger.Debug(fmt.Sprintf("GraphQL error: %s", err))
+			}
+		}
+
+		json.NewEncoder(w).Encode(response)
+		if len(response.Errors) == 0 {
+			logger.Debug("Successfully executed GraphQL request")
+		}
+	})
+}
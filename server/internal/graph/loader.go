@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+)
+
+// loader fetches values by id in batches, dataloader-style: ids are registered while their
+// parents are resolved, and the first load fetches all registered ids at once instead of
+// running one query per row
+type loader[V any] struct {
+	fetch func(ctx context.Context, ids []int) (map[int]V, error)
+
+	mu      sync.Mutex
+	pending map[int]bool
+	loaded  map[int]bool
+	values  map[int]V
+}
+
+func newLoader[V any](fetch func(ctx context.Context, ids []int) (map[int]V, error)) *loader[V] {
+	return &loader[V]{
+		fetch:   fetch,
+		pending: make(map[int]bool),
+		loaded:  make(map[int]bool),
+		values:  make(map[int]V),
+	}
+}
+
+// register adds ids to the next batch
+func (l *loader[V]) register(ids ...int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range ids {
+		if !l.loaded[id] {
+			l.pending[id] = true
+		}
+	}
+}
+
+// prime stores a value which is already known
+func (l *loader[V]) prime(id int, v V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.values[id] = v
+	l.loaded[id] = true
+	delete(l.pending, id)
+}
+
+// load returns value of id, fetching the whole pending batch if it is not loaded yet.
+// ok is false if there is no value for id.
+func (l *loader[V]) load(ctx context.Context, id int) (v V, ok bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.loaded[id] {
+		l.pending[id] = true
+		ids := slices.Sorted(maps.Keys(l.pending))
+
+		values, err := l.fetch(ctx, ids)
+		if err != nil {
+			return v, false, err
+		}
+		for _, id := range ids {
+			if value, ok := values[id]; ok {
+				l.values[id] = value
+			}
+			l.loaded[id] = true
+		}
+		clear(l.pending)
+	}
+
+	v, ok = l.values[id]
+	return v, ok, nil
+}
+
+// loaders batch the queries of a single request
+type loaders struct {
+	server    *server.Server
+	users     *loader[models.User]
+	resources *loader[models.Resource]
+
+	mu sync.Mutex
+	// userIds are the users in the response so far, whose bookings are fetched together
+	userIds map[int]bool
+	// bookings has a loader per filter of User.bookings
+	bookings map[string]*loader[[]models.Booking]
+}
+
+func newLoaders(s *server.Server) *loaders {
+	return &loaders{
+		server:    s,
+		users:     newLoader(s.UsersByIds),
+		resources: newLoader(s.ResourcesByIds),
+		userIds:   make(map[int]bool),
+		bookings:  make(map[string]*loader[[]models.Booking]),
+	}
+}
+
+// addUser notes that u is part of the response, so that its bookings are fetched in the same batch
+// as those of other users
+func (l *loaders) addUser(u models.User) {
+	l.users.prime(u.Id, u)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.userIds[u.Id] {
+		return
+	}
+	l.userIds[u.Id] = true
+	for _, bookings := range l.bookings {
+		bookings.register(u.Id)
+	}
+}
+
+// addBooking notes that b is part of the response, so that its user and resource are fetched in
+// the same batch as those of other bookings
+func (l *loaders) addBooking(b models.Booking) {
+	l.users.register(b.UserId)
+	l.resources.register(b.ResourceId)
+}
+
+// userBookings returns the loader of bookings matching f by user
+func (l *loaders) userBookings(f models.BookingFilter) *loader[[]models.Booking] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := fmt.Sprintf("%+v", f)
+	bookings, ok := l.bookings[key]
+	if !ok {
+		bookings = newLoader(func(ctx context.Context, ids []int) (map[int][]models.Booking, error) {
+			return l.server.BookingsOfUsers(ctx, ids, f)
+		})
+		bookings.register(slices.Collect(maps.Keys(l.userIds))...)
+		l.bookings[key] = bookings
+	}
+	return bookings
+}
@@ -0,0 +1,578 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// defaultPageSize is the page size of booking lists without page argument
+const defaultPageSize = 50
+
+type resolver struct {
+	server *server.Server
+}
+
+func toID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+func fromID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n < 1 {
+		return 0, &gqlError{Message: fmt.Sprintf("Incorrect input data: %q is not an id", id), Code: codeInvalid}
+	}
+	return n, nil
+}
+
+// toTime renders t in the time zone of the request, see server.ZoneFromContext
+func toTime(ctx context.Context, t time.Time) graphql.Time {
+	if loc := server.ZoneFromContext(ctx); loc != nil {
+		t = t.In(loc)
+	}
+	return graphql.Time{Time: t}
+}
+
+func fromTime(t *graphql.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+type bookingFilterInput struct {
+	ResourceId       *graphql.ID
+	Statuses         *[]string
+	From             *graphql.Time
+	To               *graphql.Time
+	IncludeCancelled *bool
+}
+
+// pageInput always has both fields, schema defaults fill in missing ones
+type pageInput struct {
+	Limit  int32
+	Offset int32
+}
+
+type bookingsArgs struct {
+	Filter *bookingFilterInput
+	Page   *pageInput
+}
+
+// filter converts arguments of a booking list
+func (args bookingsArgs) filter() (models.BookingFilter, error) {
+	f := models.BookingFilter{Limit: defaultPageSize}
+
+	if args.Filter != nil {
+		if args.Filter.ResourceId != nil {
+			id, err := fromID(*args.Filter.ResourceId)
+			if err != nil {
+				return f, err
+			}
+			f.ResourceId = id
+		}
+		if args.Filter.Statuses != nil {
+			f.Statuses = *args.Filter.Statuses
+		}
+		f.From = fromTime(args.Filter.From)
+		f.To = fromTime(args.Filter.To)
+		f.IncludeCancelled = args.Filter.IncludeCancelled != nil && *args.Filter.IncludeCancelled
+	}
+
+	if args.Page != nil {
+		f.Limit = int(args.Page.Limit)
+		f.Offset = int(args.Page.Offset)
+	}
+	return f, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.server.GetUser(ctx, id)
+	if kindOf(err) == server.KindNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, toError(err)
+	}
+	return newUserResolver(ctx, user), nil
+}
+
+func (r *resolver) Users(ctx context.Context) ([]*userResolver, error) {
+	users, err := r.server.ListUsers(ctx)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	resolvers := make([]*userResolver, len(users))
+	for i, u := range users {
+		resolvers[i] = newUserResolver(ctx, u)
+	}
+	return resolvers, nil
+}
+
+func (r *resolver) Booking(ctx context.Context, args struct{ ID graphql.ID }) (*bookingResolver, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := r.server.GetBooking(ctx, id)
+	if kindOf(err) == server.KindNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, toError(err)
+	}
+	return newBookingResolver(ctx, booking), nil
+}
+
+func (r *resolver) Bookings(ctx context.Context, args bookingsArgs) ([]*bookingResolver, error) {
+	f, err := args.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := r.server.FindBookings(ctx, f)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return newBookingResolvers(ctx, bookings), nil
+}
+
+func (r *resolver) CreateUser(ctx context.Context, args struct {
+	Input struct {
+		Username string
+		Password string
+	}
+}) (*userResolver, error) {
+	user, err := r.server.CreateUser(ctx, models.User{Username: args.Input.Username, Password: args.Input.Password})
+	if err != nil {
+		return nil, toError(err)
+	}
+	return newUserResolver(ctx, user), nil
+}
+
+func (r *resolver) UpdateUser(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input struct {
+		Username *string
+		Password *string
+	}
+}) (*userResolver, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := server.AuthorizeAccount(ctx, id); err != nil {
+		return nil, toError(err)
+	}
+
+	var newUserData models.User
+	if args.Input.Username != nil {
+		newUserData.Username = *args.Input.Username
+	}
+	if args.Input.Password != nil {
+		newUserData.Password = *args.Input.Password
+	}
+
+	user, err := r.server.UpdateUser(ctx, id, newUserData)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return newUserResolver(ctx, user), nil
+}
+
+func (r *resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := server.AuthorizeAccount(ctx, id); err != nil {
+		return false, toError(err)
+	}
+
+	if err := r.server.DeleteUser(ctx, id); err != nil {
+		return false, toError(err)
+	}
+	return true, nil
+}
+
+func (r *resolver) CreateBooking(ctx context.Context, args struct {
+	Input struct {
+		UserId     graphql.ID
+		ResourceId *graphql.ID
+		StartTime  graphql.Time
+		EndTime    graphql.Time
+		Text       string
+		PartySize  *int32
+		Waitlist   *bool
+		PromoCode  *string
+	}
+}) (*createBookingPayloadResolver, error) {
+	userId, err := fromID(args.Input.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	newBooking := models.Booking{
+		UserId:    userId,
+		StartTime: args.Input.StartTime.Time,
+		EndTime:   args.Input.EndTime.Time,
+		Text:      args.Input.Text,
+	}
+	if args.Input.ResourceId != nil {
+		if newBooking.ResourceId, err = fromID(*args.Input.ResourceId); err != nil {
+			return nil, err
+		}
+	}
+	if args.Input.PartySize != nil {
+		newBooking.PartySize = int(*args.Input.PartySize)
+	}
+	if args.Input.PromoCode != nil {
+		newBooking.PromoCode = *args.Input.PromoCode
+	}
+	waitlist := args.Input.Waitlist != nil && *args.Input.Waitlist
+
+	if err := server.AuthorizeUser(ctx, newBooking.UserId); err != nil {
+		return nil, toError(err)
+	}
+
+	booking, entry, err := r.server.CreateBooking(ctx, newBooking, waitlist)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	if entry != nil {
+		return &createBookingPayloadResolver{entry: newWaitlistEntryResolver(ctx, *entry)}, nil
+	}
+	return &createBookingPayloadResolver{booking: newBookingResolver(ctx, booking)}, nil
+}
+
+func (r *resolver) UpdateBooking(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input struct {
+		ResourceId *graphql.ID
+		StartTime  *graphql.Time
+		EndTime    *graphql.Time
+		Text       *string
+		PartySize  *int32
+	}
+}) (*bookingResolver, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	newBookingData := models.Booking{
+		StartTime: fromTime(args.Input.StartTime),
+		EndTime:   fromTime(args.Input.EndTime),
+	}
+	if args.Input.ResourceId != nil {
+		if newBookingData.ResourceId, err = fromID(*args.Input.ResourceId); err != nil {
+			return nil, err
+		}
+	}
+	if args.Input.Text != nil {
+		newBookingData.Text = *args.Input.Text
+	}
+	if args.Input.PartySize != nil {
+		newBookingData.PartySize = int(*args.Input.PartySize)
+	}
+
+	if err := r.server.AuthorizeBooking(ctx, id); err != nil {
+		return nil, toError(err)
+	}
+
+	booking, err := r.server.UpdateBooking(ctx, id, newBookingData)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return newBookingResolver(ctx, booking), nil
+}
+
+func (r *resolver) CancelBooking(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := fromID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.server.AuthorizeBooking(ctx, id); err != nil {
+		return false, toError(err)
+	}
+
+	if _, err := r.server.CancelBooking(ctx, id); err != nil {
+		return false, toError(err)
+	}
+	return true, nil
+}
+
+type userResolver struct {
+	u models.User
+	l *loaders
+}
+
+func newUserResolver(ctx context.Context, u models.User) *userResolver {
+	l := loadersFromContext(ctx)
+	l.addUser(u)
+	return &userResolver{u: u, l: l}
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return toID(r.u.Id)
+}
+
+func (r *userResolver) Username() string {
+	return r.u.Username
+}
+
+func (r *userResolver) Role() string {
+	return r.u.Role
+}
+
+func (r *userResolver) Priority() bool {
+	return r.u.Priority
+}
+
+func (r *userResolver) CreatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.u.CreatedAt)
+}
+
+func (r *userResolver) UpdatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.u.UpdatedAt)
+}
+
+func (r *userResolver) Bookings(ctx context.Context, args bookingsArgs) ([]*bookingResolver, error) {
+	f, err := args.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, _, err := r.l.userBookings(f).load(ctx, r.u.Id)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return newBookingResolvers(ctx, bookings), nil
+}
+
+type bookingResolver struct {
+	b models.Booking
+	l *loaders
+}
+
+func newBookingResolver(ctx context.Context, b models.Booking) *bookingResolver {
+	l := loadersFromContext(ctx)
+	l.addBooking(b)
+	return &bookingResolver{b: b, l: l}
+}
+
+func newBookingResolvers(ctx context.Context, bookings []models.Booking) []*bookingResolver {
+	resolvers := make([]*bookingResolver, len(bookings))
+	for i, b := range bookings {
+		resolvers[i] = newBookingResolver(ctx, b)
+	}
+	return resolvers
+}
+
+func (r *bookingResolver) ID() graphql.ID {
+	return toID(r.b.Id)
+}
+
+func (r *bookingResolver) User(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, r.l, r.b.UserId)
+}
+
+func (r *bookingResolver) Resource(ctx context.Context) (*resourceResolver, error) {
+	return loadResource(ctx, r.l, r.b.ResourceId)
+}
+
+func (r *bookingResolver) SeriesId() *graphql.ID {
+	if r.b.SeriesId == nil {
+		return nil
+	}
+	id := toID(*r.b.SeriesId)
+	return &id
+}
+
+func (r *bookingResolver) GroupId() *graphql.ID {
+	if r.b.GroupId == nil {
+		return nil
+	}
+	id := toID(*r.b.GroupId)
+	return &id
+}
+
+func (r *bookingResolver) StartTime(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.b.StartTime)
+}
+
+func (r *bookingResolver) EndTime(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.b.EndTime)
+}
+
+func (r *bookingResolver) Text() string {
+	return r.b.Text
+}
+
+func (r *bookingResolver) Status() string {
+	return r.b.Status
+}
+
+func (r *bookingResolver) HoldExpiresAt(ctx context.Context) *graphql.Time {
+	if r.b.HoldExpiresAt == nil {
+		return nil
+	}
+	t := toTime(ctx, *r.b.HoldExpiresAt)
+	return &t
+}
+
+func (r *bookingResolver) Sequence() int32 {
+	return int32(r.b.Sequence)
+}
+
+func (r *bookingResolver) Price() int32 {
+	return int32(r.b.Price)
+}
+
+func (r *bookingResolver) PartySize() int32 {
+	return int32(r.b.PartySize)
+}
+
+func (r *bookingResolver) UpdatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.b.UpdatedAt)
+}
+
+type resourceResolver struct {
+	r models.Resource
+}
+
+func (r *resourceResolver) ID() graphql.ID {
+	return toID(r.r.Id)
+}
+
+func (r *resourceResolver) Name() string {
+	return r.r.Name
+}
+
+func (r *resourceResolver) Type() string {
+	return r.r.Type
+}
+
+func (r *resourceResolver) Zone() string {
+	return r.r.Zone
+}
+
+func (r *resourceResolver) Capacity() int32 {
+	return int32(r.r.Capacity)
+}
+
+func (r *resourceResolver) BufferBefore() int32 {
+	return int32(r.r.BufferBefore)
+}
+
+func (r *resourceResolver) BufferAfter() int32 {
+	return int32(r.r.BufferAfter)
+}
+
+func (r *resourceResolver) RequiresApproval() bool {
+	return r.r.RequiresApproval
+}
+
+func (r *resourceResolver) CreatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.r.CreatedAt)
+}
+
+func (r *resourceResolver) UpdatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.r.UpdatedAt)
+}
+
+type waitlistEntryResolver struct {
+	e models.WaitlistEntry
+	l *loaders
+}
+
+func newWaitlistEntryResolver(ctx context.Context, e models.WaitlistEntry) *waitlistEntryResolver {
+	return &waitlistEntryResolver{e: e, l: loadersFromContext(ctx)}
+}
+
+func (r *waitlistEntryResolver) ID() graphql.ID {
+	return toID(r.e.Id)
+}
+
+func (r *waitlistEntryResolver) User(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, r.l, r.e.UserId)
+}
+
+func (r *waitlistEntryResolver) Resource(ctx context.Context) (*resourceResolver, error) {
+	return loadResource(ctx, r.l, r.e.ResourceId)
+}
+
+func (r *waitlistEntryResolver) StartTime(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.e.StartTime)
+}
+
+func (r *waitlistEntryResolver) EndTime(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.e.EndTime)
+}
+
+func (r *waitlistEntryResolver) Text() string {
+	return r.e.Text
+}
+
+func (r *waitlistEntryResolver) Status() string {
+	return r.e.Status
+}
+
+func (r *waitlistEntryResolver) Priority() bool {
+	return r.e.Priority
+}
+
+func (r *waitlistEntryResolver) CreatedAt(ctx context.Context) graphql.Time {
+	return toTime(ctx, r.e.CreatedAt)
+}
+
+type createBookingPayloadResolver struct {
+	booking *bookingResolver
+	entry   *waitlistEntryResolver
+}
+
+func (r *createBookingPayloadResolver) Booking() *bookingResolver {
+	return r.booking
+}
+
+func (r *createBookingPayloadResolver) WaitlistEntry() *waitlistEntryResolver {
+	return r.entry
+}
+
+// loadUser resolves user id through the batch of the request; a missing user resolves to null
+func loadUser(ctx context.Context, l *loaders, id int) (*userResolver, error) {
+	user, ok, err := l.users.load(ctx, id)
+	if err != nil {
+		return nil, toError(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return newUserResolver(ctx, user), nil
+}
+
+// loadResource resolves resource id through the batch of the request; a missing resource resolves to null
+func loadResource(ctx context.Context, l *loaders, id int) (*resourceResolver, error) {
+	resource, ok, err := l.resources.load(ctx, id)
+	if err != nil {
+		return nil, toError(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &resourceResolver{r: resource}, nil
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	"github.com/graph-gophers/graphql-go"
+)
+
+func code(err error) string {
+	var e *gqlError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func TestUserMutationsRequireAccount(t *testing.T) {
+	// the resolver has no server: the mutations must fail before using it
+	r := &resolver{}
+	member := server.ContextWithUser(context.Background(), models.User{Id: 2, Role: models.RoleMember})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"anonymous", context.Background(), codeAuth},
+		{"another member", member, codeDenied},
+	}
+
+	for _, tt := range tests {
+		var update struct {
+			ID    graphql.ID
+			Input struct {
+				Username *string
+				Password *string
+			}
+		}
+		update.ID = "1"
+		if _, err := r.UpdateUser(tt.ctx, update); code(err) != tt.want {
+			t.Errorf("%s: updateUser returned %v, want %s", tt.name, err, tt.want)
+		}
+		if _, err := r.DeleteUser(tt.ctx, struct{ ID graphql.ID }{ID: "1"}); code(err) != tt.want {
+			t.Errorf("%s: deleteUser returned %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestBookingTimesInZone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Yekaterinburg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC)
+	r := &bookingResolver{b: models.Booking{StartTime: start}}
+
+	if got := r.StartTime(server.ContextWithZone(context.Background(), loc)); got.Location() != loc || !got.Equal(start) {
+		t.Errorf("start = %s, want %s", got, start.In(loc))
+	}
+	if got := r.StartTime(context.Background()); got.Location() != time.UTC {
+		t.Errorf("start without a zone = %s, want %s", got, start)
+	}
+}
@@ -0,0 +1,129 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event statuses (RFC 5545, 3.8.1.11)
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	prodId     = "-//booking-service//booking-service//EN"
+	timeLayout = "20060102T150405Z"
+	// lines longer than this many octets are folded
+	maxLineLength = 75
+)
+
+// Event is a VEVENT component
+type Event struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+}
+
+// Calendar is a VCALENDAR object. Name and RefreshInterval are used by
+// clients which subscribe to the calendar as a feed.
+type Calendar struct {
+	Name            string
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// escape escapes TEXT property values
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// formatDuration formats d as a dur-time value, e.g. PT15M
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%dM", int(d.Minutes()))
+}
+
+// writer writes content lines terminated with CRLF and folded at 75 octets
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *writer) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	// continuation lines start with a space which counts towards their length
+	for limit := maxLineLength; len(line) > limit; limit = maxLineLength - 1 {
+		// do not split multi-byte UTF-8 characters
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		_, w.err = w.w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	_, err := w.w.WriteString(line + "\r\n")
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Write renders c in iCalendar format
+func (c Calendar) Write(out io.Writer) error {
+	w := &writer{w: bufio.NewWriter(out)}
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodId)
+	w.line("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", formatDuration(c.RefreshInterval))
+		w.line("X-PUBLISHED-TTL", formatDuration(c.RefreshInterval))
+	}
+
+	for _, e := range c.Events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", e.UID)
+		w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+		w.line("DTSTAMP", formatTime(e.Stamp))
+		w.line("LAST-MODIFIED", formatTime(e.Stamp))
+		w.line("DTSTART", formatTime(e.Start))
+		w.line("DTEND", formatTime(e.End))
+		w.line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			w.line("LOCATION", escape(e.Location))
+		}
+		w.line("STATUS", e.Status)
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
@@ -0,0 +1,106 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Room 1", "Room 1"},
+		{`C:\temp`, `C:\\temp`},
+		{"Room 1; Floor 2, East", `Room 1\; Floor 2\, East`},
+		{"first\nsecond\r\nthird", `first\nsecond\nthird`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func write(t *testing.T, c Calendar) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// unfold joins folded lines back together (RFC 5545, 3.1)
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	start := time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC)
+	description := strings.Repeat("Größenänderung des Raums ", 10)
+	out := write(t, Calendar{Events: []Event{{
+		UID:         "booking-1",
+		Stamp:       start,
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Room 1",
+		Description: description,
+		Status:      StatusConfirmed,
+	}}})
+
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Error("output does not end with CRLF")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 character: %q", line)
+		}
+	}
+	if !strings.Contains(unfold(out), "\r\nDESCRIPTION:"+escape(description)+"\r\n") {
+		t.Error("unfolded output does not contain the description")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.FixedZone("CET", 3600))
+	out := write(t, Calendar{
+		Name:            "Bookings, Room 1",
+		RefreshInterval: 15 * time.Minute,
+		Events: []Event{{
+			UID:      "booking-1",
+			Sequence: 2,
+			Stamp:    start,
+			Start:    start,
+			End:      start.Add(time.Hour),
+			Summary:  "Team; weekly",
+			Status:   StatusCancelled,
+		}},
+	})
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Bookings\\, Room 1\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT15M\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART:20250303T130000Z\r\n",
+		"DTEND:20250303T140000Z\r\n",
+		"SUMMARY:Team\\; weekly\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "DESCRIPTION") || strings.Contains(out, "LOCATION") {
+		t.Error("output contains empty optional properties")
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 // fix this
 import (
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 
 type Logger struct {
 	ozzo       *oz.Logger
+	console    *oz.ConsoleTarget
 	logDirPath string
 }
 
@@ -30,6 +32,7 @@ func NewLogger() *Logger {
 	}
 
 	targetConsole := oz.NewConsoleTarget()
+	logger.console = targetConsole
 	targetFile := oz.NewFileTarget()
 	targetFile.FileName = logFile.Name()
 
@@ -49,3 +52,8 @@ func (l *Logger) Error(errorMsg string) {
 func (l *Logger) Debug(debugMsg string) {
 	l.ozzo.Debug(debugMsg)
 }
+
+// SetConsoleWriter redirects console output, e.g. to keep stdout clean for command output
+func (l *Logger) SetConsoleWriter(w io.Writer) {
+	l.console.Writer = w
+}
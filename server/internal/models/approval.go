@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Statuses of approvals. Requests not reviewed before their deadline are rejected without a
+// reviewer; withdrawn ones were cancelled before they were reviewed.
+const (
+	ApprovalPending   = "pending"
+	ApprovalApproved  = "approved"
+	ApprovalRejected  = "rejected"
+	ApprovalWithdrawn = "withdrawn"
+)
+
+// @Description Approval is the review of a booking of a resource which requires approval. The booking is pending
+// @Description and blocks its slot until staff approve it, which confirms and charges it, or reject it, which
+// @Description cancels it free of charge. Requests not reviewed before the deadline are rejected automatically;
+// @Description the deadline is a day after the request, or the start of the booking if that is earlier.
+type Approval struct {
+	BookingId  int        `json:"booking_id"`
+	Status     string     `json:"status"`
+	Deadline   time.Time  `json:"deadline"`
+	Reason     string     `json:"reason,omitempty"`
+	ReviewedBy *int       `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Booking    Booking    `json:"booking"`
+}
+
+// @Description Review is a comment of staff on their decision; it is required to reject a booking
+type Review struct {
+	Reason string `json:"reason" validate:"max=200"`
+}
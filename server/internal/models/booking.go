@@ -3,15 +3,65 @@ package models
 import (
 	"time"
 
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
 	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
 )
 
-// @Description Booking is a struct which contains Id, UserId, StartTime and EndTime
+// Booking statuses. A held booking is offered to a waitlisted user and blocks
+// the slot until it is confirmed or HoldExpiresAt passes. A pending booking of a
+// resource which requires approval blocks the slot until staff review it. Cancelled
+// bookings are kept so that calendar subscribers learn about the cancellation.
+const (
+	BookingConfirmed = "confirmed"
+	BookingHeld      = "held"
+	BookingPending   = "pending"
+	BookingCancelled = "cancelled"
+)
+
+// @Description Booking is a struct which contains Id, UserId, ResourceId, StartTime and EndTime.
+// @Description SeriesId is set when the booking is an occurrence of a recurring series, with OccurrenceStart, the time
+// @Description the rule gives the occurrence, and Overridden when the occurrence was changed on its own: such changes
+// @Description are kept when the whole series is updated. GroupId is set when the booking was made as a part of a
+// @Description booking group. PartySize is the number of people, 1 unless set; it counts against
+// @Description the capacity of the resource.
+// @Description Sequence is incremented on every change, as SEQUENCE of the iCalendar event.
+// @Description Price is calculated by the tariffs when the booking is created or rescheduled and kept with
+// @Description its breakdown, so later changes of tariffs do not alter it. CancellationFee is the part of the
+// @Description price kept when the booking was cancelled late. PromoCode is only read when a booking is created;
+// @Description the redeemed code is reported in the price breakdown. New bookings without a resource_id
+// @Description book the default resource of the venue.
 // needs rework: text field
 type Booking struct {
-	Id        int       `json:"id"`
-	UserId    int       `json:"user_id"`
-	StartTime time.Time `json:"start_time" validate:"required"`
-	EndTime   time.Time `json:"end_time" validate:"required"`
-	Text      string    `json:"text" validate:"required,max=100,excludesall=/\\#@$"`
+	Id              int            `json:"id"`
+	UserId          int            `json:"user_id"`
+	ResourceId      int            `json:"resource_id" validate:"min=0"`
+	SeriesId        *int           `json:"series_id,omitempty"`
+	OccurrenceStart *time.Time     `json:"occurrence_start,omitempty"`
+	Overridden      bool           `json:"overridden,omitempty"`
+	GroupId         *int           `json:"group_id,omitempty"`
+	StartTime       time.Time      `json:"start_time" validate:"required"`
+	EndTime         time.Time      `json:"end_time" validate:"required"`
+	PartySize       int            `json:"party_size" validate:"min=0"`
+	Text            string         `json:"text" validate:"required,max=100,excludesall=/\\#@$"`
+	Status          string         `json:"status"`
+	HoldExpiresAt   *time.Time     `json:"hold_expires_at,omitempty"`
+	Sequence        int            `json:"sequence"`
+	Price           int            `json:"price"`
+	PriceBreakdown  *pricing.Quote `json:"price_breakdown,omitempty"`
+	CancellationFee int            `json:"cancellation_fee,omitempty"`
+	PromoCode       string         `json:"promo_code,omitempty" validate:"max=30"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// BookingFilter selects bookings; zero fields match everything. Cancelled bookings are only
+// included with IncludeCancelled or when Statuses asks for them.
+type BookingFilter struct {
+	ResourceId       int      `validate:"min=0"`
+	Statuses         []string `validate:"dive,oneof=confirmed held pending cancelled"`
+	From             time.Time
+	To               time.Time
+	IncludeCancelled bool
+	// Limit and Offset page bookings ordered by start time
+	Limit  int `validate:"min=1,max=500"`
+	Offset int `validate:"min=0"`
 }
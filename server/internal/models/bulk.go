@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Import modes. In all_or_nothing mode nothing is imported if any record fails,
+// in best_effort mode failed records are skipped.
+const (
+	ImportModeAllOrNothing = "all_or_nothing"
+	ImportModeBestEffort   = "best_effort"
+)
+
+// ImportOptions controls how records are imported
+type ImportOptions struct {
+	Format string
+	Mode   string
+	DryRun bool
+}
+
+// @Description UserRecord is a user in import and export files. On import either password (plain text)
+// @Description or password_hash (bcrypt, as exported) must be set.
+type UserRecord struct {
+	Id           int       `json:"id"`
+	Username     string    `json:"username" validate:"required,min=6,max=20,excludesall=\\/#@$"`
+	Password     string    `json:"password,omitempty" validate:"required_without=PasswordHash,omitempty,min=6,max=20"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	Role         string    `json:"role" validate:"omitempty,oneof=member staff admin"`
+	Priority     bool      `json:"priority"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// @Description RecordError reports why a record of an import file was rejected
+type RecordError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// @Description ImportResult reports the outcome of an import: number of records read, records which passed
+// @Description all checks, records written to database (none in dry run or when all_or_nothing import failed)
+// @Description and errors of rejected records
+type ImportResult struct {
+	Mode     string        `json:"mode"`
+	DryRun   bool          `json:"dry_run"`
+	Total    int           `json:"total"`
+	Valid    int           `json:"valid"`
+	Imported int           `json:"imported"`
+	Errors   []RecordError `json:"errors"`
+}
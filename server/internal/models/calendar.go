@@ -0,0 +1,8 @@
+package models
+
+// @Description CalendarFeed is a secret link to the iCalendar feed of user's bookings.
+// @Description Anybody who knows the token can read the feed, so it can be regenerated to revoke a leaked link.
+type CalendarFeed struct {
+	Token string `json:"token"`
+	Url   string `json:"url"`
+}
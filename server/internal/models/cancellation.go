@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description CancellationRule charges fee_percent of the booking price when a booking is cancelled less than
+// @Description hours_before hours before its start_time. Bookings which have already started are charged their full
+// @Description price whatever the rules. Of the rules whose window a cancellation falls into the narrowest one applies,
+// @Description so rules of 24 hours with 50% and 2 hours with 100% make cancelling free until a day before the start,
+// @Description cost half of the price until two hours before and the full price afterwards. A rule applies to all
+// @Description resources unless it is scoped to a resource type; disabled rules are kept but not applied.
+type CancellationRule struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name" validate:"required,max=100"`
+	ResourceType *string   `json:"resource_type,omitempty" validate:"omitempty,max=30"`
+	HoursBefore  int       `json:"hours_before" validate:"min=0"`
+	FeePercent   int       `json:"fee_percent" validate:"min=0,max=100"`
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// @Description Cancellation reports the consequences of cancelling a booking in minor currency units: the fee kept
+// @Description by the venue and the refund of what was paid from the prepaid balance. Without prepaid balances paid and
+// @Description refund are 0 and the fee is to be collected at the front desk. Reason explains the fee.
+type Cancellation struct {
+	BookingId int    `json:"booking_id"`
+	Price     int    `json:"price"`
+	Paid      int    `json:"paid"`
+	Fee       int    `json:"fee"`
+	Refund    int    `json:"refund"`
+	RuleId    *int   `json:"rule_id,omitempty"`
+	Reason    string `json:"reason"`
+}
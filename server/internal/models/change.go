@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Kinds of booking changes
+const (
+	ChangeExtended   = "extended"
+	ChangeEndedEarly = "ended_early"
+	ChangeRelocated  = "relocated"
+	ChangeSwapped    = "swapped"
+)
+
+// @Description BookingChange is an entry of the history of a booking: the resource, times and price before
+// @Description and after an extension, an early end, a relocation or a swap. changed_by is set when the change
+// @Description was made by an authenticated user.
+type BookingChange struct {
+	Id            int       `json:"id"`
+	BookingId     int       `json:"booking_id"`
+	Kind          string    `json:"kind"`
+	OldResourceId int       `json:"old_resource_id"`
+	NewResourceId int       `json:"new_resource_id"`
+	OldStartTime  time.Time `json:"old_start_time"`
+	OldEndTime    time.Time `json:"old_end_time"`
+	NewStartTime  time.Time `json:"new_start_time"`
+	NewEndTime    time.Time `json:"new_end_time"`
+	OldPrice      int       `json:"old_price"`
+	NewPrice      int       `json:"new_price"`
+	ChangedBy     *int      `json:"changed_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// @Description Extension is the time added to the end of a booking
+type Extension struct {
+	Minutes int `json:"minutes" validate:"required,min=1,max=1440"`
+}
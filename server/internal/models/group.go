@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Statuses of group participants. Participants are invited by the organiser and accept or
+// decline the invitation themselves.
+const (
+	ParticipantInvited  = "invited"
+	ParticipantAccepted = "accepted"
+	ParticipantDeclined = "declined"
+)
+
+// @Description BookingGroup books several resources for the same interval at once, e.g. adjacent PCs for a team.
+// @Description All bookings are made in a single transaction for the organiser and paid from their balance:
+// @Description if any resource is taken or breaks a rule, nothing is booked. participant_ids are registered users
+// @Description invited when the group is created; more can be invited later. A booking of the group can be
+// @Description cancelled on its own, cancelling the group cancels all of them.
+type BookingGroup struct {
+	Id             int                `json:"id"`
+	OrganiserId    int                `json:"organiser_id" validate:"required,min=1"`
+	ResourceIds    []int              `json:"resource_ids" validate:"required,min=2,max=50,unique,dive,min=1"`
+	StartTime      time.Time          `json:"start_time" validate:"required"`
+	EndTime        time.Time          `json:"end_time" validate:"required"`
+	Text           string             `json:"text" validate:"required,max=100,excludesall=/\\#@$"`
+	ParticipantIds []int              `json:"participant_ids,omitempty" validate:"max=100,unique,dive,min=1"`
+	Bookings       []Booking          `json:"bookings"`
+	Participants   []GroupParticipant `json:"participants"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// @Description GroupParticipant is a user invited to a booking group by its organiser
+type GroupParticipant struct {
+	UserId      int        `json:"user_id" validate:"required,min=1"`
+	Status      string     `json:"status" validate:"omitempty,oneof=invited accepted declined"`
+	InvitedAt   time.Time  `json:"invited_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
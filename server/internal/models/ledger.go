@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Kinds of ledger entries
+const (
+	LedgerTopUp      = "top_up"
+	LedgerCharge     = "charge"
+	LedgerRefund     = "refund"
+	LedgerAdjustment = "adjustment"
+)
+
+// Accounts of the venue which ledger entries are booked to: top-ups are paid in cash, charges
+// and refunds go to revenue and manual corrections to adjustments. AccountUsers holds the
+// balances of users, which the venue owes them.
+const (
+	AccountCash        = "cash"
+	AccountRevenue     = "revenue"
+	AccountAdjustments = "adjustments"
+	AccountUsers       = "users"
+)
+
+// @Description Posting is one side of a ledger entry: amount (minor currency units) booked to an account, positive
+// @Description amounts credit it. Postings to account users name the user whose balance they change.
+type Posting struct {
+	Account string `json:"account"`
+	UserId  *int   `json:"user_id,omitempty"`
+	Amount  int    `json:"amount"`
+}
+
+// @Description LedgerEntry changes the prepaid balance of a user by amount (minor currency units) and names the
+// @Description account of the venue it is booked to: positive amounts credit the user (top_up, refund, adjustment), negative ones
+// @Description debit it (charge, adjustment). The entry is booked double: its postings credit the user and debit
+// @Description the venue account by the same amount, so they sum to zero. Balance is the balance of the user after
+// @Description the entry. Entries are never changed or deleted, mistakes are corrected with adjustments.
+type LedgerEntry struct {
+	Id          int       `json:"id"`
+	UserId      int       `json:"user_id"`
+	Kind        string    `json:"kind"`
+	Amount      int       `json:"amount"`
+	Account     string    `json:"account"`
+	BookingId   *int      `json:"booking_id,omitempty"`
+	Balance     int       `json:"balance"`
+	Description string    `json:"description"`
+	CreatedBy   *int      `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Postings    []Posting `json:"postings"`
+}
+
+// @Description Balance is the amount (minor currency units) available to a user for bookings
+type Balance struct {
+	UserId  int `json:"user_id"`
+	Balance int `json:"balance"`
+}
+
+// @Description BalanceChange is a top-up (positive amount) or a manual adjustment (any non-zero amount) of a
+// @Description user balance in minor currency units
+type BalanceChange struct {
+	Amount      int    `json:"amount" validate:"required"`
+	Description string `json:"description" validate:"max=200"`
+}
+
+// @Description AccountBalance is the total of the postings to an account, e.g. cash received for top-ups (negative, as
+// @Description it was debited); account users is the sum of all user balances, which the venue owes to its users.
+// @Description The totals of all accounts sum to zero.
+type AccountBalance struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+}
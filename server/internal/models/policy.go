@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description PolicyRule is a business limit checked when bookings are created or changed.
+// @Description Kinds and units of value: min_duration, max_duration, granularity, min_lead_time and
+// @Description max_lead_time in minutes, max_active_bookings and max_concurrent_bookings in bookings,
+// @Description max_weekly_hours in hours.
+// @Description A rule applies to all bookings unless it is scoped to a resource type and/or a user role;
+// @Description disabled rules are kept but not checked.
+type PolicyRule struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name" validate:"required,max=100"`
+	Kind         string    `json:"kind" validate:"required,oneof=min_duration max_duration granularity min_lead_time max_lead_time max_active_bookings max_concurrent_bookings max_weekly_hours"`
+	Value        int       `json:"value" validate:"required,min=1"`
+	ResourceType *string   `json:"resource_type,omitempty" validate:"omitempty,max=30"`
+	Role         *string   `json:"role,omitempty" validate:"omitempty,oneof=member staff admin"`
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description PromoCode takes value percent (kind percent) or value minor currency units (kind fixed) off the price
+// @Description of a booking created with it, after membership hours and discounts. A code can be redeemed between
+// @Description valid_from and valid_until, up to max_redemptions times in total and max_per_user times by a single
+// @Description user; a missing bound is no limit. Without resource_types the code applies to all resources.
+// @Description Codes are matched case-insensitively. Redemptions is the number of bookings which hold the code;
+// @Description cancelling a booking releases its redemption.
+type PromoCode struct {
+	Id             int        `json:"id"`
+	Code           string     `json:"code" validate:"required,max=30,alphanum"`
+	Kind           string     `json:"kind" validate:"required,oneof=percent fixed"`
+	Value          int        `json:"value" validate:"required,min=1"`
+	ResourceTypes  []string   `json:"resource_types,omitempty" validate:"dive,max=30"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxRedemptions *int       `json:"max_redemptions,omitempty" validate:"omitempty,min=1"`
+	MaxPerUser     *int       `json:"max_per_user,omitempty" validate:"omitempty,min=1"`
+	Redemptions    int        `json:"redemptions"`
+	Disabled       bool       `json:"disabled"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// @Description PromoRedemption is a use of a promo code by a booking. Amount is what the code took off its price.
+// @Description booking_status is cancelled for released redemptions and missing once the booking was purged.
+type PromoRedemption struct {
+	Id            int       `json:"id"`
+	PromoCodeId   int       `json:"promo_code_id"`
+	Code          string    `json:"code"`
+	UserId        int       `json:"user_id"`
+	BookingId     *int      `json:"booking_id,omitempty"`
+	BookingStatus string    `json:"booking_status,omitempty"`
+	Amount        int       `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// @Description MembershipPlan includes hours of bookings every calendar month (in the venue time zone), optionally
+// @Description only of some resource types. Included hours cover the priced minutes of bookings from their start,
+// @Description in the order bookings are made; unused hours do not carry over.
+type MembershipPlan struct {
+	Id            int       `json:"id"`
+	Name          string    `json:"name" validate:"required,max=100"`
+	IncludedHours int       `json:"included_hours" validate:"required,min=1"`
+	ResourceTypes []string  `json:"resource_types,omitempty" validate:"dive,max=30"`
+	Disabled      bool      `json:"disabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// @Description Membership subscribes a user to a plan from start_date until end_date inclusive (YYYY-MM-DD), or
+// @Description open-ended. Memberships of a user can not overlap. The usage fields report a single month (period,
+// @Description YYYY-MM): the minutes the plan includes and those used by bookings starting in it.
+type Membership struct {
+	Id              int       `json:"id"`
+	UserId          int       `json:"user_id" validate:"required,min=1"`
+	PlanId          int       `json:"plan_id" validate:"required,min=1"`
+	StartDate       string    `json:"start_date" validate:"required,date"`
+	EndDate         *string   `json:"end_date,omitempty" validate:"omitempty,date"`
+	CreatedAt       time.Time `json:"created_at"`
+	Period          string    `json:"period,omitempty"`
+	IncludedMinutes int       `json:"included_minutes,omitempty"`
+	UsedMinutes     int       `json:"used_minutes,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Relocation modes. In all_or_nothing mode nothing is moved if any booking can not be
+// relocated, in skip_conflicts mode such bookings stay where they are.
+const (
+	RelocationModeAllOrNothing  = "all_or_nothing"
+	RelocationModeSkipConflicts = "skip_conflicts"
+)
+
+// @Description Relocation moves bookings of a resource, e.g. a PC which broke, to compatible resources: resources
+// @Description of the same type in the same zone. Bookings which have not ended by from (now by default) and start
+// @Description before to (if set) are moved to target_id, or to the first compatible resource free at their time
+// @Description if target_id is not set. Prices are kept.
+type Relocation struct {
+	TargetId int       `json:"target_id" validate:"min=0"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Mode     string    `json:"mode" validate:"omitempty,oneof=all_or_nothing skip_conflicts"`
+}
+
+// @Description Move is a booking relocated to another resource
+type Move struct {
+	BookingId      int       `json:"booking_id"`
+	UserId         int       `json:"user_id"`
+	FromResourceId int       `json:"from_resource_id"`
+	ToResourceId   int       `json:"to_resource_id"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+}
+
+// @Description RelocationResult reports the bookings which are moved and those which could not be, with the
+// @Description reason. moved is the number of bookings written to database: none in dry run or when an
+// @Description all_or_nothing relocation failed.
+type RelocationResult struct {
+	ResourceId int        `json:"resource_id"`
+	Mode       string     `json:"mode"`
+	DryRun     bool       `json:"dry_run"`
+	Moves      []Move     `json:"moves"`
+	Conflicts  []Conflict `json:"conflicts"`
+	Moved      int        `json:"moved"`
+}
+
+// @Description Swap exchanges the resources of two bookings, e.g. the seats of two customers. The resources must
+// @Description be compatible and each booking must fit into the resource of the other one.
+type Swap struct {
+	BookingIds []int `json:"booking_ids" validate:"len=2,unique,dive,min=1"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description Resource is a bookable entity (PC, console, room) which contains Id, Name, Type and Zone.
+// @Description Capacity is how many people it holds at the same time, e.g. of a lounge; bookings of a resource
+// @Description may overlap as long as their parties together fit into it. A resource holds a single party by default.
+// @Description BufferBefore and BufferAfter are minutes kept free before and after every booking for setup and
+// @Description cleanup; they block the resource without changing start_time and end_time of bookings.
+// @Description Bookings of a resource which requires approval, e.g. a VIP room, are pending until staff approve them.
+type Resource struct {
+	Id               int       `json:"id"`
+	Name             string    `json:"name" validate:"required,min=2,max=50,excludesall=\\/#@$"`
+	Type             string    `json:"type" validate:"required,max=30"`
+	Zone             string    `json:"zone" validate:"max=30"`
+	Capacity         int       `json:"capacity" validate:"min=0,max=10000"`
+	BufferBefore     int       `json:"buffer_before" validate:"min=0,max=1440"`
+	BufferAfter      int       `json:"buffer_after" validate:"min=0,max=1440"`
+	RequiresApproval bool      `json:"requires_approval"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Statuses of a resource during a time slot
+const (
+	ResourceAvailable   = "available"
+	ResourceBooked      = "booked"
+	ResourceMaintenance = "maintenance"
+)
+
+// @Description ResourceStatus is the availability of a resource during [start, end) after its bookings or maintenance
+// @Description changed: available while there is room for another party, booked when there is not and maintenance while
+// @Description it is blocked. It tells nothing about who booked the resource.
+type ResourceStatus struct {
+	ResourceId int       `json:"resource_id"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Status     string    `json:"status"`
+}
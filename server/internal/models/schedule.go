@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description OpeningHours is a weekly opening interval of the whole venue (no resource_id) or of a single
+// @Description resource, which then ignores venue hours. Weekday is 0 (Sunday) to 6 (Saturday), times are HH:MM;
+// @Description close_time earlier than or equal to open_time means closing after midnight.
+// @Description Without any opening hours the venue is open around the clock.
+type OpeningHours struct {
+	Id         int    `json:"id"`
+	ResourceId *int   `json:"resource_id,omitempty"`
+	Weekday    int    `json:"weekday" validate:"min=0,max=6"`
+	OpenTime   string `json:"open_time" validate:"required,clock"`
+	CloseTime  string `json:"close_time" validate:"required,clock"`
+}
+
+// @Description ScheduleException overrides opening hours on a single date (YYYY-MM-DD) for the venue or one
+// @Description resource: either closed (holiday) or open with special hours.
+type ScheduleException struct {
+	Id         int    `json:"id"`
+	ResourceId *int   `json:"resource_id,omitempty"`
+	Date       string `json:"date" validate:"required,date"`
+	Closed     bool   `json:"closed"`
+	OpenTime   string `json:"open_time,omitempty" validate:"required_without=Closed,omitempty,clock"`
+	CloseTime  string `json:"close_time,omitempty" validate:"required_without=Closed,omitempty,clock"`
+	Reason     string `json:"reason" validate:"max=100"`
+}
+
+// @Description MaintenanceWindow is a period when a resource can not be booked, e.g. because it is under repair
+type MaintenanceWindow struct {
+	Id         int       `json:"id"`
+	ResourceId int       `json:"resource_id" validate:"required,min=1"`
+	StartTime  time.Time `json:"start_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required"`
+	Reason     string    `json:"reason" validate:"max=100"`
+}
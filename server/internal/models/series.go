@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Conflict handling modes for recurring series
+const (
+	SeriesModeAllOrNothing  = "all_or_nothing"
+	SeriesModeSkipConflicts = "skip_conflicts"
+)
+
+// Edit and cancel scopes for an occurrence of a recurring series
+const (
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+)
+
+// @Description BookingSeries is a recurring booking defined by an iCalendar RRULE.
+// @Description StartTime and EndTime describe the first occurrence.
+type BookingSeries struct {
+	Id         int         `json:"id"`
+	UserId     int         `json:"user_id" validate:"required,min=1"`
+	ResourceId int         `json:"resource_id" validate:"required,min=1"`
+	StartTime  time.Time   `json:"start_time" validate:"required"`
+	EndTime    time.Time   `json:"end_time" validate:"required"`
+	RRule      string      `json:"rrule" validate:"required,max=500"`
+	ExDates    []time.Time `json:"exdates"`
+	Text       string      `json:"text" validate:"required,max=100,excludesall=/\\#@$"`
+	Mode       string      `json:"mode,omitempty" validate:"omitempty,oneof=all_or_nothing skip_conflicts"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// @Description Conflict describes an occurrence which overlaps an existing booking (ConflictBookingId)
+// @Description or breaks a schedule or policy rule such as opening hours, maintenance or maximal duration, or is not
+// @Description covered by the balance of the user (Rule and Reason). ResourceId is set for bookings of a group,
+// @Description BookingId for bookings which could not be relocated.
+type Conflict struct {
+	BookingId         int       `json:"booking_id,omitempty"`
+	ResourceId        int       `json:"resource_id,omitempty"`
+	StartTime         time.Time `json:"start_time"`
+	EndTime           time.Time `json:"end_time"`
+	ConflictBookingId int       `json:"conflict_booking_id,omitempty"`
+	Rule              string    `json:"rule,omitempty"`
+	Reason            string    `json:"reason,omitempty"`
+}
+
+// @Description SeriesResult reports bookings created for a series and occurrences skipped due to conflicts
+type SeriesResult struct {
+	SeriesId  int        `json:"series_id"`
+	Bookings  []Booking  `json:"bookings"`
+	Conflicts []Conflict `json:"conflicts"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// @Description Tariff is a price per hour of bookings in minor currency units (e.g. kopecks or cents) during a
+// @Description band of the day, e.g. night hours 22:00-06:00. Times are HH:MM in the venue time zone; end_time
+// @Description earlier than start_time means the band continues after midnight, equal times mean the whole day.
+// @Description days is all, weekdays or weekends. A tariff applies to all resources unless it is scoped to a
+// @Description resource type; where tariffs overlap, the one with the highest priority wins and a scoped tariff
+// @Description wins over a global one of the same priority. Disabled tariffs are kept but not applied.
+type Tariff struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name" validate:"required,max=100"`
+	ResourceType *string   `json:"resource_type,omitempty" validate:"omitempty,max=30"`
+	Days         string    `json:"days" validate:"required,oneof=all weekdays weekends"`
+	StartTime    string    `json:"start_time" validate:"required,clock"`
+	EndTime      string    `json:"end_time" validate:"required,clock"`
+	PricePerHour int       `json:"price_per_hour" validate:"min=0"`
+	Priority     int       `json:"priority"`
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// @Description Discount lowers the price of bookings by a percentage, e.g. for members or staff. It applies to
+// @Description all bookings unless it is scoped to a user role and/or a resource type. Discounts do not stack:
+// @Description a booking gets the largest one which applies.
+type Discount struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name" validate:"required,max=100"`
+	Percent      int       `json:"percent" validate:"required,min=1,max=100"`
+	Role         *string   `json:"role,omitempty" validate:"omitempty,oneof=member staff admin"`
+	ResourceType *string   `json:"resource_type,omitempty" validate:"omitempty,max=30"`
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// @Description QuoteRequest describes a booking to be priced without creating it. Without user_id no role
+// @Description discounts and memberships are applied. A promo code is checked but not redeemed.
+type QuoteRequest struct {
+	UserId     int       `json:"user_id" validate:"min=0"`
+	ResourceId int       `json:"resource_id" validate:"required,min=1"`
+	StartTime  time.Time `json:"start_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required"`
+	PromoCode  string    `json:"promo_code,omitempty" validate:"max=30"`
+}
@@ -6,11 +6,21 @@ import (
 	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
 )
 
-// @Description User is a struct which contains Id, Username, Password, CreatedAt and UpdatedAt
+// User roles. Staff manage waitlists and maintenance, admins manage everything.
+const (
+	RoleMember = "member"
+	RoleStaff  = "staff"
+	RoleAdmin  = "admin"
+)
+
+// @Description User is a struct which contains Id, Username, Password, CreatedAt and UpdatedAt.
+// @Description Priority members designated by staff are served first from waitlists.
 type User struct {
 	Id        int       `json:"id"`
 	Username  string    `json:"username" validate:"required,min=6,max=20,excludesall=\\/#@$"`
-	Password  string    `json:"password" validate:"required,min=6,max=20"`
+	Password  string    `json:"password,omitempty" validate:"required,min=6,max=20"`
+	Role      string    `json:"role"`
+	Priority  bool      `json:"priority"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
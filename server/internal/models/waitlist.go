@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Waitlist entry statuses
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistFulfilled = "fulfilled"
+	WaitlistExpired   = "expired"
+	WaitlistCancelled = "cancelled"
+)
+
+// @Description WaitlistEntry is a request for a resource and time window which is currently taken.
+// @Description When the window frees up the entry is offered: a held booking is created (BookingId)
+// @Description which the user must confirm before HoldExpiresAt.
+type WaitlistEntry struct {
+	Id            int        `json:"id"`
+	UserId        int        `json:"user_id" validate:"required,min=1"`
+	ResourceId    int        `json:"resource_id" validate:"required,min=1"`
+	StartTime     time.Time  `json:"start_time" validate:"required"`
+	EndTime       time.Time  `json:"end_time" validate:"required"`
+	PartySize     int        `json:"party_size" validate:"min=0"`
+	Text          string     `json:"text" validate:"required,max=100,excludesall=/\\#@$"`
+	Status        string     `json:"status"`
+	Priority      bool       `json:"priority"`
+	BookingId     *int       `json:"booking_id,omitempty"`
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	_ "github.com/alexey-dobry/booking-service/server/internal/validator"
+)
+
+// Webhook delivery statuses. Deliveries which failed webhook.MaxAttempts times are dead
+// and are only retried on request.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// @Description Webhook is a subscription of an external url to events. Events are posted as json signed
+// @Description with the secret, see README. Secret is generated when empty and only returned on creation.
+type Webhook struct {
+	Id        int       `json:"id"`
+	Url       string    `json:"url" validate:"required,url,max=500"`
+	Events    []string  `json:"events" validate:"required,min=1,dive,oneof=* booking.created booking.updated booking.cancelled user.created user.updated user.deleted maintenance.created maintenance.updated maintenance.deleted"`
+	Secret    string    `json:"secret,omitempty" validate:"omitempty,min=16,max=100"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// @Description WebhookDelivery is an event queued for a webhook together with the outcome of its last attempt
+type WebhookDelivery struct {
+	Id            int64           `json:"id"`
+	WebhookId     int             `json:"webhook_id"`
+	EventId       string          `json:"event_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	ResponseCode  *int            `json:"response_code,omitempty"`
+	LastError     *string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
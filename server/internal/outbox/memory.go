@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// BrokerMessage is a message delivered by MemoryBroker
+type BrokerMessage struct {
+	Subject string
+	Headers map[string]string
+	Data    []byte
+}
+
+// MemoryBroker is an in-process Broker standing in for NATS or Kafka. Subscribers receive
+// messages whose subject starts with their prefix; Publish blocks while a subscriber's
+// buffer is full, so nothing is dropped.
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[chan BrokerMessage]string
+}
+
+// NewMemoryBroker returns a broker without subscribers
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: make(map[chan BrokerMessage]string)}
+}
+
+// Subscribe returns messages published under subjects starting with prefix and a function
+// which ends the subscription
+func (b *MemoryBroker) Subscribe(prefix string, buffer int) (<-chan BrokerMessage, func()) {
+	ch := make(chan BrokerMessage, buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = prefix
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, subject string, headers map[string]string, data []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch, prefix := range b.subs {
+		if !strings.HasPrefix(subject, prefix) {
+			continue
+		}
+		select {
+		case ch <- BrokerMessage{Subject: subject, Headers: headers, Data: data}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
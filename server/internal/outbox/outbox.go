@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types
+const (
+	BookingCreated   = "booking.created"
+	BookingUpdated   = "booking.updated"
+	BookingCancelled = "booking.cancelled"
+	UserCreated      = "user.created"
+	UserUpdated      = "user.updated"
+	UserDeleted      = "user.deleted"
+
+	MaintenanceCreated = "maintenance.created"
+	MaintenanceUpdated = "maintenance.updated"
+	MaintenanceDeleted = "maintenance.deleted"
+)
+
+// Channel is the PostgreSQL notification channel announcing positions of new outbox events
+const Channel = "outbox"
+
+// Sink names
+const (
+	SinkWebhooks = "webhooks"
+	SinkLog      = "log"
+	SinkBroker   = "broker"
+)
+
+// Message is the json form of an event handed to sinks
+type Message struct {
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Event is a row of the outbox. Sinks receive events in the order of the transactions which
+// wrote them and then of Position, the order they were written within a transaction.
+type Event struct {
+	Position int64
+	Id       string
+	Type     string
+	Payload  []byte
+}
+
+// NewPayload renders the message of event id of eventType with data
+func NewPayload(id, eventType string, data any) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Message{Id: id, Type: eventType, CreatedAt: time.Now().UTC(), Data: raw})
+}
+
+// Sink receives events from the outbox relay. An event is handed to every sink until all of
+// them accept it, so sinks must tolerate duplicates (consumers deduplicate by event id).
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, e Event) error
+}
+
+// LogSink writes events with log, e.g. the debug log of the server
+type LogSink struct {
+	Log func(string)
+}
+
+func (s LogSink) Name() string {
+	return SinkLog
+}
+
+func (s LogSink) Publish(ctx context.Context, e Event) error {
+	s.Log(fmt.Sprintf("Event %s {%s}: %s", e.Type, e.Id, e.Payload))
+	return nil
+}
+
+// Broker is a message broker with NATS/Kafka-like subjects. Headers carry event
+// id and type, so consumers do not have to parse payloads to route or deduplicate.
+type Broker interface {
+	Publish(ctx context.Context, subject string, headers map[string]string, data []byte) error
+}
+
+// BrokerSink publishes events to Broker under Prefix + event type, e.g. "booking-service.booking.created"
+type BrokerSink struct {
+	Broker Broker
+	Prefix string
+}
+
+func (s BrokerSink) Name() string {
+	return SinkBroker
+}
+
+func (s BrokerSink) Publish(ctx context.Context, e Event) error {
+	headers := map[string]string{"Event-Id": e.Id, "Event-Type": e.Type}
+	return s.Broker.Publish(ctx, s.Prefix+e.Type, headers, e.Payload)
+}
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+)
+
+// Kinds of rules. Value of time rules is in minutes.
+const (
+	KindMinDuration       = "min_duration"
+	KindMaxDuration       = "max_duration"
+	KindGranularity       = "granularity"
+	KindMinLeadTime       = "min_lead_time"
+	KindMaxLeadTime       = "max_lead_time"
+	KindMaxActiveBookings = "max_active_bookings"
+	KindMaxWeeklyHours    = "max_weekly_hours"
+	KindMaxConcurrent     = "max_concurrent_bookings"
+)
+
+// Rule is a single business limit which applies to a booking
+type Rule struct {
+	Id    int
+	Name  string
+	Kind  string
+	Value int
+}
+
+// Request describes a booking being created or changed together with the
+// usage of the user who makes it. Bookings being changed must not be counted in
+// ActiveBookings and Booked.
+type Request struct {
+	Start time.Time
+	End   time.Time
+	Now   time.Time
+	// ActiveBookings is the number of bookings of the user which have not ended yet
+	ActiveBookings int
+	// Booked are other bookings of the user within Weeks(Start, End), so they include all
+	// bookings overlapping [Start, End)
+	Booked   []schedule.Interval
+	Location *time.Location
+}
+
+// Violation names a rule which forbids the booking
+type Violation struct {
+	RuleId  int    `json:"rule_id"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s (%s): %s", v.Kind, v.Name, v.Message)
+}
+
+func (r Request) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// weekStart returns midnight of Monday of the week t belongs to
+func weekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+}
+
+// Weeks returns the period of whole weeks (Monday to Sunday) which [start, end) touches
+func Weeks(start, end time.Time, loc *time.Location) (time.Time, time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return weekStart(start, loc), weekStart(end.Add(-time.Nanosecond), loc).AddDate(0, 0, 7)
+}
+
+// formatMinutes prints m in the largest unit which divides it
+func formatMinutes(m int) string {
+	switch {
+	case m%(24*60) == 0:
+		return fmt.Sprintf("%d days", m/(24*60))
+	case m%60 == 0:
+		return fmt.Sprintf("%d hours", m/60)
+	default:
+		return fmt.Sprintf("%d minutes", m)
+	}
+}
+
+// Evaluate checks req against every rule and returns all violations
+func Evaluate(rules []Rule, req Request) []Violation {
+	var violations []Violation
+
+	for _, rule := range rules {
+		if msg := check(rule, req); msg != "" {
+			violations = append(violations, Violation{RuleId: rule.Id, Name: rule.Name, Kind: rule.Kind, Message: msg})
+		}
+	}
+	return violations
+}
+
+// check returns a description of the violation of rule or an empty string
+func check(rule Rule, req Request) string {
+	limit := time.Duration(rule.Value) * time.Minute
+	duration := req.End.Sub(req.Start)
+	lead := req.Start.Sub(req.Now)
+
+	switch rule.Kind {
+	case KindMinDuration:
+		if duration < limit {
+			return fmt.Sprintf("booking must last at least %s", formatMinutes(rule.Value))
+		}
+	case KindMaxDuration:
+		if duration > limit {
+			return fmt.Sprintf("booking must not last longer than %s", formatMinutes(rule.Value))
+		}
+	case KindGranularity:
+		if !aligned(req.Start, limit, req.location()) || !aligned(req.End, limit, req.location()) {
+			return fmt.Sprintf("booking must start and end on %s boundaries", formatMinutes(rule.Value))
+		}
+	case KindMinLeadTime:
+		if lead < limit {
+			return fmt.Sprintf("booking must be made at least %s in advance", formatMinutes(rule.Value))
+		}
+	case KindMaxLeadTime:
+		if lead > limit {
+			return fmt.Sprintf("booking can not be made more than %s in advance", formatMinutes(rule.Value))
+		}
+	case KindMaxActiveBookings:
+		if req.ActiveBookings+1 > rule.Value {
+			return fmt.Sprintf("user can not have more than %d upcoming or ongoing bookings", rule.Value)
+		}
+	case KindMaxConcurrent:
+		if concurrent := overlapping(req) + 1; concurrent > rule.Value {
+			return fmt.Sprintf("user can not have more than %d bookings at the same time, %d would overlap", rule.Value, concurrent)
+		}
+	case KindMaxWeeklyHours:
+		limit = time.Duration(rule.Value) * time.Hour
+		if week, booked := busiestWeek(req); booked > limit {
+			return fmt.Sprintf("user can not book more than %d hours per week, week of %s would have %s", rule.Value, week.Format(time.DateOnly), booked)
+		}
+	}
+	return ""
+}
+
+// aligned reports whether t is a multiple of step since midnight
+func aligned(t time.Time, step time.Duration, loc *time.Location) bool {
+	t = t.In(loc)
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+	return sinceMidnight%step == 0
+}
+
+// overlapping returns the number of other bookings of the user which overlap the requested one
+func overlapping(req Request) int {
+	n := 0
+	for _, in := range req.Booked {
+		if in.Start.Before(req.End) && in.End.After(req.Start) {
+			n++
+		}
+	}
+	return n
+}
+
+// busiestWeek returns the week touched by the requested booking with the largest
+// booked time, including the requested booking itself
+func busiestWeek(req Request) (time.Time, time.Duration) {
+	loc := req.location()
+	booked := append([]schedule.Interval{{Start: req.Start, End: req.End}}, req.Booked...)
+
+	from, to := Weeks(req.Start, req.End, loc)
+
+	var busiest time.Time
+	var max time.Duration
+	for week := from; week.Before(to); week = week.AddDate(0, 0, 7) {
+		var total time.Duration
+		for _, in := range schedule.Clip(booked, week, week.AddDate(0, 0, 7)) {
+			total += in.End.Sub(in.Start)
+		}
+		if total > max {
+			busiest, max = week, total
+		}
+	}
+	return busiest, max
+}
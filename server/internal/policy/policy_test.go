@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+)
+
+// 2025-03-03 is a Monday
+var now = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func at(day, hh, mm int) time.Time {
+	return time.Date(2025, 3, day, hh, mm, 0, 0, time.UTC)
+}
+
+func kinds(violations []Violation) []string {
+	var k []string
+	for _, v := range violations {
+		k = append(k, v.Kind)
+	}
+	return k
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		req  Request
+		fail bool
+	}{
+		{"min duration met", Rule{Kind: KindMinDuration, Value: 60}, Request{Start: at(3, 10, 0), End: at(3, 11, 0), Now: now}, false},
+		{"too short", Rule{Kind: KindMinDuration, Value: 60}, Request{Start: at(3, 10, 0), End: at(3, 10, 30), Now: now}, true},
+		{"max duration met", Rule{Kind: KindMaxDuration, Value: 120}, Request{Start: at(3, 10, 0), End: at(3, 12, 0), Now: now}, false},
+		{"too long", Rule{Kind: KindMaxDuration, Value: 120}, Request{Start: at(3, 10, 0), End: at(3, 12, 30), Now: now}, true},
+		{"aligned", Rule{Kind: KindGranularity, Value: 30}, Request{Start: at(3, 10, 30), End: at(3, 12, 0), Now: now}, false},
+		{"not aligned", Rule{Kind: KindGranularity, Value: 30}, Request{Start: at(3, 10, 15), End: at(3, 12, 0), Now: now}, true},
+		{"enough lead time", Rule{Kind: KindMinLeadTime, Value: 60}, Request{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Now: now}, false},
+		{"too late", Rule{Kind: KindMinLeadTime, Value: 60}, Request{Start: now.Add(30 * time.Minute), End: now.Add(2 * time.Hour), Now: now}, true},
+		{"too early", Rule{Kind: KindMaxLeadTime, Value: 24 * 60}, Request{Start: now.Add(25 * time.Hour), End: now.Add(26 * time.Hour), Now: now}, true},
+		{"active bookings below limit", Rule{Kind: KindMaxActiveBookings, Value: 2}, Request{Start: at(3, 10, 0), End: at(3, 11, 0), Now: now, ActiveBookings: 1}, false},
+		{"active bookings at limit", Rule{Kind: KindMaxActiveBookings, Value: 2}, Request{Start: at(3, 10, 0), End: at(3, 11, 0), Now: now, ActiveBookings: 2}, true},
+		{"unknown kind", Rule{Kind: "unknown", Value: 1}, Request{Start: at(3, 10, 0), End: at(3, 11, 0), Now: now}, false},
+	}
+
+	for _, tt := range tests {
+		violations := Evaluate([]Rule{tt.rule}, tt.req)
+		if got := len(violations) > 0; got != tt.fail {
+			t.Errorf("%s: Evaluate = %v, want violation: %v", tt.name, violations, tt.fail)
+		}
+	}
+}
+
+func TestEvaluateReportsEveryRule(t *testing.T) {
+	rules := []Rule{
+		{Id: 1, Name: "Short", Kind: KindMinDuration, Value: 60},
+		{Id: 2, Name: "Half hours", Kind: KindGranularity, Value: 30},
+		{Id: 3, Name: "Long", Kind: KindMaxDuration, Value: 240},
+	}
+	violations := Evaluate(rules, Request{Start: at(3, 10, 10), End: at(3, 10, 40), Now: now})
+
+	if got := kinds(violations); len(got) != 2 || got[0] != KindMinDuration || got[1] != KindGranularity {
+		t.Fatalf("Evaluate = %v, want min_duration and granularity", got)
+	}
+	if violations[0].RuleId != 1 || violations[0].Name != "Short" {
+		t.Errorf("violation = %+v, want rule 1 named Short", violations[0])
+	}
+}
+
+func TestEvaluateMaxConcurrent(t *testing.T) {
+	rule := Rule{Kind: KindMaxConcurrent, Value: 2}
+	booked := []schedule.Interval{
+		{Start: at(3, 9, 0), End: at(3, 11, 0)},
+		// ends when the requested booking starts, so it does not overlap
+		{Start: at(3, 8, 0), End: at(3, 10, 0)},
+	}
+
+	req := Request{Start: at(3, 10, 0), End: at(3, 12, 0), Now: now, Booked: booked}
+	if violations := Evaluate([]Rule{rule}, req); len(violations) > 0 {
+		t.Errorf("one overlapping booking returned %v", violations)
+	}
+
+	req.Booked = append(req.Booked, schedule.Interval{Start: at(3, 11, 30), End: at(3, 13, 0)})
+	if violations := Evaluate([]Rule{rule}, req); len(violations) != 1 {
+		t.Errorf("two overlapping bookings returned %v, want a violation", violations)
+	}
+}
+
+func TestEvaluateMaxWeeklyHours(t *testing.T) {
+	rule := Rule{Kind: KindMaxWeeklyHours, Value: 4}
+	// three hours on Monday of the same week, one in the week before
+	booked := []schedule.Interval{
+		{Start: at(3, 10, 0), End: at(3, 13, 0)},
+		{Start: at(2, 10, 0), End: at(2, 11, 0)},
+	}
+
+	req := Request{Start: at(5, 10, 0), End: at(5, 11, 0), Now: now, Booked: booked}
+	if violations := Evaluate([]Rule{rule}, req); len(violations) > 0 {
+		t.Errorf("four hours in a week returned %v", violations)
+	}
+
+	req.End = at(5, 11, 30)
+	if violations := Evaluate([]Rule{rule}, req); len(violations) != 1 {
+		t.Errorf("four and a half hours in a week returned %v, want a violation", violations)
+	}
+}
+
+func TestWeeks(t *testing.T) {
+	// Sunday evening to Monday morning touches two weeks
+	from, to := Weeks(at(9, 22, 0), at(10, 2, 0), time.UTC)
+	if !from.Equal(at(3, 0, 0)) || !to.Equal(at(17, 0, 0)) {
+		t.Errorf("Weeks = %s - %s, want 2025-03-03 - 2025-03-17", from, to)
+	}
+
+	// a booking ending at midnight does not touch the next week
+	from, to = Weeks(at(9, 22, 0), at(10, 0, 0), time.UTC)
+	if !from.Equal(at(3, 0, 0)) || !to.Equal(at(10, 0, 0)) {
+		t.Errorf("Weeks = %s - %s, want 2025-03-03 - 2025-03-10", from, to)
+	}
+}
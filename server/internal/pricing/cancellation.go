@@ -0,0 +1,37 @@
+package pricing
+
+import "time"
+
+// CancellationRule charges FeePercent of the price of bookings cancelled less than HoursBefore
+// hours before they start
+type CancellationRule struct {
+	Id          int
+	Name        string
+	HoursBefore int
+	FeePercent  int
+}
+
+// AfterStart is the rule of bookings cancelled after they started which no rule charges their full
+// price: started bookings are never refunded
+var AfterStart = CancellationRule{Name: "no refund after the start", FeePercent: 100}
+
+// CancellationFee returns the fee of cancelling a booking of price which starts at start at the
+// moment now, and the rule which sets it or nil if cancelling is free. Rules are tried in order,
+// the first one whose window now falls into applies, so callers pass the narrowest windows first.
+// Bookings which have started are charged their full price, by AfterStart if no rule does.
+func CancellationFee(rules []CancellationRule, price int, start, now time.Time) (int, *CancellationRule) {
+	lead := start.Sub(now)
+
+	for i, rule := range rules {
+		if lead < time.Duration(rule.HoursBefore)*time.Hour {
+			if lead > 0 || rule.FeePercent >= 100 {
+				return (price*rule.FeePercent + 50) / 100, &rules[i]
+			}
+			break
+		}
+	}
+	if lead <= 0 {
+		return price, &AfterStart
+	}
+	return 0, nil
+}
@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationFee(t *testing.T) {
+	start := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	rules := []CancellationRule{
+		{Id: 1, Name: "same day", HoursBefore: 2, FeePercent: 50},
+		{Id: 2, Name: "day before", HoursBefore: 24, FeePercent: 25},
+	}
+
+	tests := []struct {
+		name   string
+		rules  []CancellationRule
+		before time.Duration
+		fee    int
+		rule   int // -1 for AfterStart, 0 for free cancellation
+	}{
+		{"free", rules, 48 * time.Hour, 0, 0},
+		{"wide window", rules, 5 * time.Hour, 250, 2},
+		{"narrowest window first", rules, time.Hour, 500, 1},
+		{"at the start", rules, 0, 1000, -1},
+		{"after the start", rules, -time.Hour, 1000, -1},
+		{"after the start without rules", nil, -time.Hour, 1000, -1},
+		{"rule without a window", []CancellationRule{{Id: 3, Name: "none", HoursBefore: 0, FeePercent: 50}}, time.Minute, 0, 0},
+		{"after the start with a rule of the full price", []CancellationRule{{Id: 4, Name: "late", HoursBefore: 1, FeePercent: 100}}, -time.Hour, 1000, 4},
+	}
+
+	for _, tt := range tests {
+		fee, rule := CancellationFee(tt.rules, 1000, start, start.Add(-tt.before))
+		if fee != tt.fee {
+			t.Errorf("%s: fee = %d, want %d", tt.name, fee, tt.fee)
+		}
+		switch {
+		case tt.rule == 0 && rule != nil:
+			t.Errorf("%s: rule = %+v, want free cancellation", tt.name, rule)
+		case tt.rule == -1 && rule != &AfterStart:
+			t.Errorf("%s: rule = %+v, want AfterStart", tt.name, rule)
+		case tt.rule > 0 && (rule == nil || rule.Id != tt.rule):
+			t.Errorf("%s: rule = %+v, want rule %d", tt.name, rule, tt.rule)
+		}
+	}
+}
+
+func TestCancellationFeeRounding(t *testing.T) {
+	start := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	rules := []CancellationRule{{Id: 1, Name: "late", HoursBefore: 24, FeePercent: 33}}
+
+	// 33% of 1250 is 412.5
+	if fee, _ := CancellationFee(rules, 1250, start, start.Add(-time.Hour)); fee != 413 {
+		t.Errorf("fee = %d, want 413", fee)
+	}
+}
@@ -0,0 +1,271 @@
+package pricing
+
+import (
+	"sort"
+	"time"
+)
+
+// Days a tariff applies on. Weekend days are Saturday and Sunday in the venue time zone.
+const (
+	DaysAll      = "all"
+	DaysWeekdays = "weekdays"
+	DaysWeekends = "weekends"
+)
+
+// Tariff is a price per hour for a band of the day. End earlier than Start means the band
+// continues after midnight; End equal to Start means the whole day.
+type Tariff struct {
+	Id           int
+	Name         string
+	Days         string
+	Start        int // minutes since midnight
+	End          int // minutes since midnight
+	PricePerHour int
+}
+
+// Discount lowers the price of a whole booking by a percentage
+type Discount struct {
+	Id      int
+	Name    string
+	Percent int
+}
+
+// Kinds of promo codes
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// Membership covers up to RemainingMinutes of a booking out of the hours included in a plan
+// for Period
+type Membership struct {
+	Id               int
+	Name             string
+	Period           string
+	RemainingMinutes int
+}
+
+// Promo is a promo code taking Value percent (PromoPercent) or Value minor units (PromoFixed)
+// off the price
+type Promo struct {
+	Id    int
+	Code  string
+	Kind  string
+	Value int
+}
+
+// Request is a booking to be priced, optionally with a membership of its user and a promo code
+type Request struct {
+	Start      time.Time
+	End        time.Time
+	Location   *time.Location
+	Membership *Membership
+	Promo      *Promo
+}
+
+// Line is the part of a booking charged by a single tariff
+type Line struct {
+	TariffId     int       `json:"tariff_id"`
+	Name         string    `json:"name"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Minutes      int       `json:"minutes"`
+	PricePerHour int       `json:"price_per_hour"`
+	Amount       int       `json:"amount"`
+}
+
+// AppliedDiscount is a discount subtracted from the subtotal
+type AppliedDiscount struct {
+	DiscountId int    `json:"discount_id"`
+	Name       string `json:"name"`
+	Percent    int    `json:"percent"`
+	Amount     int    `json:"amount"`
+}
+
+// AppliedMembership is the part of the subtotal covered by hours included in a membership
+type AppliedMembership struct {
+	MembershipId int    `json:"membership_id"`
+	Name         string `json:"name"`
+	Period       string `json:"period"`
+	Minutes      int    `json:"minutes"`
+	Amount       int    `json:"amount"`
+}
+
+// AppliedPromo is a promo code subtracted after discounts
+type AppliedPromo struct {
+	PromoCodeId int    `json:"promo_code_id"`
+	Code        string `json:"code"`
+	Kind        string `json:"kind"`
+	Value       int    `json:"value"`
+	Amount      int    `json:"amount"`
+}
+
+// @Description Quote is the price of a booking in minor currency units (e.g. kopecks or cents) with its
+// @Description breakdown: a line per tariff band the booking touches, the hours covered by a membership,
+// @Description the applied discounts, the promo code and the total. Time not covered by any tariff is free
+// @Description and reported as unpriced_minutes.
+type Quote struct {
+	Lines           []Line             `json:"lines"`
+	Subtotal        int                `json:"subtotal"`
+	Membership      *AppliedMembership `json:"membership,omitempty"`
+	Discounts       []AppliedDiscount  `json:"discounts,omitempty"`
+	Promo           *AppliedPromo      `json:"promo,omitempty"`
+	Total           int                `json:"total"`
+	UnpricedMinutes int                `json:"unpriced_minutes,omitempty"`
+}
+
+func (r Request) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// covers reports whether t applies at minute of day m of a day with weekday
+func (t Tariff) covers(weekday time.Weekday, m int) bool {
+	weekend := weekday == time.Saturday || weekday == time.Sunday
+	if t.Days == DaysWeekdays && weekend || t.Days == DaysWeekends && !weekend {
+		return false
+	}
+
+	switch {
+	case t.Start < t.End:
+		return m >= t.Start && m < t.End
+	case t.Start > t.End:
+		return m >= t.Start || m < t.End
+	default:
+		return true
+	}
+}
+
+// at returns the instant minute m of day, which time.Date shifts across DST gaps
+func at(day time.Time, m int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, m, 0, 0, day.Location())
+}
+
+// amount returns the price of d at pricePerHour, rounded to the nearest minor unit
+func amount(pricePerHour int, d time.Duration) int {
+	return int((int64(pricePerHour)*int64(d/time.Second) + 1800) / 3600)
+}
+
+// Price splits the booking into bands of tariffs and prices every band. Tariffs are tried in
+// order, the first one covering a moment applies to it, so callers pass them by precedence.
+// Days and bands are matched against the calendar day of every moment, so a night band of a
+// Friday turns into a weekend one at midnight. A membership covers priced minutes from the
+// start of the booking, then the largest of the discounts and the promo code are taken off
+// what remains.
+func Price(tariffs []Tariff, discounts []Discount, req Request) Quote {
+	quote := Quote{Lines: []Line{}}
+	loc := req.location()
+
+	start := req.Start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	var unpriced time.Duration
+
+	for ; day.Before(req.End); day = day.AddDate(0, 0, 1) {
+		from, to := day, day.AddDate(0, 0, 1)
+		if from.Before(req.Start) {
+			from = req.Start
+		}
+		if to.After(req.End) {
+			to = req.End
+		}
+		if !from.Before(to) {
+			continue
+		}
+
+		// every band boundary within the day splits the booking
+		cuts := []time.Time{from, to}
+		for _, t := range tariffs {
+			for _, m := range []int{t.Start, t.End} {
+				if cut := at(day, m); cut.After(from) && cut.Before(to) {
+					cuts = append(cuts, cut)
+				}
+			}
+		}
+		sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+		for i := 0; i+1 < len(cuts); i++ {
+			a, b := cuts[i], cuts[i+1]
+			if !a.Before(b) {
+				continue
+			}
+
+			local := a.In(loc)
+			minute := local.Hour()*60 + local.Minute()
+
+			tariff, ok := Tariff{}, false
+			for _, t := range tariffs {
+				if t.covers(local.Weekday(), minute) {
+					tariff, ok = t, true
+					break
+				}
+			}
+			if !ok {
+				unpriced += b.Sub(a)
+				continue
+			}
+
+			if n := len(quote.Lines); n > 0 && quote.Lines[n-1].TariffId == tariff.Id && quote.Lines[n-1].EndTime.Equal(a) {
+				quote.Lines[n-1].EndTime = b.In(loc)
+				continue
+			}
+			quote.Lines = append(quote.Lines, Line{TariffId: tariff.Id, Name: tariff.Name, StartTime: local, EndTime: b.In(loc), PricePerHour: tariff.PricePerHour})
+		}
+	}
+
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		d := line.EndTime.Sub(line.StartTime)
+		line.Minutes = int(d / time.Minute)
+		line.Amount = amount(line.PricePerHour, d)
+		quote.Subtotal += line.Amount
+	}
+	quote.UnpricedMinutes = int(unpriced / time.Minute)
+
+	quote.Total = quote.Subtotal
+	if m := req.Membership; m != nil && m.RemainingMinutes > 0 {
+		applied := AppliedMembership{MembershipId: m.Id, Name: m.Name, Period: m.Period}
+		for _, line := range quote.Lines {
+			minutes := min(line.Minutes, m.RemainingMinutes-applied.Minutes)
+			if minutes <= 0 {
+				break
+			}
+			applied.Minutes += minutes
+			applied.Amount += amount(line.PricePerHour, time.Duration(minutes)*time.Minute)
+		}
+		if applied.Minutes > 0 {
+			applied.Amount = min(applied.Amount, quote.Total)
+			quote.Membership = &applied
+			quote.Total -= applied.Amount
+		}
+	}
+
+	if best := bestDiscount(discounts); best != nil && quote.Total > 0 {
+		off := (quote.Total*best.Percent + 50) / 100
+		quote.Discounts = []AppliedDiscount{{DiscountId: best.Id, Name: best.Name, Percent: best.Percent, Amount: off}}
+		quote.Total -= off
+	}
+
+	if p := req.Promo; p != nil {
+		off := p.Value
+		if p.Kind == PromoPercent {
+			off = (quote.Total*p.Value + 50) / 100
+		}
+		off = min(off, quote.Total)
+		quote.Promo = &AppliedPromo{PromoCodeId: p.Id, Code: p.Code, Kind: p.Kind, Value: p.Value, Amount: off}
+		quote.Total -= off
+	}
+	return quote
+}
+
+// bestDiscount returns the discount with the largest percentage, the first one of equal ones
+func bestDiscount(discounts []Discount) *Discount {
+	var best *Discount
+	for i := range discounts {
+		if best == nil || discounts[i].Percent > best.Percent {
+			best = &discounts[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,133 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// date returns 2025-03-<day> hh:mm in loc; 2025-03-03 is a Monday
+func date(loc *time.Location, day, hh, mm int) time.Time {
+	return time.Date(2025, 3, day, hh, mm, 0, 0, loc)
+}
+
+var tariffs = []Tariff{
+	{Id: 1, Name: "Weekend", Days: DaysWeekends, PricePerHour: 2000},
+	{Id: 2, Name: "Day", Days: DaysWeekdays, Start: 8 * 60, End: 18 * 60, PricePerHour: 1000},
+	// the evening band continues after midnight
+	{Id: 3, Name: "Evening", Days: DaysWeekdays, Start: 18 * 60, End: 8 * 60, PricePerHour: 1500},
+}
+
+func tariffIds(lines []Line) []int {
+	var ids []int
+	for _, line := range lines {
+		ids = append(ids, line.TariffId)
+	}
+	return ids
+}
+
+func TestPrice(t *testing.T) {
+	loc := time.UTC
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		lines      []int
+		subtotal   int
+	}{
+		{"single band", date(loc, 3, 10, 0), date(loc, 3, 12, 0), []int{2}, 2000},
+		{"across bands", date(loc, 3, 17, 0), date(loc, 3, 19, 0), []int{2, 3}, 2500},
+		{"evening past midnight", date(loc, 3, 23, 0), date(loc, 4, 9, 0), []int{3, 2}, 1500*9 + 1000},
+		{"friday night into the weekend", date(loc, 7, 23, 0), date(loc, 8, 1, 0), []int{3, 1}, 3500},
+		{"rounded to minor units", date(loc, 3, 10, 0), date(loc, 3, 10, 20), []int{2}, 333},
+	}
+
+	for _, tt := range tests {
+		quote := Price(tariffs, nil, Request{Start: tt.start, End: tt.end, Location: loc})
+		if ids := tariffIds(quote.Lines); len(ids) != len(tt.lines) {
+			t.Errorf("%s: lines of tariffs %v, want %v", tt.name, ids, tt.lines)
+		} else {
+			for i := range ids {
+				if ids[i] != tt.lines[i] {
+					t.Errorf("%s: lines of tariffs %v, want %v", tt.name, ids, tt.lines)
+					break
+				}
+			}
+		}
+		if quote.Subtotal != tt.subtotal || quote.Total != tt.subtotal {
+			t.Errorf("%s: subtotal %d, total %d, want %d", tt.name, quote.Subtotal, quote.Total, tt.subtotal)
+		}
+	}
+}
+
+func TestPriceUnpriced(t *testing.T) {
+	loc := time.UTC
+	day := []Tariff{{Id: 1, Name: "Day", Days: DaysAll, Start: 9 * 60, End: 17 * 60, PricePerHour: 1000}}
+
+	quote := Price(day, nil, Request{Start: date(loc, 3, 16, 0), End: date(loc, 3, 18, 0), Location: loc})
+	if quote.Total != 1000 || quote.UnpricedMinutes != 60 {
+		t.Errorf("Price = total %d with %d unpriced minutes, want 1000 with 60", quote.Total, quote.UnpricedMinutes)
+	}
+}
+
+func TestPriceDaylightSavingChange(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// clocks go forward at 02:00 on Sunday 2025-03-30, so 01:00 - 04:00 lasts two hours
+	quote := Price(tariffs, nil, Request{Start: date(loc, 30, 1, 0), End: date(loc, 30, 4, 0), Location: loc})
+	if quote.Total != 4000 || len(quote.Lines) != 1 || quote.Lines[0].Minutes != 120 {
+		t.Errorf("Price = %+v, want two hours of the weekend tariff", quote)
+	}
+}
+
+func TestPriceReductions(t *testing.T) {
+	loc := time.UTC
+	req := Request{Start: date(loc, 3, 10, 0), End: date(loc, 3, 13, 0), Location: loc}
+	discounts := []Discount{{Id: 1, Name: "Students", Percent: 10}, {Id: 2, Name: "Staff", Percent: 20}}
+
+	// 3000 minus an hour of the membership, 20% and then 50% off
+	req.Membership = &Membership{Id: 1, Name: "Basic", Period: "2025-03", RemainingMinutes: 60}
+	req.Promo = &Promo{Id: 1, Code: "HALF", Kind: PromoPercent, Value: 50}
+	quote := Price(tariffs, discounts, req)
+
+	if quote.Subtotal != 3000 {
+		t.Errorf("subtotal = %d, want 3000", quote.Subtotal)
+	}
+	if m := quote.Membership; m == nil || m.Minutes != 60 || m.Amount != 1000 {
+		t.Errorf("membership = %+v, want 60 minutes worth 1000", m)
+	}
+	if len(quote.Discounts) != 1 || quote.Discounts[0].DiscountId != 2 || quote.Discounts[0].Amount != 400 {
+		t.Errorf("discounts = %+v, want 400 off by the largest one", quote.Discounts)
+	}
+	if p := quote.Promo; p == nil || p.Amount != 800 {
+		t.Errorf("promo = %+v, want 800 off", p)
+	}
+	if quote.Total != 800 {
+		t.Errorf("total = %d, want 800", quote.Total)
+	}
+
+	// a fixed promo never takes the total below zero
+	req.Membership = nil
+	req.Promo = &Promo{Id: 2, Code: "FREE", Kind: PromoFixed, Value: 5000}
+	quote = Price(tariffs, nil, req)
+	if quote.Total != 0 || quote.Promo.Amount != 3000 {
+		t.Errorf("Price = total %d with %d off, want 0 with 3000", quote.Total, quote.Promo.Amount)
+	}
+}
+
+func TestPriceUsedUpMembership(t *testing.T) {
+	loc := time.UTC
+	req := Request{
+		Start:      date(loc, 3, 10, 0),
+		End:        date(loc, 3, 11, 0),
+		Location:   loc,
+		Membership: &Membership{Id: 1, Name: "Basic", Period: "2025-03"},
+	}
+
+	if quote := Price(tariffs, nil, req); quote.Membership != nil || quote.Total != 1000 {
+		t.Errorf("Price = %+v, want the full price without a membership", quote)
+	}
+}
@@ -0,0 +1,130 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// MaxOccurrences limits how many bookings a single series may expand into
+const MaxOccurrences = 500
+
+var (
+	ErrUnbounded     = errors.New("rrule must contain UNTIL or COUNT")
+	ErrTooMany       = fmt.Errorf("rrule expands to more than %d occurrences", MaxOccurrences)
+	ErrNoOccurrences = errors.New("rrule produces no occurrences")
+	ErrInvalidSplit  = errors.New("split point is not after series start")
+)
+
+// Rule is an iCalendar RRULE anchored at DTStart with optional EXDATE exclusions
+type Rule struct {
+	RRule   string
+	DTStart time.Time
+	ExDates []time.Time
+}
+
+func parse(rule string, dtstart time.Time) (*rrule.ROption, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if strings.Contains(rule, "DTSTART") {
+		return nil, errors.New("DTSTART must be passed as start_time, not inside rrule")
+	}
+
+	// a floating UNTIL is wall clock time of the zone of the series, like DTSTART
+	opt, err := rrule.StrToROptionInLocation(rule, dtstart.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+	if opt.Count == 0 && opt.Until.IsZero() {
+		return nil, ErrUnbounded
+	}
+	opt.Dtstart = dtstart
+	return opt, nil
+}
+
+// Normalize validates rule and returns it in canonical form without the "RRULE:" prefix. A floating
+// UNTIL is read in loc.
+func Normalize(rule string, loc *time.Location) (string, error) {
+	opt, err := parse(rule, time.Now().In(loc))
+	if err != nil {
+		return "", err
+	}
+	return opt.RRuleString(), nil
+}
+
+// Expand returns start times of all occurrences of r with EXDATE entries removed
+func Expand(r Rule) ([]time.Time, error) {
+	opt, err := parse(r.RRule, r.DTStart)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+
+	var occurrences []time.Time
+	next := rr.Iterator()
+	for t, ok := next(); ok; t, ok = next() {
+		if excluded(t, r.ExDates) {
+			continue
+		}
+		if len(occurrences) == MaxOccurrences {
+			return nil, ErrTooMany
+		}
+		occurrences = append(occurrences, t)
+	}
+
+	if len(occurrences) == 0 {
+		return nil, ErrNoOccurrences
+	}
+	return occurrences, nil
+}
+
+// Split cuts r at occurrence at. It returns the rule for the head of the series,
+// which ends right before at, and the rule for the tail starting at at. COUNT is
+// distributed between the two parts so the series keeps its total length.
+func Split(r Rule, at time.Time) (head string, tail string, err error) {
+	if !at.After(r.DTStart) {
+		return "", "", ErrInvalidSplit
+	}
+
+	opt, err := parse(r.RRule, r.DTStart)
+	if err != nil {
+		return "", "", err
+	}
+
+	headOpt, tailOpt := *opt, *opt
+	headOpt.Dtstart, tailOpt.Dtstart = time.Time{}, time.Time{}
+
+	headOpt.Until = at.Add(-time.Second)
+	headOpt.Count = 0
+
+	if opt.Count != 0 {
+		rr, err := rrule.NewRRule(*opt)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid rrule: %w", err)
+		}
+		before := 0
+		for _, t := range rr.Between(r.DTStart, at, true) {
+			if t.Before(at) {
+				before++
+			}
+		}
+		tailOpt.Count = opt.Count - before
+	}
+
+	return headOpt.RRuleString(), tailOpt.RRuleString(), nil
+}
+
+func excluded(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if t.Equal(ex) {
+			return true
+		}
+	}
+	return false
+}
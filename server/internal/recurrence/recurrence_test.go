@@ -0,0 +1,162 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+		err  bool
+	}{
+		{rule: "RRULE:FREQ=WEEKLY;COUNT=3", want: "FREQ=WEEKLY;COUNT=3"},
+		{rule: "  FREQ=DAILY;INTERVAL=2;COUNT=5 ", want: "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{rule: "FREQ=WEEKLY", err: true},
+		{rule: "DTSTART:20250301T140000Z\nRRULE:FREQ=DAILY;COUNT=2", err: true},
+		{rule: "FREQ=SOMETIMES;COUNT=2", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.rule, time.UTC)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Normalize(%q) = %q, want error", tt.rule, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Normalize(%q) returned error: %s", tt.rule, err)
+		} else if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeUnbounded(t *testing.T) {
+	if _, err := Normalize("FREQ=DAILY", time.UTC); !errors.Is(err, ErrUnbounded) {
+		t.Errorf("Normalize of a rule without UNTIL or COUNT returned %v, want ErrUnbounded", err)
+	}
+}
+
+func TestNormalizeFloatingUntil(t *testing.T) {
+	loc := berlin(t)
+
+	got, err := Normalize("FREQ=DAILY;UNTIL=20250310T180000", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 18:00 in Berlin in winter is 17:00 UTC
+	if want := "FREQ=DAILY;UNTIL=20250310T170000Z"; got != want {
+		t.Errorf("Normalize = %q, want %q", got, want)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+
+	got, err := Expand(Rule{
+		RRule:   "FREQ=WEEKLY;COUNT=4",
+		DTStart: start,
+		ExDates: []time.Time{start.AddDate(0, 0, 7)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []time.Time{start, start.AddDate(0, 0, 14), start.AddDate(0, 0, 21)}
+	if len(got) != len(want) {
+		t.Fatalf("Expand returned %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExpandKeepsWallClockOverDaylightSavingChange(t *testing.T) {
+	loc := berlin(t)
+	// clocks go forward on 2025-03-30
+	start := time.Date(2025, 3, 29, 18, 0, 0, 0, loc)
+
+	got, err := Expand(Rule{RRule: "FREQ=DAILY;COUNT=3", DTStart: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, occurrence := range got {
+		if h, m := occurrence.In(loc).Hour(), occurrence.In(loc).Minute(); h != 18 || m != 0 {
+			t.Errorf("occurrence %s is not at 18:00 venue time", occurrence)
+		}
+	}
+	if d := got[1].Sub(got[0]); d != 23*time.Hour {
+		t.Errorf("occurrences across the change are %s apart, want 23h", d)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rule Rule
+		want error
+	}{
+		{"too many", Rule{RRule: "FREQ=DAILY;COUNT=501", DTStart: start}, ErrTooMany},
+		{"all excluded", Rule{RRule: "FREQ=DAILY;COUNT=1", DTStart: start, ExDates: []time.Time{start}}, ErrNoOccurrences},
+		{"unbounded", Rule{RRule: "FREQ=DAILY", DTStart: start}, ErrUnbounded},
+	}
+
+	for _, tt := range tests {
+		if _, err := Expand(tt.rule); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Expand returned %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	at := start.AddDate(0, 0, 14)
+	r := Rule{RRule: "FREQ=WEEKLY;COUNT=5", DTStart: start}
+
+	head, tail, err := Split(r, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headOccurrences, err := Expand(Rule{RRule: head, DTStart: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headOccurrences) != 2 || !headOccurrences[1].Before(at) {
+		t.Errorf("head %q expands to %v, want the two occurrences before %s", head, headOccurrences, at)
+	}
+
+	tailOccurrences, err := Expand(Rule{RRule: tail, DTStart: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tailOccurrences) != 3 || !tailOccurrences[0].Equal(at) {
+		t.Errorf("tail %q expands to %v, want three occurrences from %s", tail, tailOccurrences, at)
+	}
+}
+
+func TestSplitAtStart(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+
+	if _, _, err := Split(Rule{RRule: "FREQ=WEEKLY;COUNT=5", DTStart: start}, start); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("Split at the start returned %v, want ErrInvalidSplit", err)
+	}
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	bookingv1 "github.com/alexey-dobry/booking-service/server/api/booking/v1"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type bookingService struct {
+	bookingv1.UnimplementedBookingServiceServer
+	server *server.Server
+}
+
+// fromTimestamp converts ts, keeping unset timestamps zero like missing json fields
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toBooking(b models.Booking) *bookingv1.Booking {
+	booking := &bookingv1.Booking{
+		Id:            int64(b.Id),
+		UserId:        int64(b.UserId),
+		ResourceId:    int64(b.ResourceId),
+		StartTime:     timestamppb.New(b.StartTime),
+		EndTime:       timestamppb.New(b.EndTime),
+		Text:          b.Text,
+		Status:        b.Status,
+		HoldExpiresAt: toTimestamp(b.HoldExpiresAt),
+		Sequence:      int64(b.Sequence),
+		Price:         int64(b.Price),
+		PartySize:     int64(b.PartySize),
+		UpdatedAt:     timestamppb.New(b.UpdatedAt),
+	}
+	if b.SeriesId != nil {
+		seriesId := int64(*b.SeriesId)
+		booking.SeriesId = &seriesId
+	}
+	if b.GroupId != nil {
+		groupId := int64(*b.GroupId)
+		booking.GroupId = &groupId
+	}
+	return booking
+}
+
+func toWaitlistEntry(e models.WaitlistEntry) *bookingv1.WaitlistEntry {
+	return &bookingv1.WaitlistEntry{
+		Id:         int64(e.Id),
+		UserId:     int64(e.UserId),
+		ResourceId: int64(e.ResourceId),
+		StartTime:  timestamppb.New(e.StartTime),
+		EndTime:    timestamppb.New(e.EndTime),
+		Text:       e.Text,
+		Status:     e.Status,
+		Priority:   e.Priority,
+		CreatedAt:  timestamppb.New(e.CreatedAt),
+	}
+}
+
+func (bs *bookingService) CreateBooking(ctx context.Context, req *bookingv1.CreateBookingRequest) (*bookingv1.CreateBookingResponse, error) {
+	newBooking := models.Booking{
+		UserId:     int(req.UserId),
+		ResourceId: int(req.ResourceId),
+		StartTime:  fromTimestamp(req.StartTime),
+		EndTime:    fromTimestamp(req.EndTime),
+		PartySize:  int(req.PartySize),
+		Text:       req.Text,
+		PromoCode:  req.PromoCode,
+	}
+
+	if err := server.AuthorizeUser(ctx, newBooking.UserId); err != nil {
+		return nil, toStatus(err)
+	}
+
+	booking, entry, err := bs.server.CreateBooking(ctx, newBooking, req.Waitlist)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if entry != nil {
+		return &bookingv1.CreateBookingResponse{Result: &bookingv1.CreateBookingResponse_WaitlistEntry{WaitlistEntry: toWaitlistEntry(*entry)}}, nil
+	}
+	return &bookingv1.CreateBookingResponse{Result: &bookingv1.CreateBookingResponse_Booking{Booking: toBooking(booking)}}, nil
+}
+
+func (bs *bookingService) GetBooking(ctx context.Context, req *bookingv1.GetBookingRequest) (*bookingv1.Booking, error) {
+	booking, err := bs.server.GetBooking(ctx, int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toBooking(booking), nil
+}
+
+func (bs *bookingService) ListBookings(ctx context.Context, req *bookingv1.ListBookingsRequest) (*bookingv1.ListBookingsResponse, error) {
+	bookings, err := bs.server.ListBookings(ctx, req.IncludeCancelled)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookingv1.ListBookingsResponse{Bookings: make([]*bookingv1.Booking, len(bookings))}
+	for i, b := range bookings {
+		resp.Bookings[i] = toBooking(b)
+	}
+	return resp, nil
+}
+
+func (bs *bookingService) UpdateBooking(ctx context.Context, req *bookingv1.UpdateBookingRequest) (*bookingv1.Booking, error) {
+	newBookingData := models.Booking{
+		ResourceId: int(req.ResourceId),
+		StartTime:  fromTimestamp(req.StartTime),
+		EndTime:    fromTimestamp(req.EndTime),
+		PartySize:  int(req.PartySize),
+		Text:       req.Text,
+	}
+
+	if err := bs.server.AuthorizeBooking(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	booking, err := bs.server.UpdateBooking(ctx, int(req.Id), newBookingData)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toBooking(booking), nil
+}
+
+func (bs *bookingService) CancelBooking(ctx context.Context, req *bookingv1.CancelBookingRequest) (*bookingv1.CancelBookingResponse, error) {
+	if err := bs.server.AuthorizeBooking(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	cancellation, err := bs.server.CancelBooking(ctx, int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookingv1.CancelBookingResponse{Fee: int64(cancellation.Fee), Refund: int64(cancellation.Refund), Reason: cancellation.Reason}, nil
+}
@@ -0,0 +1,173 @@
+// Package rpc serves the gRPC API defined in api/booking/v1. It is a thin layer over the
+// service methods of server.Server, which the REST handlers use as well.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bookingv1 "github.com/alexey-dobry/booking-service/server/api/booking/v1"
+	"github.com/alexey-dobry/booking-service/server/internal/logger"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// New returns a gRPC server with the user and booking services, health checking and reflection
+func New(s *server.Server, logger *logger.Logger) *grpc.Server {
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logErrors(logger), authenticate(s)),
+		grpc.StreamInterceptor(authenticateStream(s)),
+	)
+
+	bookingv1.RegisterUserServiceServer(g, &userService{server: s})
+	bookingv1.RegisterBookingServiceServer(g, &bookingService{server: s})
+
+	healthServer := health.NewServer()
+	for name := range g.GetServiceInfo() {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(g, healthServer)
+
+	reflection.Register(g)
+
+	return g
+}
+
+// logErrors logs failed calls like the REST handlers log failed requests
+func logErrors(logger *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			message := fmt.Sprintf("%s: %s", info.FullMethod, err)
+			if status.Code(err) == codes.Internal {
+				logger.Error(message)
+			} else {
+				logger.Debug(message)
+			}
+		}
+		return resp, err
+	}
+}
+
+// public reports whether method is served without credentials: creating a user, health
+// checking and reflection. Every other method requires basic auth of a member, staff or an
+// admin, passed in the authorization metadata like the REST credentials.
+func public(method string) bool {
+	return method == bookingv1.UserService_CreateUser_FullMethodName ||
+		strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// authenticateCall checks credentials of a call of method and returns ctx carrying the user
+func authenticateCall(ctx context.Context, s *server.Server, method string) (context.Context, error) {
+	if public(method) {
+		return ctx, nil
+	}
+
+	username, password, ok := basicAuth(ctx)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "Unauthorized: credentials are required")
+	}
+
+	user, err := s.Authenticate(ctx, username, password, models.RoleMember, models.RoleStaff, models.RoleAdmin)
+	if err != nil {
+		return ctx, toStatus(err)
+	}
+	return server.ContextWithUser(ctx, user), nil
+}
+
+// authenticate checks credentials of calls and passes the user to the service methods in the context
+func authenticate(s *server.Server) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticateCall(ctx, s, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticatedStream is a stream whose context carries the authenticated user
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (as *authenticatedStream) Context() context.Context {
+	return as.ctx
+}
+
+// authenticateStream checks credentials of streams like authenticate does of unary calls
+func authenticateStream(s *server.Server) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateCall(ss.Context(), s, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// basicAuth returns the credentials of "authorization: Basic ..." metadata of the call
+func basicAuth(ctx context.Context) (string, string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", "", false
+	}
+
+	r := http.Request{Header: http.Header{"Authorization": values[:1]}}
+	return r.BasicAuth()
+}
+
+// toStatus converts an error of a service method to a gRPC status error. Policy violations
+// are attached as google.rpc.PreconditionFailure.
+func toStatus(err error) error {
+	var e *server.Error
+	if !errors.As(err, &e) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch e.Kind {
+	case server.KindInvalid:
+		return status.Error(codes.InvalidArgument, e.Message)
+	case server.KindNotFound:
+		return status.Error(codes.NotFound, e.Message)
+	case server.KindConflict:
+		return status.Error(codes.Aborted, e.Message)
+	case server.KindInsufficientFunds:
+		return status.Error(codes.FailedPrecondition, e.Message)
+	case server.KindUnauthenticated:
+		return status.Error(codes.Unauthenticated, e.Message)
+	case server.KindForbidden:
+		return status.Error(codes.PermissionDenied, e.Message)
+	case server.KindPolicy:
+		failure := &errdetails.PreconditionFailure{}
+		for _, v := range e.Violations {
+			failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        v.Kind,
+				Subject:     v.Name,
+				Description: v.Message,
+			})
+		}
+		st, detailErr := status.New(codes.FailedPrecondition, e.Message).WithDetails(failure)
+		if detailErr != nil {
+			return status.Error(codes.FailedPrecondition, e.Message)
+		}
+		return st.Err()
+	default:
+		return status.Error(codes.Internal, e.Message)
+	}
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	bookingv1 "github.com/alexey-dobry/booking-service/server/api/booking/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestPublic(t *testing.T) {
+	for _, method := range []string{
+		bookingv1.UserService_CreateUser_FullMethodName,
+		"/grpc.health.v1.Health/Check",
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	} {
+		if !public(method) {
+			t.Errorf("%s requires credentials", method)
+		}
+	}
+
+	for _, method := range []string{
+		bookingv1.UserService_GetUser_FullMethodName,
+		bookingv1.UserService_ListUsers_FullMethodName,
+		bookingv1.UserService_UpdateUser_FullMethodName,
+		bookingv1.UserService_DeleteUser_FullMethodName,
+		bookingv1.BookingService_GetBooking_FullMethodName,
+		bookingv1.BookingService_ListBookings_FullMethodName,
+		bookingv1.BookingService_CancelBooking_FullMethodName,
+	} {
+		if public(method) {
+			t.Errorf("%s is served without credentials", method)
+		}
+	}
+}
+
+func TestAuthenticateWithoutCredentials(t *testing.T) {
+	interceptor := authenticate(nil)
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "served", nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: bookingv1.UserService_DeleteUser_FullMethodName}
+	if _, err := interceptor(context.Background(), nil, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call without credentials returned %v, want UNAUTHENTICATED", err)
+	}
+
+	// credentials of another scheme are not basic auth
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token"))
+	if _, err := interceptor(ctx, nil, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call with a bearer token returned %v, want UNAUTHENTICATED", err)
+	}
+
+	info.FullMethod = bookingv1.UserService_CreateUser_FullMethodName
+	if resp, err := interceptor(context.Background(), nil, info, handler); err != nil || resp != "served" {
+		t.Errorf("CreateUser returned %v, %v, want it served without credentials", resp, err)
+	}
+}
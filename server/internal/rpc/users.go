@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+
+	bookingv1 "github.com/alexey-dobry/booking-service/server/api/booking/v1"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/server"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type userService struct {
+	bookingv1.UnimplementedUserServiceServer
+	server *server.Server
+}
+
+// toUser converts u leaving out the password hash
+func toUser(u models.User) *bookingv1.User {
+	return &bookingv1.User{
+		Id:        int64(u.Id),
+		Username:  u.Username,
+		Role:      u.Role,
+		Priority:  u.Priority,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+}
+
+func (us *userService) CreateUser(ctx context.Context, req *bookingv1.CreateUserRequest) (*bookingv1.User, error) {
+	user, err := us.server.CreateUser(ctx, models.User{Username: req.Username, Password: req.Password})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+func (us *userService) GetUser(ctx context.Context, req *bookingv1.GetUserRequest) (*bookingv1.User, error) {
+	user, err := us.server.GetUser(ctx, int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+func (us *userService) ListUsers(ctx context.Context, req *bookingv1.ListUsersRequest) (*bookingv1.ListUsersResponse, error) {
+	users, err := us.server.ListUsers(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookingv1.ListUsersResponse{Users: make([]*bookingv1.User, len(users))}
+	for i, u := range users {
+		resp.Users[i] = toUser(u)
+	}
+	return resp, nil
+}
+
+func (us *userService) UpdateUser(ctx context.Context, req *bookingv1.UpdateUserRequest) (*bookingv1.User, error) {
+	if err := server.AuthorizeAccount(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	user, err := us.server.UpdateUser(ctx, int(req.Id), models.User{Username: req.Username, Password: req.Password})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+func (us *userService) DeleteUser(ctx context.Context, req *bookingv1.DeleteUserRequest) (*bookingv1.DeleteUserResponse, error) {
+	if err := server.AuthorizeAccount(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := us.server.DeleteUser(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookingv1.DeleteUserResponse{}, nil
+}
@@ -0,0 +1,347 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Names of rules reported in violations
+const (
+	RuleOpeningHours = "opening_hours"
+	RuleException    = "schedule_exception"
+	RuleHoliday      = "holiday"
+	RuleMaintenance  = "maintenance"
+)
+
+// Interval is a half-open time interval [Start, End)
+type Interval struct {
+	Start time.Time `json:"start_time"`
+	End   time.Time `json:"end_time"`
+}
+
+// Hours is a weekly opening interval. Open and Close are minutes since midnight,
+// Close <= Open means the venue closes after midnight of the next day.
+type Hours struct {
+	Weekday time.Weekday
+	Open    int
+	Close   int
+}
+
+// Exception overrides weekly hours for a single date: either the day is closed
+// (holiday) or it has special opening hours
+type Exception struct {
+	Id     int
+	Date   time.Time
+	Closed bool
+	Open   int
+	Close  int
+	Reason string
+}
+
+// Maintenance is a period when a resource can not be booked
+type Maintenance struct {
+	Id     int
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// Schedule combines all rules which restrict when a resource can be booked.
+// An empty Hours list means the resource is open around the clock.
+type Schedule struct {
+	Hours       []Hours
+	Exceptions  []Exception
+	Maintenance []Maintenance
+	Location    *time.Location
+}
+
+// Violation is returned by Check and names the rule which forbids the interval
+type Violation struct {
+	Rule    string
+	Id      int
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// ParseClock converts "HH:MM" into minutes since midnight
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock converts minutes since midnight into "HH:MM"
+func FormatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func (s Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+func (s Schedule) exception(day time.Time) (Exception, bool) {
+	for _, e := range s.Exceptions {
+		if e.Date.Year() == day.Year() && e.Date.YearDay() == day.YearDay() {
+			return e, true
+		}
+	}
+	return Exception{}, false
+}
+
+// dayInterval builds the interval from wall clock times of day rather than by adding minutes to
+// midnight, which would shift the hours by the clock change on daylight saving days
+func dayInterval(day time.Time, open, close int) Interval {
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, open/60, open%60, 0, 0, day.Location())
+	if close <= open {
+		d++
+	}
+	end := time.Date(y, m, d, close/60, close%60, 0, 0, day.Location())
+	return Interval{Start: start, End: end}
+}
+
+// opening returns intervals during which the venue is open on day, which is midnight in venue location
+func (s Schedule) opening(day time.Time) []Interval {
+	if e, ok := s.exception(day); ok {
+		if e.Closed {
+			return nil
+		}
+		return []Interval{dayInterval(day, e.Open, e.Close)}
+	}
+
+	if len(s.Hours) == 0 {
+		return []Interval{{Start: day, End: day.AddDate(0, 0, 1)}}
+	}
+
+	var intervals []Interval
+	for _, h := range s.Hours {
+		if h.Weekday == day.Weekday() {
+			intervals = append(intervals, dayInterval(day, h.Open, h.Close))
+		}
+	}
+	return intervals
+}
+
+func midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// OpenIntervals returns intervals within [from, to) when the resource is open and not under maintenance
+func (s Schedule) OpenIntervals(from, to time.Time) []Interval {
+	loc := s.location()
+
+	var open []Interval
+	// start a day earlier to catch opening hours which run past midnight
+	for day := midnight(from, loc).AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		open = append(open, s.opening(day)...)
+	}
+
+	var maintenance []Interval
+	for _, m := range s.Maintenance {
+		maintenance = append(maintenance, Interval{Start: m.Start, End: m.End})
+	}
+
+	return Subtract(Clip(Merge(open), from, to), maintenance)
+}
+
+// Check returns *Violation if [start, end) can not be booked according to the schedule
+func (s Schedule) Check(start, end time.Time) error {
+	loc := s.location()
+
+	for _, m := range s.Maintenance {
+		if m.Start.Before(end) && m.End.After(start) {
+			return &Violation{
+				Rule:    RuleMaintenance,
+				Id:      m.Id,
+				Message: fmt.Sprintf("resource is under maintenance from %s to %s (%s)", m.Start.In(loc).Format(time.RFC3339), m.End.In(loc).Format(time.RFC3339), m.Reason),
+			}
+		}
+	}
+
+	gaps := Subtract([]Interval{{Start: start, End: end}}, s.OpenIntervals(start, end))
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	day := midnight(gaps[0].Start, loc)
+	if e, ok := s.exception(day); ok {
+		if e.Closed {
+			return &Violation{Rule: RuleHoliday, Id: e.Id, Message: fmt.Sprintf("venue is closed on %s (%s)", day.Format(time.DateOnly), e.Reason)}
+		}
+		return &Violation{
+			Rule:    RuleException,
+			Id:      e.Id,
+			Message: fmt.Sprintf("on %s venue is only open %s-%s (%s)", day.Format(time.DateOnly), FormatClock(e.Open), FormatClock(e.Close), e.Reason),
+		}
+	}
+
+	var hours []string
+	for _, h := range s.Hours {
+		if h.Weekday == day.Weekday() {
+			hours = append(hours, FormatClock(h.Open)+"-"+FormatClock(h.Close))
+		}
+	}
+	if len(hours) == 0 {
+		return &Violation{Rule: RuleOpeningHours, Message: fmt.Sprintf("venue is closed on %s", day.Weekday())}
+	}
+	return &Violation{
+		Rule:    RuleOpeningHours,
+		Message: fmt.Sprintf("%s is outside opening hours on %s (%s)", gaps[0].Start.In(loc).Format("15:04"), day.Weekday(), strings.Join(hours, ", ")),
+	}
+}
+
+// Merge sorts intervals and joins overlapping or adjacent ones
+func Merge(intervals []Interval) []Interval {
+	sorted := append([]Interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []Interval
+	for _, in := range sorted {
+		if !in.End.After(in.Start) {
+			continue
+		}
+		if n := len(merged); n > 0 && !in.Start.After(merged[n-1].End) {
+			if in.End.After(merged[n-1].End) {
+				merged[n-1].End = in.End
+			}
+			continue
+		}
+		merged = append(merged, in)
+	}
+	return merged
+}
+
+// Clip cuts intervals to [from, to)
+func Clip(intervals []Interval, from, to time.Time) []Interval {
+	var clipped []Interval
+	for _, in := range intervals {
+		if in.Start.Before(from) {
+			in.Start = from
+		}
+		if in.End.After(to) {
+			in.End = to
+		}
+		if in.End.After(in.Start) {
+			clipped = append(clipped, in)
+		}
+	}
+	return clipped
+}
+
+// Subtract removes busy intervals from free ones
+func Subtract(free []Interval, busy []Interval) []Interval {
+	result := Merge(free)
+	for _, b := range Merge(busy) {
+		var next []Interval
+		for _, f := range result {
+			if !b.Start.Before(f.End) || !b.End.After(f.Start) {
+				next = append(next, f)
+				continue
+			}
+			if b.Start.After(f.Start) {
+				next = append(next, Interval{Start: f.Start, End: b.Start})
+			}
+			if b.End.Before(f.End) {
+				next = append(next, Interval{Start: b.End, End: f.End})
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// Occupant is a party of Size people taking places of a resource during [Start, End)
+type Occupant struct {
+	Start time.Time
+	End   time.Time
+	Size  int
+}
+
+// Slot is an interval during all of which Remaining places of a resource are free
+type Slot struct {
+	Start     time.Time `json:"start_time"`
+	End       time.Time `json:"end_time"`
+	Remaining int       `json:"remaining_capacity"`
+}
+
+// Peak returns the largest number of places occupants take at the same moment within [from, to)
+// and the index of an occupant present at that moment, or -1 if no occupant overlaps the interval.
+// Occupancy only grows when an occupant starts, so it is enough to look at from and every start.
+func Peak(occupants []Occupant, from, to time.Time) (int, int) {
+	moments := []time.Time{from}
+	for _, o := range occupants {
+		if o.Start.After(from) && o.Start.Before(to) {
+			moments = append(moments, o.Start)
+		}
+	}
+
+	peak, index := 0, -1
+	for _, t := range moments {
+		used, present := 0, -1
+		for i, o := range occupants {
+			if !o.Start.After(t) && o.End.After(t) {
+				used += o.Size
+				if present < 0 {
+					present = i
+				}
+			}
+		}
+		if used > peak {
+			peak, index = used, present
+		}
+	}
+	return peak, index
+}
+
+// Remaining splits open intervals at every start and end of occupants and returns the parts in
+// which at least size of capacity places are free. Adjacent parts with the same number of free
+// places are joined.
+func Remaining(open []Interval, occupants []Occupant, capacity, size int) []Slot {
+	var slots []Slot
+	for _, in := range Merge(open) {
+		cuts := []time.Time{in.Start, in.End}
+		for _, o := range occupants {
+			for _, t := range []time.Time{o.Start, o.End} {
+				if t.After(in.Start) && t.Before(in.End) {
+					cuts = append(cuts, t)
+				}
+			}
+		}
+		sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+		for i := 0; i+1 < len(cuts); i++ {
+			a, b := cuts[i], cuts[i+1]
+			if !a.Before(b) {
+				continue
+			}
+
+			free := capacity
+			for _, o := range occupants {
+				if o.Start.Before(b) && o.End.After(a) {
+					free -= o.Size
+				}
+			}
+			if free < size || free <= 0 {
+				continue
+			}
+
+			if n := len(slots); n > 0 && slots[n-1].End.Equal(a) && slots[n-1].Remaining == free {
+				slots[n-1].End = b
+				continue
+			}
+			slots = append(slots, Slot{Start: a, End: b, Remaining: free})
+		}
+	}
+	return slots
+}
@@ -0,0 +1,196 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+// at returns 2025-03-<day> hh:mm in loc; 2025-03-03 is a Monday
+func at(loc *time.Location, day, hh, mm int) time.Time {
+	return time.Date(2025, 3, day, hh, mm, 0, 0, loc)
+}
+
+func rule(err error) string {
+	var v *Violation
+	if errors.As(err, &v) {
+		return v.Rule
+	}
+	return ""
+}
+
+func TestParseClock(t *testing.T) {
+	if m, err := ParseClock("09:30"); err != nil || m != 9*60+30 {
+		t.Errorf("ParseClock(09:30) = %d, %v", m, err)
+	}
+	if _, err := ParseClock("25:00"); err == nil {
+		t.Error("ParseClock(25:00) returned no error")
+	}
+	if s := FormatClock(22*60 + 5); s != "22:05" {
+		t.Errorf("FormatClock = %q, want 22:05", s)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	loc := time.UTC
+	s := Schedule{
+		Hours: []Hours{
+			{Weekday: time.Monday, Open: 10 * 60, Close: 22 * 60},
+			// Friday runs past midnight
+			{Weekday: time.Friday, Open: 18 * 60, Close: 2 * 60},
+		},
+		Exceptions: []Exception{
+			{Id: 1, Date: at(loc, 10, 0, 0), Closed: true, Reason: "holiday"},
+			{Id: 2, Date: at(loc, 17, 0, 0), Open: 12 * 60, Close: 16 * 60, Reason: "short day"},
+		},
+		Maintenance: []Maintenance{
+			{Id: 3, Start: at(loc, 3, 15, 0), End: at(loc, 3, 16, 0), Reason: "repair"},
+		},
+		Location: loc,
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       string
+	}{
+		{"within hours", at(loc, 3, 10, 0), at(loc, 3, 12, 0), ""},
+		{"before opening", at(loc, 3, 9, 0), at(loc, 3, 11, 0), RuleOpeningHours},
+		{"after closing", at(loc, 3, 21, 0), at(loc, 3, 23, 0), RuleOpeningHours},
+		{"closed weekday", at(loc, 4, 12, 0), at(loc, 4, 13, 0), RuleOpeningHours},
+		{"maintenance", at(loc, 3, 14, 0), at(loc, 3, 15, 30), RuleMaintenance},
+		{"ends when maintenance starts", at(loc, 3, 14, 0), at(loc, 3, 15, 0), ""},
+		{"past midnight", at(loc, 7, 23, 0), at(loc, 8, 1, 30), ""},
+		{"past closing after midnight", at(loc, 7, 23, 0), at(loc, 8, 3, 0), RuleOpeningHours},
+		{"holiday", at(loc, 10, 12, 0), at(loc, 10, 13, 0), RuleHoliday},
+		{"special hours", at(loc, 17, 12, 0), at(loc, 17, 16, 0), ""},
+		{"outside special hours", at(loc, 17, 15, 0), at(loc, 17, 17, 0), RuleException},
+	}
+
+	for _, tt := range tests {
+		if got := rule(s.Check(tt.start, tt.end)); got != tt.want {
+			t.Errorf("%s: Check = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAroundTheClock(t *testing.T) {
+	s := Schedule{}
+	if err := s.Check(at(time.UTC, 3, 23, 0), at(time.UTC, 4, 5, 0)); err != nil {
+		t.Errorf("schedule without hours rejected a booking: %s", err)
+	}
+}
+
+func TestCheckKeepsHoursOverDaylightSavingChange(t *testing.T) {
+	loc := berlin(t)
+	// clocks go forward on Sunday 2025-03-30, so the day has 23 hours
+	s := Schedule{Hours: []Hours{{Weekday: time.Sunday, Open: 10 * 60, Close: 22 * 60}}, Location: loc}
+
+	if err := s.Check(at(loc, 30, 10, 0), at(loc, 30, 11, 0)); err != nil {
+		t.Errorf("booking from opening time was rejected: %s", err)
+	}
+	if err := s.Check(at(loc, 30, 22, 0), at(loc, 30, 23, 0)); rule(err) != RuleOpeningHours {
+		t.Errorf("booking after closing time returned %v", err)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	loc := time.UTC
+	free := []Interval{{Start: at(loc, 3, 10, 0), End: at(loc, 3, 20, 0)}}
+	busy := []Interval{
+		{Start: at(loc, 3, 12, 0), End: at(loc, 3, 13, 0)},
+		{Start: at(loc, 3, 12, 30), End: at(loc, 3, 14, 0)},
+	}
+
+	got := Subtract(free, busy)
+	want := []Interval{
+		{Start: at(loc, 3, 10, 0), End: at(loc, 3, 12, 0)},
+		{Start: at(loc, 3, 14, 0), End: at(loc, 3, 20, 0)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Subtract = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPeak(t *testing.T) {
+	loc := time.UTC
+	occupants := []Occupant{
+		{Start: at(loc, 3, 10, 0), End: at(loc, 3, 12, 0), Size: 2},
+		{Start: at(loc, 3, 11, 0), End: at(loc, 3, 13, 0), Size: 3},
+		{Start: at(loc, 3, 12, 0), End: at(loc, 3, 14, 0), Size: 1},
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		peak     int
+		index    int
+	}{
+		{"overlap of the first two", at(loc, 3, 10, 0), at(loc, 3, 14, 0), 5, 0},
+		{"after the first ends", at(loc, 3, 12, 0), at(loc, 3, 14, 0), 4, 1},
+		{"last one alone", at(loc, 3, 13, 0), at(loc, 3, 14, 0), 1, 2},
+		{"nobody", at(loc, 3, 14, 0), at(loc, 3, 15, 0), 0, -1},
+	}
+
+	for _, tt := range tests {
+		peak, index := Peak(occupants, tt.from, tt.to)
+		if peak != tt.peak || index != tt.index {
+			t.Errorf("%s: Peak = %d, %d, want %d, %d", tt.name, peak, index, tt.peak, tt.index)
+		}
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	loc := time.UTC
+	open := []Interval{{Start: at(loc, 3, 10, 0), End: at(loc, 3, 16, 0)}}
+	occupants := []Occupant{
+		{Start: at(loc, 3, 11, 0), End: at(loc, 3, 12, 0), Size: 4},
+		{Start: at(loc, 3, 13, 0), End: at(loc, 3, 14, 0), Size: 2},
+	}
+
+	got := Remaining(open, occupants, 6, 3)
+	want := []Slot{
+		{Start: at(loc, 3, 10, 0), End: at(loc, 3, 11, 0), Remaining: 6},
+		{Start: at(loc, 3, 12, 0), End: at(loc, 3, 13, 0), Remaining: 6},
+		{Start: at(loc, 3, 13, 0), End: at(loc, 3, 14, 0), Remaining: 4},
+		{Start: at(loc, 3, 14, 0), End: at(loc, 3, 16, 0), Remaining: 6},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Remaining = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) || got[i].Remaining != want[i].Remaining {
+			t.Errorf("slot %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRemainingJoinsEqualSlots(t *testing.T) {
+	loc := time.UTC
+	open := []Interval{{Start: at(loc, 3, 10, 0), End: at(loc, 3, 14, 0)}}
+	// back to back parties of the same size leave the same places free
+	occupants := []Occupant{
+		{Start: at(loc, 3, 10, 0), End: at(loc, 3, 12, 0), Size: 1},
+		{Start: at(loc, 3, 12, 0), End: at(loc, 3, 14, 0), Size: 1},
+	}
+
+	got := Remaining(open, occupants, 3, 1)
+	if len(got) != 1 || !got[0].Start.Equal(open[0].Start) || !got[0].End.Equal(open[0].End) || got[0].Remaining != 2 {
+		t.Errorf("Remaining = %v, want one slot of 2 places over the whole interval", got)
+	}
+}
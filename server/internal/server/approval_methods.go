@@ -0,0 +1,407 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// approvalTimeout is how long staff have to review a booking which requires approval
+const approvalTimeout = 24 * time.Hour
+
+// approvalExpiryInterval is how often approvals past their deadline are rejected
+const approvalExpiryInterval = time.Minute
+
+// approvalExpiredReason is the reason of approvals rejected because nobody reviewed them
+const approvalExpiredReason = "Not reviewed before the deadline"
+
+const approvalColumns = "booking_id, status, deadline, reason, reviewed_by, reviewed_at, created_at"
+
+func scanApproval(row pgx.Row, a *models.Approval) error {
+	return row.Scan(&a.BookingId, &a.Status, &a.Deadline, &a.Reason, &a.ReviewedBy, &a.ReviewedAt, &a.CreatedAt)
+}
+
+func getApproval(ctx context.Context, q querier, bookingId int) (models.Approval, error) {
+	var a models.Approval
+	err := scanApproval(q.QueryRow(ctx, "SELECT "+approvalColumns+" FROM booking_approvals WHERE booking_id=$1", bookingId), &a)
+	return a, err
+}
+
+// newBookingStatus returns the status new bookings of resourceId get: pending when the resource
+// requires approval, confirmed otherwise
+func newBookingStatus(ctx context.Context, q querier, resourceId int) (string, error) {
+	var requiresApproval bool
+	err := q.QueryRow(ctx, "SELECT requires_approval FROM resources WHERE id=$1", resourceId).Scan(&requiresApproval)
+	if err == pgx.ErrNoRows {
+		return models.BookingConfirmed, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if requiresApproval {
+		return models.BookingPending, nil
+	}
+	return models.BookingConfirmed, nil
+}
+
+// requestApproval queues b for review by staff if it is pending. The deadline is approvalTimeout
+// from now, or the start of the booking if that is earlier.
+func requestApproval(ctx context.Context, tx pgx.Tx, b models.Booking) error {
+	if b.Status != models.BookingPending {
+		return nil
+	}
+
+	deadline := time.Now().Add(approvalTimeout)
+	if b.StartTime.Before(deadline) {
+		deadline = b.StartTime
+	}
+
+	_, err := tx.Exec(ctx, "INSERT INTO booking_approvals (booking_id,deadline) VALUES ($1,$2)", b.Id, deadline)
+	return err
+}
+
+// saveReview records the decision on the approval of bookingId and returns the approval
+func saveReview(ctx context.Context, tx pgx.Tx, bookingId int, status string, reviewedBy *int, reason string) (models.Approval, error) {
+	var a models.Approval
+	query := "UPDATE booking_approvals SET status=$2, reason=$3, reviewed_by=$4, reviewed_at=NOW() WHERE booking_id=$1 RETURNING " + approvalColumns
+	err := scanApproval(tx.QueryRow(ctx, query, bookingId, status, reason, reviewedBy), &a)
+	return a, err
+}
+
+// rejectBooking cancels pending booking b free of charge, records the rejection and offers its slot
+// to the waitlist. It returns false without changes if b is no longer pending. The resource must be locked.
+func (s *Server) rejectBooking(ctx context.Context, tx pgx.Tx, b models.Booking, reviewedBy *int, reason string) (models.Approval, bool, error) {
+	cancelled, err := cancelBookingsWhere(ctx, tx, "id=$1 AND status=$2", b.Id, models.BookingPending)
+	if err != nil || len(cancelled) == 0 {
+		return models.Approval{}, false, err
+	}
+
+	a, err := saveReview(ctx, tx, b.Id, models.ApprovalRejected, reviewedBy, reason)
+	if err != nil {
+		return a, false, err
+	}
+	a.Booking = cancelled[0]
+
+	if _, err := s.promoteWaitlist(ctx, tx, b.ResourceId); err != nil {
+		return a, false, err
+	}
+	if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled); err != nil {
+		return a, false, err
+	}
+	return a, true, nil
+}
+
+// ListApprovals returns approvals with status ordered by deadline, optionally only those of
+// bookings of resourceId
+func (s *Server) ListApprovals(ctx context.Context, status string, resourceId int) ([]models.Approval, error) {
+	if err := validator.V.Var(status, "oneof=pending approved rejected withdrawn"); err != nil {
+		return nil, newError(KindInvalid, "Incorrect input data: status: %s", err)
+	}
+
+	// bookings cancelled while pending are withdrawn by expireApprovals; until then they are left out
+	query := "SELECT a.booking_id, a.status, a.deadline, a.reason, a.reviewed_by, a.reviewed_at, a.created_at FROM booking_approvals a JOIN bookings b ON b.id = a.booking_id " +
+		"WHERE a.status=$1 AND ($2 = 0 OR b.resource_id=$2) AND (a.status <> $3 OR b.status=$3) ORDER BY a.deadline, a.booking_id"
+	rows, err := s.database.Query(ctx, query, status, resourceId, models.ApprovalPending)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	approvals, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Approval, error) {
+		var a models.Approval
+		err := scanApproval(row, &a)
+		return a, err
+	})
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+
+	ids := make([]int, len(approvals))
+	for i, a := range approvals {
+		ids[i] = a.BookingId
+	}
+	rows, err = s.database.Query(ctx, "SELECT "+bookingColumns+" FROM bookings WHERE id=ANY($1)", ids)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	bookings, err := collectBookings(rows)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+
+	byId := make(map[int]models.Booking, len(bookings))
+	for _, b := range bookings {
+		byId[b.Id] = b
+	}
+	for i := range approvals {
+		approvals[i].Booking = byId[approvals[i].BookingId]
+	}
+	return approvals, nil
+}
+
+// ReviewBooking approves or rejects pending booking id on behalf of staff member reviewerId.
+// An approved booking is confirmed and charged, a rejected one is cancelled free of charge;
+// reason is required to reject.
+func (s *Server) ReviewBooking(ctx context.Context, id int, approve bool, reviewerId int, reason string) (models.Approval, error) {
+	reason = strings.TrimSpace(reason)
+	if err := validator.V.Var(reason, "max=200"); err != nil {
+		return models.Approval{}, newError(KindInvalid, "Incorrect input data: reason: %s", err)
+	}
+	if !approve && reason == "" {
+		return models.Approval{}, newError(KindInvalid, "Incorrect input data: reason is required to reject a booking")
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return models.Approval{}, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	booking, err := getBooking(ctx, tx, id)
+	if err == pgx.ErrNoRows {
+		return models.Approval{}, notFound(id)
+	} else if err != nil {
+		return models.Approval{}, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	if err := lockResource(ctx, tx, booking.ResourceId); err != nil {
+		return models.Approval{}, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	// the booking may have been cancelled or reviewed while waiting for the lock
+	if booking, err = getBooking(ctx, tx, id); err != nil {
+		return models.Approval{}, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	approval, err := getApproval(ctx, tx, id)
+	if err == pgx.ErrNoRows {
+		return approval, newError(KindInvalid, "Booking {%d} does not require approval", id)
+	} else if err != nil {
+		return approval, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	if approval.Status != models.ApprovalPending || booking.Status != models.BookingPending {
+		return approval, newError(KindConflict, "Booking {%d} is not pending approval; status: %s", id, booking.Status)
+	}
+
+	if approve {
+		query := "UPDATE bookings SET status=$2, sequence=sequence+1, updated_at=NOW() WHERE id=$1 RETURNING " + bookingColumns
+		if err := scanBooking(tx.QueryRow(ctx, query, id, models.BookingConfirmed), &booking); err != nil {
+			return approval, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+		}
+
+		// pending bookings are charged once they are approved
+		if err := s.chargeBooking(ctx, tx, booking); err != nil {
+			return approval, err
+		}
+
+		if approval, err = saveReview(ctx, tx, id, models.ApprovalApproved, &reviewerId, reason); err != nil {
+			return approval, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+		}
+		approval.Booking = booking
+
+		if err := s.publish(ctx, tx, outbox.BookingUpdated, booking); err != nil {
+			return approval, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+		}
+	} else {
+		if approval, _, err = s.rejectBooking(ctx, tx, booking, &reviewerId, reason); err != nil {
+			return approval, newError(KindInternal, "Failed to reject booking; additional info: %s", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return approval, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return approval, nil
+}
+
+// expireApprovals rejects pending bookings which were not reviewed before their deadline and
+// withdraws approvals of bookings cancelled while they were pending. It returns the rejected bookings.
+func (s *Server) expireApprovals(ctx context.Context) ([]models.Booking, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	withdrawn := "UPDATE booking_approvals a SET status=$1 FROM bookings b WHERE b.id = a.booking_id AND a.status=$2 AND b.status <> $3"
+	if _, err := tx.Exec(ctx, withdrawn, models.ApprovalWithdrawn, models.ApprovalPending, models.BookingPending); err != nil {
+		return nil, err
+	}
+
+	overdue := "SELECT " + bookingColumns + " FROM bookings WHERE status=$1 AND id IN (SELECT booking_id FROM booking_approvals WHERE status=$2 AND deadline < $3)"
+	rows, err := tx.Query(ctx, overdue, models.BookingPending, models.ApprovalPending, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := collectBookings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceIds := make([]int, len(bookings))
+	for i, b := range bookings {
+		resourceIds[i] = b.ResourceId
+	}
+	if err := lockResources(ctx, tx, resourceIds...); err != nil {
+		return nil, err
+	}
+
+	var rejected []models.Booking
+	for _, b := range bookings {
+		a, ok, err := s.rejectBooking(ctx, tx, b, nil, approvalExpiredReason)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rejected = append(rejected, a.Booking)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+// runApprovalExpiry periodically calls expireApprovals until ctx is cancelled
+func (s *Server) runApprovalExpiry(ctx context.Context) {
+	ticker := time.NewTicker(approvalExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rejected, err := s.expireApprovals(ctx)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("Failed to expire approvals; additional info: %s", err))
+			}
+			for _, b := range rejected {
+				s.logger.Debug(fmt.Sprintf("Booking {%d} was rejected: %s", b.Id, approvalExpiredReason))
+			}
+		}
+	}
+}
+
+// handleGetApprovals
+//
+// @Summary Get approvals queue
+// @Description Creates function which retrieves approvals of bookings of resources which require approval, by
+// @Description default the pending ones which wait for review, ordered by deadline. Requires basic auth of staff.
+// @Produces json
+//
+// @Param status query string false "pending (default), approved, rejected or withdrawn"
+// @Param resource_id query int false "Resource ID"
+//
+// @Success 200 {array} models.Approval "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 400 {object} integer "Unknown status"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /approvals [get]
+func (s *Server) handleGetApprovals() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = models.ApprovalPending
+		}
+		resourceId, _ := strconv.Atoi(r.URL.Query().Get("resource_id"))
+
+		approvals, err := s.ListApprovals(r.Context(), status, resourceId)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if len(approvals) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, r, approvals)
+		s.logger.Debug("Successfully retrieved approvals data")
+	}
+}
+
+// handleReviewBooking serves approve and reject requests of a pending booking
+func (s *Server) handleReviewBooking(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var review models.Review
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil && err != io.EOF {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		user, _ := userFromContext(r.Context())
+
+		approval, err := s.ReviewBooking(r.Context(), id, approve, user.Id, review.Reason)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, approval)
+		s.logger.Debug(fmt.Sprintf("Successefully %s booking {%d}", approval.Status, id))
+	}
+}
+
+// handleApproveBooking
+//
+// @Summary Approve pending booking
+// @Description Creates function which confirms pending booking specified by id and charges it to the balance of
+// @Description its user. Requires basic auth of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Booking ID"
+// @Param review body models.Review false "optional reason"
+//
+// @Success 200 {object} models.Approval "ok"
+// @Failure 400 {object} integer "Wrong ID or the booking does not require approval"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 402 {object} integer "Booking costs more than the balance of the user"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "Booking is not pending approval"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /approvals/{id}/approve [post]
+func (s *Server) handleApproveBooking() http.HandlerFunc {
+	return s.handleReviewBooking(true)
+}
+
+// handleRejectBooking
+//
+// @Summary Reject pending booking
+// @Description Creates function which cancels pending booking specified by id free of charge and offers its slot
+// @Description to the waitlist. The reason is required. Requires basic auth of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Booking ID"
+// @Param review body models.Review true "reason"
+//
+// @Success 200 {object} models.Approval "ok"
+// @Failure 400 {object} integer "Wrong ID, no reason, or the booking does not require approval"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "Booking is not pending approval"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /approvals/{id}/reject [post]
+func (s *Server) handleRejectBooking() http.HandlerFunc {
+	return s.handleReviewBooking(false)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+// addApprovalResource adds a resource called name whose bookings require approval and returns its id
+func addApprovalResource(t *testing.T, s *Server, name string) int {
+	t.Helper()
+	id := addTestResource(t, s, name, 1)
+	if _, err := s.database.Exec(context.Background(), "UPDATE resources SET requires_approval=TRUE WHERE id=$1", id); err != nil {
+		t.Fatalf("failed to require approval of %s: %s", name, err)
+	}
+	return id
+}
+
+func TestConcurrentPendingBookingsBlockSlot(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	users := []int{addTestUser(t, s, "member 1"), addTestUser(t, s, "member 2"), addTestUser(t, s, "member 3")}
+	resourceId := addApprovalResource(t, s, "hall")
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	bookings := make([]models.Booking, len(users))
+	errs := parallel(len(users), func(i int) error {
+		b := models.Booking{UserId: users[i], ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour), Text: "party"}
+		var err error
+		bookings[i], _, err = s.CreateBooking(ctx, b, false)
+		return err
+	})
+
+	pending := 0
+	for i, err := range errs {
+		switch {
+		case err == nil && bookings[i].Status == models.BookingPending:
+			pending++
+		case err == nil:
+			t.Errorf("booking %d is %s, want pending", bookings[i].Id, bookings[i].Status)
+		case errorKind(err) != KindConflict:
+			t.Errorf("CreateBooking failed: %s", err)
+		}
+	}
+	if pending != 1 {
+		t.Errorf("%d bookings are pending, want 1", pending)
+	}
+
+	counts := countRows(t, s, "bookings", "booking_approvals")
+	if want := []int{1, 1}; !slices.Equal(counts, want) {
+		t.Errorf("bookings and approvals = %v, want %v", counts, want)
+	}
+}
+
+func TestExpireApprovals(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addApprovalResource(t, s, "hall")
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	book := func(from time.Time) models.Booking {
+		t.Helper()
+		b := models.Booking{UserId: userId, ResourceId: resourceId, StartTime: from, EndTime: from.Add(time.Hour), Text: "party"}
+		b, _, err := s.CreateBooking(ctx, b, false)
+		if err != nil {
+			t.Fatalf("CreateBooking failed: %s", err)
+		}
+		return b
+	}
+	overdue, waiting := book(start), book(start.Add(2*time.Hour))
+
+	if _, err := s.database.Exec(ctx, "UPDATE booking_approvals SET deadline=NOW() - INTERVAL '1 minute' WHERE booking_id=$1", overdue.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected, err := s.expireApprovals(ctx)
+	if err != nil {
+		t.Fatalf("expireApprovals failed: %s", err)
+	}
+	if len(rejected) != 1 || rejected[0].Id != overdue.Id || rejected[0].Status != models.BookingCancelled {
+		t.Errorf("rejected = %+v, want cancelled booking %d", rejected, overdue.Id)
+	}
+
+	for _, tt := range []struct {
+		booking        models.Booking
+		status, review string
+	}{
+		{overdue, models.BookingCancelled, models.ApprovalRejected},
+		{waiting, models.BookingPending, models.ApprovalPending},
+	} {
+		b, err := getBooking(ctx, s.database, tt.booking.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := getApproval(ctx, s.database, tt.booking.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Status != tt.status || a.Status != tt.review {
+			t.Errorf("booking %d is %s with approval %s, want %s with %s", b.Id, b.Status, a.Status, tt.status, tt.review)
+		}
+	}
+
+	// the slot of the rejected booking is free again, while the pending one still blocks its own
+	if _, _, err := s.CreateBooking(ctx, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour), Text: "party"}, false); err != nil {
+		t.Errorf("booking the slot of the rejected booking failed: %s", err)
+	}
+	if _, _, err := s.CreateBooking(ctx, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: waiting.StartTime, EndTime: waiting.EndTime, Text: "party"}, false); errorKind(err) != KindConflict {
+		t.Errorf("booking the slot of the pending booking returned %v, want a conflict", err)
+	}
+
+	// a second run has nothing left to reject
+	if rejected, err := s.expireApprovals(ctx); err != nil || len(rejected) != 0 {
+		t.Errorf("second expireApprovals = %v, %v, want nothing rejected", rejected, err)
+	}
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+const (
+	// passwordCost is the bcrypt cost of new password hashes; hashes of a higher cost are
+	// replaced on the next successful login
+	passwordCost = bcrypt.DefaultCost
+	// credentialTTL is how long verified credentials are accepted without running bcrypt again
+	credentialTTL = 5 * time.Minute
+	// maxCredentials limits the number of cached credentials
+	maxCredentials = 10000
+)
+
+// credentialCache remembers credentials which matched a password hash recently, so that
+// every request of a client does not cost a bcrypt comparison. Entries are keyed by the
+// stored hash too, so changing the password invalidates them. The zero value is ready to use.
+type credentialCache struct {
+	mu      sync.Mutex
+	expires map[[sha256.Size]byte]time.Time
+}
+
+func credentialKey(hash, password string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(hash + "\x00" + password))
+}
+
+// verified reports whether password was checked against hash less than credentialTTL ago
+func (c *credentialCache) verified(hash, password string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.expires[credentialKey(hash, password)]
+	return ok && time.Now().Before(expires)
+}
+
+// add remembers that password matches hash
+func (c *credentialCache) add(hash, password string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.expires == nil {
+		c.expires = make(map[[sha256.Size]byte]time.Time)
+	}
+	if len(c.expires) >= maxCredentials {
+		maps.DeleteFunc(c.expires, func(_ [sha256.Size]byte, expires time.Time) bool { return !now.Before(expires) })
+		if len(c.expires) >= maxCredentials {
+			clear(c.expires)
+		}
+	}
+	c.expires[credentialKey(hash, password)] = now.Add(credentialTTL)
+}
+
+// Authenticate checks username and password against users table and returns the user if it has
+// one of roles. Other APIs than REST use it with the credentials they receive.
+func (s *Server) Authenticate(ctx context.Context, username, password string, roles ...string) (models.User, error) {
+	var user models.User
+	var hash string
+
+	query := "SELECT id, username, password, role, priority, created_at, updated_at FROM users WHERE username=$1"
+
+	err := s.database.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &hash, &user.Role, &user.Priority, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return user, newError(KindUnauthenticated, "Unauthorized: wrong username or password")
+	}
+
+	if !s.credentials.verified(hash, password) {
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			return user, newError(KindUnauthenticated, "Unauthorized: wrong username or password")
+		}
+		if cost, _ := bcrypt.Cost([]byte(hash)); cost > passwordCost {
+			hash = s.rehashPassword(ctx, user.Id, hash, password)
+		}
+		s.credentials.add(hash, password)
+	}
+
+	if !slices.Contains(roles, user.Role) {
+		return user, newError(KindForbidden, "Forbidden: role %s is not allowed to access this resource", user.Role)
+	}
+
+	return user, nil
+}
+
+// rehashPassword replaces hash of user userId, which is costlier than passwordCost, and returns
+// the new one. Failures are logged and leave the old hash in place.
+func (s *Server) rehashPassword(ctx context.Context, userId int, hash, password string) string {
+	rehashed, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to hash password; additional info: %s", err))
+		return hash
+	}
+
+	// the hash is compared so that a password changed meanwhile is kept
+	query := "UPDATE users SET password=$1 WHERE id=$2 AND password=$3"
+	if _, err := s.database.Exec(ctx, query, rehashed, userId, hash); err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+		return hash
+	}
+	return string(rehashed)
+}
+
+// requireRole serves next only to users authenticated with one of roles.
+// The authenticated user is available to next through userFromContext.
+func (s *Server) requireRole(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			s.writeError(w, newError(KindUnauthenticated, "Unauthorized: credentials are required"))
+			return
+		}
+
+		user, err := s.Authenticate(r.Context(), username, password, roles...)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		next(w, r.WithContext(ContextWithUser(r.Context(), user)))
+	}
+}
+
+// ContextWithUser returns ctx carrying user, who service methods authorize requests of
+func ContextWithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// userFromContext returns user authenticated by requireRole
+func userFromContext(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(userContextKey).(models.User)
+	return user, ok
+}
+
+// canAccessUser reports whether user may manage data of user userId:
+// members only their own, staff and admins of everybody
+func canAccessUser(user models.User, userId int) bool {
+	return user.Id == userId || user.Role == models.RoleStaff || user.Role == models.RoleAdmin
+}
+
+// AuthorizeUser returns an error unless the user authenticated in ctx may manage data of user userId
+func AuthorizeUser(ctx context.Context, userId int) error {
+	user, ok := userFromContext(ctx)
+	if !ok {
+		return newError(KindUnauthenticated, "Unauthorized: credentials are required")
+	}
+	if !canAccessUser(user, userId) {
+		return newError(KindForbidden, "Forbidden: members can only manage their own bookings")
+	}
+	return nil
+}
+
+// AuthorizeAccount returns an error unless the user authenticated in ctx may change or delete
+// the account of user userId: users only their own, admins everybody's
+func AuthorizeAccount(ctx context.Context, userId int) error {
+	user, ok := userFromContext(ctx)
+	if !ok {
+		return newError(KindUnauthenticated, "Unauthorized: credentials are required")
+	}
+	if user.Id != userId && user.Role != models.RoleAdmin {
+		return newError(KindForbidden, "Forbidden: users can only manage their own account")
+	}
+	return nil
+}
+
+// AuthorizeBooking returns an error unless the user authenticated in ctx may manage booking
+// specified by id. Missing bookings are left to the service method to report.
+func (s *Server) AuthorizeBooking(ctx context.Context, id int) error {
+	if _, ok := userFromContext(ctx); !ok {
+		return newError(KindUnauthenticated, "Unauthorized: credentials are required")
+	}
+
+	booking, err := s.GetBooking(ctx, id)
+	if errorKind(err) == KindNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return AuthorizeUser(ctx, booking.UserId)
+}
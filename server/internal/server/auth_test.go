@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestAuthorizeAccount(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *models.User
+		userId int
+		// want is the kind of the error, nil if the change is allowed
+		want *ErrorKind
+	}{
+		{"anonymous", nil, 1, ptr(KindUnauthenticated)},
+		{"own account", &models.User{Id: 1, Role: models.RoleMember}, 1, nil},
+		{"another member", &models.User{Id: 2, Role: models.RoleMember}, 1, ptr(KindForbidden)},
+		{"staff", &models.User{Id: 2, Role: models.RoleStaff}, 1, ptr(KindForbidden)},
+		{"admin", &models.User{Id: 2, Role: models.RoleAdmin}, 1, nil},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.user != nil {
+			ctx = ContextWithUser(ctx, *tt.user)
+		}
+
+		err := AuthorizeAccount(ctx, tt.userId)
+		switch {
+		case tt.want == nil && err != nil:
+			t.Errorf("%s: AuthorizeAccount returned %s", tt.name, err)
+		case tt.want != nil && (err == nil || errorKind(err) != *tt.want):
+			t.Errorf("%s: AuthorizeAccount returned %v, want kind %d", tt.name, err, *tt.want)
+		}
+	}
+}
+
+func TestCredentialCache(t *testing.T) {
+	var c credentialCache
+	if c.verified("hash", "secret") {
+		t.Fatal("empty cache verified credentials")
+	}
+
+	c.add("hash", "secret")
+	if !c.verified("hash", "secret") {
+		t.Error("cached credentials were not verified")
+	}
+	if c.verified("hash", "other") {
+		t.Error("another password was verified")
+	}
+	// a changed password has another hash
+	if c.verified("new hash", "secret") {
+		t.Error("credentials of an old hash were verified")
+	}
+
+	c.expires[credentialKey("hash", "secret")] = time.Now().Add(-time.Second)
+	if c.verified("hash", "secret") {
+		t.Error("expired credentials were verified")
+	}
+}
@@ -7,25 +7,481 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
 	"github.com/alexey-dobry/booking-service/server/internal/validator"
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5"
 )
 
+// CreateBooking validates b and books its time slot. If the slot is taken and waitlist is set,
+// the request is queued instead and the waitlist entry is returned. Bookings of resources which
+// require approval are created pending and queued for review by staff.
+func (s *Server) CreateBooking(ctx context.Context, b models.Booking, waitlist bool) (models.Booking, *models.WaitlistEntry, error) {
+	if err := validator.V.Struct(b); err != nil {
+		return b, nil, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	if !b.EndTime.After(b.StartTime) {
+		return b, nil, newError(KindInvalid, "TimeError: end_time is before start_time")
+	}
+
+	if b.ResourceId == 0 {
+		var err error
+		if b.ResourceId, err = s.defaultResourceId(ctx, s.database); err != nil {
+			return b, nil, err
+		}
+	}
+
+	if b.PartySize == 0 {
+		b.PartySize = 1
+	}
+	if err := checkPartySize(ctx, s.database, b.ResourceId, b.PartySize); err != nil {
+		return b, nil, err
+	}
+
+	if err := s.validateSchedule(ctx, s.database, b.ResourceId, b.StartTime, b.EndTime); err != nil {
+		return b, nil, err
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return b, nil, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockResource(ctx, tx, b.ResourceId); err != nil {
+		return b, nil, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	if err := s.validatePolicy(ctx, tx, b); err != nil {
+		return b, nil, err
+	}
+
+	conflictId, err := findOverlap(ctx, tx, b.ResourceId, b.StartTime, b.EndTime, b.PartySize, 0)
+	if err != nil {
+		return b, nil, newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+	}
+	if conflictId != 0 && waitlist {
+		entry := models.WaitlistEntry{
+			UserId:     b.UserId,
+			ResourceId: b.ResourceId,
+			StartTime:  b.StartTime,
+			EndTime:    b.EndTime,
+			PartySize:  b.PartySize,
+			Text:       b.Text,
+		}
+
+		if err := s.addToWaitlist(ctx, tx, &entry); err != nil {
+			return b, nil, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return b, nil, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+		}
+		return b, &entry, nil
+	}
+	if conflictId != 0 {
+		return b, nil, newError(KindConflict, "Time slot is already taken by booking with id {%d}", conflictId)
+	}
+
+	if err := s.priceBooking(ctx, tx, &b); errorKind(err) == KindInvalid {
+		return b, nil, err
+	} else if err != nil {
+		return b, nil, newError(KindInternal, "Failed to calculate price; additional info: %s", err)
+	}
+
+	if b.Status, err = newBookingStatus(ctx, tx, b.ResourceId); err != nil {
+		return b, nil, newError(KindInternal, "Failed to check resource; additional info: %s", err)
+	}
+	// pending bookings are only charged once they are approved, but must be affordable already
+	if b.Status == models.BookingPending {
+		if err := s.checkFunds(ctx, tx, b.UserId, b.Price); err != nil {
+			return b, nil, err
+		}
+	}
+
+	query := "INSERT INTO bookings (user_id,resource_id,start_time,end_time,party_size,text,status,price,price_breakdown) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING " + bookingColumns
+
+	if err := scanBooking(tx.QueryRow(ctx, query, b.UserId, b.ResourceId, b.StartTime, b.EndTime, b.PartySize, b.Text, b.Status, b.Price, b.PriceBreakdown), &b); err != nil {
+		return b, nil, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+
+	if err := requestApproval(ctx, tx, b); err != nil {
+		return b, nil, newError(KindInternal, "Failed to request approval; additional info: %s", err)
+	}
+
+	if err := saveRedemption(ctx, tx, b); err != nil {
+		return b, nil, newError(KindInternal, "Failed to redeem promo code; additional info: %s", err)
+	}
+
+	if err := s.chargeBooking(ctx, tx, b); err != nil {
+		return b, nil, err
+	}
+
+	if err := s.publish(ctx, tx, outbox.BookingCreated, b); err != nil {
+		return b, nil, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return b, nil, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return b, nil, nil
+}
+
+// defaultResourceId returns the resource booked by requests which name none: the configured
+// one, or the general resource which bookings made before there were resources were moved to
+func (s *Server) defaultResourceId(ctx context.Context, q querier) (int, error) {
+	if s.defaultResource != 0 {
+		return s.defaultResource, nil
+	}
+
+	var id int
+	err := q.QueryRow(ctx, "SELECT id FROM resources WHERE name='general'").Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, newError(KindInvalid, "Incorrect input data: resource_id is required, the venue has no default resource")
+	} else if err != nil {
+		return 0, newError(KindInternal, "Failed to check resource; additional info: %s", err)
+	}
+	return id, nil
+}
+
+// GetBooking returns booking specified by id
+func (s *Server) GetBooking(ctx context.Context, id int) (models.Booking, error) {
+	booking, err := getBooking(ctx, s.database, id)
+	if err == pgx.ErrNoRows {
+		return booking, notFound(id)
+	} else if err != nil {
+		return booking, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	return booking, nil
+}
+
+// ListBookings returns bookings ordered by id; cancelled ones only with includeCancelled
+func (s *Server) ListBookings(ctx context.Context, includeCancelled bool) ([]models.Booking, error) {
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE $1 OR " + activeBooking + " ORDER BY id"
+	data, err := s.database.Query(ctx, query, includeCancelled)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+
+	bookingList, err := collectBookings(data)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+	return bookingList, nil
+}
+
+// bookingFilterCondition renders f as an sql condition, numbering its arguments after those in args
+func bookingFilterCondition(f models.BookingFilter, args []any) (string, []any) {
+	conditions := []string{"TRUE"}
+
+	if f.ResourceId != 0 {
+		args = append(args, f.ResourceId)
+		conditions = append(conditions, fmt.Sprintf("resource_id=$%d", len(args)))
+	}
+	if len(f.Statuses) > 0 {
+		args = append(args, f.Statuses)
+		conditions = append(conditions, fmt.Sprintf("status=ANY($%d)", len(args)))
+	} else if !f.IncludeCancelled {
+		conditions = append(conditions, activeBooking)
+	}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		conditions = append(conditions, fmt.Sprintf("end_time > $%d", len(args)))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		conditions = append(conditions, fmt.Sprintf("start_time < $%d", len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// FindBookings returns a page of bookings matching f ordered by start time
+func (s *Server) FindBookings(ctx context.Context, f models.BookingFilter) ([]models.Booking, error) {
+	if err := validator.V.Struct(f); err != nil {
+		return nil, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	condition, args := bookingFilterCondition(f, []any{f.Limit, f.Offset})
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE " + condition + " ORDER BY start_time, id LIMIT $1 OFFSET $2"
+
+	rows, err := s.database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	bookingList, err := collectBookings(rows)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+	return bookingList, nil
+}
+
+// BookingsOfUsers returns bookings of each of userIds matching f in a single query. The page
+// is applied to bookings of every user separately.
+func (s *Server) BookingsOfUsers(ctx context.Context, userIds []int, f models.BookingFilter) (map[int][]models.Booking, error) {
+	if err := validator.V.Struct(f); err != nil {
+		return nil, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	condition, args := bookingFilterCondition(f, []any{userIds, f.Offset, f.Offset + f.Limit})
+	query := "SELECT " + bookingColumns + " FROM (SELECT *, row_number() OVER (PARTITION BY user_id ORDER BY start_time, id) AS n " +
+		"FROM bookings WHERE user_id=ANY($1) AND " + condition + ") b WHERE n > $2 AND n <= $3 ORDER BY start_time, id"
+
+	rows, err := s.database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	bookingList, err := collectBookings(rows)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+
+	byUser := make(map[int][]models.Booking, len(userIds))
+	for _, b := range bookingList {
+		byUser[b.UserId] = append(byUser[b.UserId], b)
+	}
+	return byUser, nil
+}
+
+// UpdateBooking moves booking specified by id to the resource and times set in b and changes
+// its party size and text if set. The changed slot is checked like a new booking.
+func (s *Server) UpdateBooking(ctx context.Context, id int, b models.Booking) (models.Booking, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return b, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := getBooking(ctx, tx, id)
+	if err == pgx.ErrNoRows {
+		return b, notFound(id)
+	} else if err != nil {
+		return b, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	if current.Status == models.BookingCancelled {
+		return b, newError(KindInvalid, "Booking {%d} is cancelled", id)
+	}
+
+	if !b.StartTime.IsZero() {
+		current.StartTime = b.StartTime
+	}
+	if !b.EndTime.IsZero() {
+		current.EndTime = b.EndTime
+	}
+	oldResourceId := current.ResourceId
+	if b.ResourceId != 0 {
+		current.ResourceId = b.ResourceId
+	}
+	oldPartySize := current.PartySize
+	if b.PartySize < 0 {
+		return b, newError(KindInvalid, "Incorrect input data: party_size must be positive")
+	} else if b.PartySize != 0 {
+		current.PartySize = b.PartySize
+	}
+
+	if !current.EndTime.After(current.StartTime) {
+		return b, newError(KindInvalid, "TimeError: end_time is before start_time")
+	}
+
+	// moving a booking must not skip the review of a resource which requires approval
+	if current.ResourceId != oldResourceId && current.Status != models.BookingPending {
+		status, err := newBookingStatus(ctx, tx, current.ResourceId)
+		if err != nil {
+			return b, newError(KindInternal, "Failed to check resource; additional info: %s", err)
+		}
+		if status == models.BookingPending {
+			return b, newError(KindInvalid, "ApprovalError: resource {%d} requires approval, book it anew", current.ResourceId)
+		}
+	}
+
+	if err := checkPartySize(ctx, tx, current.ResourceId, current.PartySize); err != nil {
+		return b, err
+	}
+
+	if err := s.validateSchedule(ctx, tx, current.ResourceId, current.StartTime, current.EndTime); err != nil {
+		return b, err
+	}
+
+	if err := lockResources(ctx, tx, oldResourceId, current.ResourceId); err != nil {
+		return b, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	rescheduled := !b.StartTime.IsZero() || !b.EndTime.IsZero() || b.ResourceId != 0
+	if rescheduled {
+		if err := s.validatePolicy(ctx, tx, current); err != nil {
+			return b, err
+		}
+	}
+
+	conflictId, err := findOverlap(ctx, tx, current.ResourceId, current.StartTime, current.EndTime, current.PartySize, id)
+	if err != nil {
+		return b, newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+	}
+	if conflictId != 0 {
+		return b, newError(KindConflict, "Time slot is already taken by booking with id {%d}", conflictId)
+	}
+
+	if b.Text != "" {
+		if err := validator.V.Var(b.Text, "required,min=6,max=100,excludes=/\\#@$"); err != nil {
+			return b, newError(KindInvalid, "Incorrect input data: %s", err)
+		}
+		current.Text = b.Text
+	}
+
+	// the price is only recalculated when the slot or the party changes, so that tariff changes
+	// alone do not affect it
+	repriced := rescheduled || current.PartySize != oldPartySize
+	if repriced {
+		if err := s.priceBooking(ctx, tx, &current); errorKind(err) == KindInvalid {
+			return b, err
+		} else if err != nil {
+			return b, newError(KindInternal, "Failed to calculate price; additional info: %s", err)
+		}
+	}
+
+	query := "UPDATE bookings SET resource_id=$2, start_time=$3, end_time=$4, party_size=$5, text=$6, price=$7, price_breakdown=$8, overridden=series_id IS NOT NULL, sequence=sequence+1, updated_at=NOW() WHERE id=$1 RETURNING " + bookingColumns
+
+	err = scanBooking(tx.QueryRow(ctx, query, id, current.ResourceId, current.StartTime, current.EndTime, current.PartySize, current.Text, current.Price, current.PriceBreakdown), &current)
+	if err != nil {
+		return b, newError(KindInternal, "Failed to execute sql command; additional info:%s", err)
+	}
+
+	// a pending booking moved to an earlier start must still be reviewed before it
+	if current.Status == models.BookingPending {
+		if _, err := tx.Exec(ctx, "UPDATE booking_approvals SET deadline=LEAST(deadline, $2) WHERE booking_id=$1", id, current.StartTime); err != nil {
+			return b, newError(KindInternal, "Failed to execute sql command; additional info:%s", err)
+		}
+	}
+
+	if repriced {
+		if err := saveRedemption(ctx, tx, current); err != nil {
+			return b, newError(KindInternal, "Failed to redeem promo code; additional info: %s", err)
+		}
+		if err := s.chargeBooking(ctx, tx, current); err != nil {
+			return b, err
+		}
+	}
+
+	// moving or shortening a booking may free time somebody is waiting for
+	if _, err := s.promoteWaitlist(ctx, tx, oldResourceId); err != nil {
+		return b, newError(KindInternal, "Failed to process waitlist; additional info: %s", err)
+	}
+
+	if err := s.publish(ctx, tx, outbox.BookingUpdated, current); err != nil {
+		return b, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return b, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return current, nil
+}
+
+// CancelBooking cancels booking specified by id, refunds what was paid for it less the fee of the
+// cancellation rules and offers its slot to the waitlist. Held and pending bookings are cancelled free of charge.
+// Cancelling a booking which does not exist or is already cancelled is not an error and returns a
+// zero Cancellation.
+func (s *Server) CancelBooking(ctx context.Context, id int) (models.Cancellation, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	booking, err := getBooking(ctx, tx, id)
+	if err == pgx.ErrNoRows || booking.Status == models.BookingCancelled {
+		return models.Cancellation{}, nil
+	} else if err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to delete specified booking; additional info: %s", err)
+	}
+
+	if err := lockResource(ctx, tx, booking.ResourceId); err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	// deleting a held booking declines the waitlist offer it was created for
+	if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$2 WHERE booking_id=$1 AND status=$3", id, models.WaitlistCancelled, models.WaitlistOffered); err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to delete specified booking; additional info: %s", err)
+	}
+
+	fee := lateCancellationFee(tx, time.Now())
+	if booking.Status == models.BookingHeld || booking.Status == models.BookingPending {
+		fee = nil
+	}
+
+	cancelled, cancellations, err := cancelAndRefund(ctx, tx, fee, "id=$1", id)
+	if err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to delete specified booking; additional info: %s", err)
+	}
+
+	if _, err := s.promoteWaitlist(ctx, tx, booking.ResourceId); err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to process waitlist; additional info: %s", err)
+	}
+
+	if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled); err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Cancellation{}, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	if len(cancellations) == 0 {
+		return models.Cancellation{}, nil
+	}
+	return cancellations[0], nil
+}
+
+// PurgeBookings permanently deletes bookings which ended before before, cancelled or not, and
+// returns their number. Nothing is deleted with dryRun. No events are published: purged bookings
+// are history, not changes of availability.
+func (s *Server) PurgeBookings(ctx context.Context, before time.Time, dryRun bool) (int, error) {
+	if before.IsZero() {
+		return 0, newError(KindInvalid, "Incorrect input data: time to purge bookings before is required")
+	}
+
+	query := "DELETE FROM bookings WHERE end_time < $1"
+	if dryRun {
+		query = "SELECT count(*) FROM bookings WHERE end_time < $1"
+
+		var count int
+		if err := s.database.QueryRow(ctx, query, before).Scan(&count); err != nil {
+			return 0, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+		}
+		return count, nil
+	}
+
+	tag, err := s.database.Exec(ctx, query, before)
+	if err != nil {
+		return 0, newError(KindInternal, "Failed to delete data from database; additional info: %s", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
 // handleAddBooking
 //
 // @Summary Adds new booking entry
-// @Description Creates function which adds new user data to database
+// @Description Creates function which adds new user data to database. Bookings of resources which require approval
+// @Description are created pending: they block the slot and are charged once staff approve them.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
 // @Accept json
 //
 // @Param UserId formData int true "integer >= 1"
-// @Param StartTime formData string true "format = YYYY-MM-DD HH:MM:SS"
-// @Param EndTime formData string true "format = YYYY-MM-DD HH:MM:SS"
+// @Param ResourceId formData int false "integer >= 1, the default resource (DEFAULT_RESOURCE_ID) if omitted"
+// @Param StartTime formData string true "RFC 3339, e.g. 2025-03-01T14:00:00+03:00"
+// @Param EndTime formData string true "RFC 3339, e.g. 2025-03-01T16:00:00+03:00"
+// @Param PromoCode formData string false "promo code to redeem, e.g. SPRING25"
+// @Param waitlist query bool false "join the waitlist instead of failing when the slot is taken"
 //
 // @Success 200 {object} integer "ok"
-// @Failure 400 {object} integer "Wrong ID"
+// @Success 202 {object} models.WaitlistEntry "slot is taken, request was added to waitlist"
+// @Failure 400 {array} policy.Violation "Wrong ID, time breaks opening hours, holiday or maintenance rule, promo code can not be redeemed, or booking breaks policy rules (list of violations)"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 402 {object} integer "Booking costs more than the balance of the user"
+// @Failure 409 {object} integer "Time slot is already taken"
 // @Failure 500 {object} integer "Error scanning data from db response"
 // @Router /booking [post]
 func (s *Server) handleAddBooking() http.HandlerFunc {
@@ -40,24 +496,25 @@ func (s *Server) handleAddBooking() http.HandlerFunc {
 			return
 		}
 
-		if err := validator.V.Struct(newBooking); err != nil {
-			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
-			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+		if err := AuthorizeUser(r.Context(), newBooking.UserId); err != nil {
+			s.writeError(w, err)
 			return
 		}
 
-		if err := newBooking.EndTime.After(newBooking.StartTime); err != true {
-			http.Error(w, "TimeError: end_time is before start_time", http.StatusBadRequest)
-			s.logger.Debug("TimeError: end_time is before start_time")
+		_, entry, err := s.CreateBooking(r.Context(), newBooking, r.URL.Query().Get("waitlist") == "true")
+		if errorKind(err) == KindConflict {
+			http.Error(w, fmt.Sprintf("%s; retry with ?waitlist=true to join the waitlist", err), http.StatusConflict)
+			s.logger.Debug(err.Error())
+			return
+		} else if err != nil {
+			s.writeError(w, err)
 			return
 		}
 
-		query := "INSERT INTO bookings (user_id,start_time,end_time,text) VALUES ($1,$2,$3,$4)"
-
-		_, err := s.database.Exec(context.Background(), query, newBooking.UserId, newBooking.StartTime, newBooking.EndTime, newBooking.Text)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+		if entry != nil {
+			w.WriteHeader(http.StatusAccepted)
+			writeJSON(w, r, entry)
+			s.logger.Debug("Time slot is taken, booking request was added to waitlist")
 			return
 		}
 
@@ -83,24 +540,13 @@ func (s *Server) handleGetBooking() http.HandlerFunc {
 
 		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-		var Booking models.Booking
-
-		query := "SELECT * FROM bookings WHERE id=$1"
-
-		data := s.database.QueryRow(context.Background(), query, id)
-
-		err := data.Scan(&Booking.Id, &Booking.UserId, &Booking.StartTime, &Booking.EndTime, &Booking.Text)
-		if err == pgx.ErrNoRows {
-			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
-			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
-			return
-		} else if err != nil {
-			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+		Booking, err := s.GetBooking(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(Booking)
+		writeJSON(w, r, Booking)
 		s.logger.Debug("Successfully retrieved booking data")
 	}
 }
@@ -111,6 +557,8 @@ func (s *Server) handleGetBooking() http.HandlerFunc {
 // @Description Creates function which retrieves data of all bookings from database
 // @Produces json
 //
+// @Param include_cancelled query bool false "also return cancelled bookings"
+//
 // @Success 200 {array} models.Booking "ok"
 // @Success 200 {object} integer "no content"
 // @Failure 500 {object} integer "Error scanning data from db response"
@@ -119,33 +567,18 @@ func (s *Server) handleGetBookings() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		var bookingList []models.Booking
-
-		query := "SELECT id, user_id, start_time, end_time, text FROM bookings"
-		data, err := s.database.Query(context.Background(), query)
+		bookingList, err := s.ListBookings(r.Context(), r.URL.Query().Get("include_cancelled") == "true")
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			s.writeError(w, err)
 			return
 		}
 
-		for data.Next() {
-			var Booking models.Booking
-			err = data.Scan(&Booking.Id, &Booking.UserId, &Booking.StartTime, &Booking.EndTime, &Booking.Text)
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
-				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
-				return
-			}
-			bookingList = append(bookingList, Booking)
-		}
-
 		if len(bookingList) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(bookingList)
+		writeJSON(w, r, bookingList)
 		s.logger.Debug("Successfully retrieved bookings data")
 	}
 }
@@ -153,13 +586,18 @@ func (s *Server) handleGetBookings() http.HandlerFunc {
 // handleUpdateBooking
 //
 // @Summary Updates booking data
-// @Description Creates function which updates data of booking specified by id in database
+// @Description Creates function which updates data of booking specified by id in database.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
 // @Accept json
 //
 // @Param id path int true "Booking ID"
 //
 // @Success 200 {object} integer "ok"
-// @Failure 400 {object} integer "Wrong Id"
+// @Failure 400 {array} policy.Violation "Wrong Id, time breaks opening hours, holiday or maintenance rule, or booking breaks policy rules (list of violations)"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 402 {object} integer "Booking costs more than the balance of the user"
+// @Failure 409 {object} integer "Time slot is already taken"
 // @Failure 500 {object} integer "Error scanning data from db response"
 // @Router /booking/{id} [put]
 func (s *Server) handleUpdateBooking() http.HandlerFunc {
@@ -176,35 +614,13 @@ func (s *Server) handleUpdateBooking() http.HandlerFunc {
 			return
 		}
 
-		var builder strings.Builder
-
-		if newBookingData.EndTime.String() != "0001-01-01 00:00:00 +0000 UTC" {
-			builder.WriteString("end_time='")
-			builder.WriteString(newBookingData.EndTime.Format("2006-01-02 15:04:05.000"))
-			builder.WriteString("',")
-		}
-		if newBookingData.StartTime.String() != "0001-01-01 00:00:00 +0000 UTC" {
-			builder.WriteString("start_time='")
-			builder.WriteString(newBookingData.StartTime.Format("2006-01-02 15:04:05.000"))
-			builder.WriteString("',")
-		}
-		if newBookingData.Text != "" {
-			if err := validator.V.Var(newBookingData.Text, "required,min=6,max=100,excludes=/\\#@$"); err != nil {
-				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
-				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
-				return
-			}
-			builder.WriteString("text='")
-			builder.WriteString(newBookingData.Text)
-			builder.WriteString("',")
+		if err := s.AuthorizeBooking(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
 		}
 
-		query := fmt.Sprintf("UPDATE bookings SET %s WHERE id=$1", builder.String()[:len(builder.String())-1])
-
-		_, err := s.database.Exec(context.Background(), query, id)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to execute sql command; additional info:%s; querystr: %s", err, query), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to execute sql command; additional info:%s", err))
+		if _, err := s.UpdateBooking(r.Context(), id, newBookingData); err != nil {
+			s.writeError(w, err)
 			return
 		}
 
@@ -215,29 +631,42 @@ func (s *Server) handleUpdateBooking() http.HandlerFunc {
 
 // handleDeleteBooking
 //
-// @Summary Delete specified booking data
-// @Description Creates function which deletes data of booking specified by id from database
+// @Summary Cancel specified booking
+// @Description Creates function which cancels booking specified by id. The booking is kept with status cancelled,
+// @Description so calendar feeds can report the cancellation, and no longer blocks its time slot. A booking cancelled
+// @Description late is charged the fee of the cancellation rules; the response reports the fee, the refund to the
+// @Description prepaid balance and the reason. Cancelling a missing or cancelled booking responds with an empty body.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
+// @Produces json
 //
 // @Param id path int true "Booking ID"
 //
-// @Success 200 {object} integer "ok"
+// @Success 200 {object} models.Cancellation "ok"
 // @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
 // @Router /booking/{id} [delete]
 func (s *Server) handleDeleteBooking() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		id := mux.Vars(r)["id"]
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		if err := s.AuthorizeBooking(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
 
-		query := "DELETE FROM bookings WHERE id=$1"
-		_, err := s.database.Exec(context.Background(), query, id)
+		cancellation, err := s.CancelBooking(r.Context(), id)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to delete specified booking; additional info: %s", err), http.StatusBadRequest)
-			s.logger.Error(fmt.Sprintf("Failed to delete specified booking; additional info: %s", err))
+			s.writeError(w, err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
+		if cancellation.BookingId != 0 {
+			writeJSON(w, r, cancellation)
+		}
 		s.logger.Debug("Successefully deleted specified booking data from database")
 	}
 }
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultResourceId(t *testing.T) {
+	s := &Server{defaultResource: 5}
+	if id, err := s.defaultResourceId(context.Background(), nil); err != nil || id != 5 {
+		t.Errorf("configured default = %d, %v, want 5", id, err)
+	}
+
+	s = testServer(t, false)
+	ctx := context.Background()
+	if _, err := s.defaultResourceId(ctx, s.database); errorKind(err) != KindInvalid {
+		t.Errorf("default without a general resource returned %v, want invalid input", err)
+	}
+
+	general := addTestResource(t, s, "general", 1)
+	if id, err := s.defaultResourceId(ctx, s.database); err != nil || id != general {
+		t.Errorf("default = %d, %v, want the general resource %d", id, err, general)
+	}
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/bulk"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// importRecords decodes records of type T from r and passes each to insert. Every record is
+// inserted in its own savepoint, so a failed record does not abort the import transaction,
+// and later records are checked against earlier ones of the same file.
+func importRecords[T any](ctx context.Context, db *pgxpool.Pool, r io.Reader, opts models.ImportOptions, insert func(context.Context, pgx.Tx, *T) error) (models.ImportResult, error) {
+	result := models.ImportResult{Mode: opts.Mode, DryRun: opts.DryRun, Errors: []models.RecordError{}}
+	if result.Mode == "" {
+		result.Mode = models.ImportModeAllOrNothing
+	}
+	if result.Mode != models.ImportModeAllOrNothing && result.Mode != models.ImportModeBestEffort {
+		return result, fmt.Errorf("unknown mode %q, expected all_or_nothing or best_effort", result.Mode)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	decoder := bulk.NewDecoder(r, opts.Format)
+	for {
+		var record T
+
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		var recordErr *bulk.RecordError
+		if errors.As(err, &recordErr) {
+			result.Total++
+			result.Errors = append(result.Errors, models.RecordError{Line: recordErr.Line, Error: recordErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		result.Total++
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return result, err
+		}
+		if err := insert(ctx, savepoint, &record); err != nil {
+			savepoint.Rollback(ctx)
+			result.Errors = append(result.Errors, models.RecordError{Line: decoder.Line(), Error: err.Error()})
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return result, err
+		}
+		result.Valid++
+	}
+
+	if opts.DryRun || (result.Mode == models.ImportModeAllOrNothing && len(result.Errors) > 0) {
+		return result, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	result.Imported = result.Valid
+	return result, nil
+}
+
+// ImportUsers creates users from records read from r
+func (s *Server) ImportUsers(ctx context.Context, r io.Reader, opts models.ImportOptions) (models.ImportResult, error) {
+	return importRecords(ctx, s.database, r, opts, func(ctx context.Context, tx pgx.Tx, u *models.UserRecord) error {
+		if err := validator.V.Struct(u); err != nil {
+			return err
+		}
+
+		password := []byte(u.PasswordHash)
+		if u.PasswordHash == "" {
+			var err error
+			if password, err = bcrypt.GenerateFromPassword([]byte(u.Password), passwordCost); err != nil {
+				return err
+			}
+		} else if _, err := bcrypt.Cost(password); err != nil {
+			return fmt.Errorf("password_hash: %w", err)
+		}
+
+		if u.Role == "" {
+			u.Role = models.RoleMember
+		}
+		if u.CreatedAt.IsZero() {
+			u.CreatedAt = time.Now()
+		}
+		if u.UpdatedAt.IsZero() {
+			u.UpdatedAt = u.CreatedAt
+		}
+
+		query := "INSERT INTO users (username,password,role,priority,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
+		if err := tx.QueryRow(ctx, query, u.Username, password, u.Role, u.Priority, u.CreatedAt, u.UpdatedAt).Scan(&u.Id); err != nil {
+			return err
+		}
+		return s.publishUser(ctx, tx, outbox.UserCreated, u.Id)
+	})
+}
+
+// ImportBookings creates bookings from records read from r. Records are validated and checked
+// for overlaps like bookings created one by one, but opening hours and policy rules are not
+// applied, so that past bookings can be migrated. Records with a price breakdown, e.g. exported ones,
+// keep their price, others are priced by the current tariffs.
+func (s *Server) ImportBookings(ctx context.Context, r io.Reader, opts models.ImportOptions) (models.ImportResult, error) {
+	return importRecords(ctx, s.database, r, opts, func(ctx context.Context, tx pgx.Tx, b *models.Booking) error {
+		if err := validator.V.Struct(b); err != nil {
+			return err
+		}
+		if !b.EndTime.After(b.StartTime) {
+			return errors.New("TimeError: end_time is before start_time")
+		}
+
+		if b.ResourceId == 0 {
+			var err error
+			if b.ResourceId, err = s.defaultResourceId(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		if b.PartySize == 0 {
+			b.PartySize = 1
+		}
+
+		switch b.Status {
+		case "":
+			b.Status = models.BookingConfirmed
+		case models.BookingConfirmed, models.BookingCancelled:
+		default:
+			return fmt.Errorf("status: only %s and %s bookings can be imported", models.BookingConfirmed, models.BookingCancelled)
+		}
+
+		if b.Status == models.BookingConfirmed {
+			if err := lockResource(ctx, tx, b.ResourceId); err != nil {
+				return err
+			}
+			conflictId, err := findOverlap(ctx, tx, b.ResourceId, b.StartTime, b.EndTime, b.PartySize, 0)
+			if err != nil {
+				return err
+			}
+			if conflictId != 0 {
+				return fmt.Errorf("time slot is already taken by booking with id {%d}", conflictId)
+			}
+		}
+
+		// promo codes are only redeemed by bookings made through the API
+		b.PromoCode = ""
+		if b.PriceBreakdown == nil {
+			if err := s.priceBooking(ctx, tx, b); err != nil {
+				return err
+			}
+		}
+
+		query := "INSERT INTO bookings (user_id,resource_id,start_time,end_time,party_size,text,status,price,price_breakdown,cancellation_fee) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING " + bookingColumns
+		if err := scanBooking(tx.QueryRow(ctx, query, b.UserId, b.ResourceId, b.StartTime, b.EndTime, b.PartySize, b.Text, b.Status, b.Price, b.PriceBreakdown, b.CancellationFee), b); err != nil {
+			return err
+		}
+		return s.publish(ctx, tx, outbox.BookingCreated, b)
+	})
+}
+
+// ExportUsers writes all users to w, including password hashes so that they can be imported again
+func (s *Server) ExportUsers(ctx context.Context, w io.Writer, format string) error {
+	rows, err := s.database.Query(ctx, "SELECT id, username, password, role, priority, created_at, updated_at FROM users ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := bulk.NewEncoder(w, format, s.location)
+	for rows.Next() {
+		var u models.UserRecord
+		if err := rows.Scan(&u.Id, &u.Username, &u.PasswordHash, &u.Role, &u.Priority, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return err
+		}
+		if err := encoder.Encode(u); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// ExportBookings writes all bookings, including cancelled ones, to w
+func (s *Server) ExportBookings(ctx context.Context, w io.Writer, format string) error {
+	rows, err := s.database.Query(ctx, "SELECT "+bookingColumns+" FROM bookings ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := bulk.NewEncoder(w, format, s.location)
+	for rows.Next() {
+		var b models.Booking
+		if err := scanBooking(rows, &b); err != nil {
+			return err
+		}
+		if err := encoder.Encode(b); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// importOptions reads format, mode and dry_run query parameters
+func importOptions(r *http.Request) (models.ImportOptions, error) {
+	format, err := bulk.ParseFormat(r.URL.Query().Get("format"), r.Header.Get("Content-Type"))
+	return models.ImportOptions{Format: format, Mode: r.URL.Query().Get("mode"), DryRun: r.URL.Query().Get("dry_run") == "true"}, err
+}
+
+// handleImport serves an import with importer
+func (s *Server) handleImport(importer func(context.Context, io.Reader, models.ImportOptions) (models.ImportResult, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		opts, err := importOptions(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		result, err := importer(r.Context(), r.Body, opts)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to import data; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to import data; additional info: %s", err))
+			return
+		}
+
+		if result.Mode == models.ImportModeAllOrNothing && len(result.Errors) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		writeJSON(w, r, result)
+		s.logger.Debug(fmt.Sprintf("Import finished: %d records, %d valid, %d imported", result.Total, result.Valid, result.Imported))
+	}
+}
+
+// handleExport serves an export with exporter
+func (s *Server) handleExport(name string, exporter func(context.Context, io.Writer, string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, err := bulk.ParseFormat(r.URL.Query().Get("format"), "")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if format == bulk.FormatCSV {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+		// the response is streamed, so errors can only be logged
+		if err := exporter(r.Context(), w, format); err != nil {
+			s.logger.Error(fmt.Sprintf("Failed to export data; additional info: %s", err))
+			return
+		}
+		s.logger.Debug(fmt.Sprintf("Successfully exported %s", name))
+	}
+}
+
+// handleImportUsers
+//
+// @Summary Import users
+// @Description Creates function which creates users from a CSV (with header) or NDJSON file. Each record is
+// @Description validated like POST /user; the response lists errors of rejected records by line.
+// @Description Requires basic auth of an admin.
+// @Accept text/csv,application/x-ndjson
+// @Produces json
+//
+// @Param format query string false "csv | ndjson, by default taken from Content-Type"
+// @Param mode query string false "all_or_nothing (default) | best_effort"
+// @Param dry_run query bool false "only validate records"
+// @Param records body models.UserRecord true "records"
+//
+// @Success 200 {object} models.ImportResult "ok"
+// @Failure 400 {object} models.ImportResult "Some records are invalid, nothing was imported"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /import/users [post]
+func (s *Server) handleImportUsers() http.HandlerFunc {
+	return s.handleImport(s.ImportUsers)
+}
+
+// handleImportBookings
+//
+// @Summary Import bookings
+// @Description Creates function which creates bookings from a CSV (with header) or NDJSON file. Each record is
+// @Description validated and checked for overlaps like POST /booking, also against earlier records of the file;
+// @Description opening hours and policy rules are not applied. Requires basic auth of an admin.
+// @Accept text/csv,application/x-ndjson
+// @Produces json
+//
+// @Param format query string false "csv | ndjson, by default taken from Content-Type"
+// @Param mode query string false "all_or_nothing (default) | best_effort"
+// @Param dry_run query bool false "only validate records"
+// @Param records body models.Booking true "records"
+//
+// @Success 200 {object} models.ImportResult "ok"
+// @Failure 400 {object} models.ImportResult "Some records are invalid, nothing was imported"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /import/bookings [post]
+func (s *Server) handleImportBookings() http.HandlerFunc {
+	return s.handleImport(s.ImportBookings)
+}
+
+// handleExportUsers
+//
+// @Summary Export users
+// @Description Creates function which streams all users, with password hashes, as CSV or NDJSON.
+// @Description Requires basic auth of an admin.
+// @Produces text/csv,application/x-ndjson
+//
+// @Param format query string false "csv | ndjson (default)"
+//
+// @Success 200 {array} models.UserRecord "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /export/users [get]
+func (s *Server) handleExportUsers() http.HandlerFunc {
+	return s.handleExport("users", s.ExportUsers)
+}
+
+// handleExportBookings
+//
+// @Summary Export bookings
+// @Description Creates function which streams all bookings, including cancelled ones, as CSV or NDJSON.
+// @Description Requires basic auth of an admin.
+// @Produces text/csv,application/x-ndjson
+//
+// @Param format query string false "csv | ndjson (default)"
+//
+// @Success 200 {array} models.Booking "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /export/bookings [get]
+func (s *Server) handleExportBookings() http.HandlerFunc {
+	return s.handleExport("bookings", s.ExportBookings)
+}
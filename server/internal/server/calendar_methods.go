@@ -0,0 +1,291 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/ical"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// calendarDomain makes booking UIDs globally unique
+	calendarDomain = "booking-service"
+	// calendarHistory is how long past bookings stay in calendar feeds
+	calendarHistory = 90 * 24 * time.Hour
+	// calendarRefresh is how often subscribed clients are asked to reload feeds
+	calendarRefresh = 15 * time.Minute
+)
+
+var bookingEventStatus = map[string]string{
+	models.BookingConfirmed: ical.StatusConfirmed,
+	models.BookingHeld:      ical.StatusTentative,
+	models.BookingPending:   ical.StatusTentative,
+	models.BookingCancelled: ical.StatusCancelled,
+}
+
+// bookingEvents converts bookings into calendar events. The UID only depends on
+// the booking id, so calendar clients update events in place when bookings change.
+func bookingEvents(ctx context.Context, q querier, bookings []models.Booking) ([]ical.Event, error) {
+	resourceIds := make([]int, len(bookings))
+	for i, b := range bookings {
+		resourceIds[i] = b.ResourceId
+	}
+
+	rows, err := q.Query(ctx, "SELECT "+resourceColumns+" FROM resources WHERE id = ANY($1)", resourceIds)
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[int]models.Resource)
+	for rows.Next() {
+		var r models.Resource
+		if err := scanResource(rows, &r); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		resources[r.Id] = r
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	events := make([]ical.Event, len(bookings))
+	for i, b := range bookings {
+		resource := resources[b.ResourceId]
+
+		location := resource.Name
+		if resource.Zone != "" {
+			location = fmt.Sprintf("%s (%s)", resource.Name, resource.Zone)
+		}
+
+		events[i] = ical.Event{
+			UID:         fmt.Sprintf("booking-%d@%s", b.Id, calendarDomain),
+			Sequence:    b.Sequence,
+			Stamp:       b.UpdatedAt,
+			Start:       b.StartTime,
+			End:         b.EndTime,
+			Summary:     fmt.Sprintf("Booking of %s", resource.Name),
+			Description: b.Text,
+			Location:    location,
+			Status:      bookingEventStatus[b.Status],
+		}
+	}
+	return events, nil
+}
+
+// writeCalendar responds with calendar in iCalendar format
+func (s *Server) writeCalendar(w http.ResponseWriter, calendar ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+
+	if err := calendar.Write(w); err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to write calendar; additional info: %s", err))
+		return
+	}
+	s.logger.Debug(fmt.Sprintf("Successfully rendered calendar with %d events", len(calendar.Events)))
+}
+
+// handleGetBookingCalendar
+//
+// @Summary Get booking as iCalendar
+// @Description Creates function which renders booking specified by id as an RFC 5545 calendar with a single event
+// @Produces text/calendar
+//
+// @Param id path int true "Booking ID"
+//
+// @Success 200 {string} string "VCALENDAR with one VEVENT"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /booking/{id}.ics [get]
+func (s *Server) handleGetBookingCalendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		ctx := context.Background()
+
+		booking, err := getBooking(ctx, s.database, id)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		events, err := bookingEvents(ctx, s.database, []models.Booking{booking})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		s.writeCalendar(w, ical.Calendar{Events: events}, fmt.Sprintf("booking-%d.ics", id))
+	}
+}
+
+// handleGetCalendarFeed
+//
+// @Summary Get calendar feed of user
+// @Description Creates function which renders bookings of the user owning the secret token as an RFC 5545 calendar
+// @Description to subscribe to. Cancelled bookings stay in the feed with STATUS:CANCELLED, bookings are kept
+// @Description for 90 days after they end.
+// @Produces text/calendar
+//
+// @Param token path string true "Calendar token"
+//
+// @Success 200 {string} string "VCALENDAR"
+// @Failure 404 {object} integer "Unknown token"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /calendar/{token}.ics [get]
+func (s *Server) handleGetCalendarFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := mux.Vars(r)["token"]
+
+		ctx := context.Background()
+
+		var userId int
+		var username string
+		err := s.database.QueryRow(ctx, "SELECT id, username FROM users WHERE calendar_token=$1", token).Scan(&userId, &username)
+		if err == pgx.ErrNoRows {
+			http.Error(w, "Unknown calendar token", http.StatusNotFound)
+			s.logger.Debug("Unknown calendar token")
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		var bookingList []models.Booking
+
+		query := "SELECT " + bookingColumns + " FROM bookings WHERE user_id=$1 AND end_time > $2 ORDER BY start_time"
+		data, err := s.database.Query(ctx, query, userId, time.Now().Add(-calendarHistory))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var Booking models.Booking
+			err = scanBooking(data, &Booking)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			bookingList = append(bookingList, Booking)
+		}
+
+		events, err := bookingEvents(ctx, s.database, bookingList)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		calendar := ical.Calendar{Name: fmt.Sprintf("Bookings of %s", username), RefreshInterval: calendarRefresh, Events: events}
+		s.writeCalendar(w, calendar, "bookings.ics")
+	}
+}
+
+// newToken returns size random bytes hex encoded, e.g. for calendar feed urls
+func newToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeCalendarFeed sets calendar token of user specified by id, unless keep is true and the user
+// already has one, and responds with the feed link
+func (s *Server) writeCalendarFeed(w http.ResponseWriter, r *http.Request, keep bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if user, _ := userFromContext(r.Context()); !canAccessUser(user, id) {
+		http.Error(w, "Forbidden: members can only access their own calendar", http.StatusForbidden)
+		s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} accessing calendar of user {%d}", user.Id, id))
+		return
+	}
+
+	token, err := newToken(20)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate token; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to generate token; additional info: %s", err))
+		return
+	}
+
+	query := "UPDATE users SET calendar_token=$2 WHERE id=$1 RETURNING calendar_token"
+	if keep {
+		query = "UPDATE users SET calendar_token=COALESCE(calendar_token, $2) WHERE id=$1 RETURNING calendar_token"
+	}
+
+	err = s.database.QueryRow(context.Background(), query, id, token).Scan(&token)
+	if err == pgx.ErrNoRows {
+		http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+		return
+	}
+
+	writeJSON(w, r, models.CalendarFeed{Token: token, Url: fmt.Sprintf("/calendar/%s.ics", token)})
+	s.logger.Debug("Successfully retrieved calendar feed link")
+}
+
+// handleGetCalendarToken
+//
+// @Summary Get calendar feed link
+// @Description Creates function which returns the secret calendar feed link of user specified by id, creating it
+// @Description on first use. Requires basic auth of the user or of staff.
+// @Produces json
+//
+// @Param id path int true "User ID"
+//
+// @Success 200 {object} models.CalendarFeed "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /user/{id}/calendar-token [get]
+func (s *Server) handleGetCalendarToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeCalendarFeed(w, r, true)
+	}
+}
+
+// handleResetCalendarToken
+//
+// @Summary Regenerate calendar feed link
+// @Description Creates function which replaces the calendar token of user specified by id, so the previous
+// @Description feed link stops working. Requires basic auth of the user or of staff.
+// @Produces json
+//
+// @Param id path int true "User ID"
+//
+// @Success 200 {object} models.CalendarFeed "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /user/{id}/calendar-token [post]
+func (s *Server) handleResetCalendarToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeCalendarFeed(w, r, false)
+	}
+}
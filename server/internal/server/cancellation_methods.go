@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const cancellationRuleColumns = "id, name, resource_type, hours_before, fee_percent, disabled, created_at, updated_at"
+
+func scanCancellationRule(row pgx.Row, c *models.CancellationRule) error {
+	return row.Scan(&c.Id, &c.Name, &c.ResourceType, &c.HoursBefore, &c.FeePercent, &c.Disabled, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// loadCancellationRules reads enabled cancellation rules which apply to resourceId, narrowest windows
+// first; of rules with equal windows those of the resource type come before global ones
+func loadCancellationRules(ctx context.Context, q querier, resourceId int) ([]pricing.CancellationRule, error) {
+	query := `SELECT c.id, c.name, c.hours_before, c.fee_percent
+		FROM cancellation_rules c, resources r WHERE r.id=$1 AND NOT c.disabled AND (c.resource_type IS NULL OR c.resource_type=r.type)
+		ORDER BY c.hours_before, c.resource_type IS NULL, c.fee_percent DESC, c.id`
+	rows, err := q.Query(ctx, query, resourceId)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[pricing.CancellationRule])
+}
+
+// lateCancellationFee returns a feeFunc which charges bookings cancelled at now by the cancellation rules
+// of their resources
+func lateCancellationFee(q querier, now time.Time) feeFunc {
+	rulesOf := map[int][]pricing.CancellationRule{}
+
+	return func(ctx context.Context, b models.Booking) (models.Cancellation, error) {
+		c := models.Cancellation{BookingId: b.Id, Price: b.Price, Reason: "Cancelled free of charge"}
+
+		rules, ok := rulesOf[b.ResourceId]
+		if !ok {
+			var err error
+			if rules, err = loadCancellationRules(ctx, q, b.ResourceId); err != nil {
+				return c, err
+			}
+			rulesOf[b.ResourceId] = rules
+		}
+
+		fee, rule := pricing.CancellationFee(rules, b.Price, b.StartTime, now)
+		if rule == nil {
+			return c, nil
+		}
+		c.Fee = fee
+		if rule != &pricing.AfterStart {
+			c.RuleId = &rule.Id
+		}
+
+		if lead := b.StartTime.Sub(now); lead > 0 {
+			c.Reason = fmt.Sprintf("Cancelled %s before the start, less than %d hours: %d%% fee of rule %q",
+				lead.Truncate(time.Minute), rule.HoursBefore, rule.FeePercent, rule.Name)
+		} else {
+			c.Reason = fmt.Sprintf("Cancelled after the start: %d%% fee of rule %q", rule.FeePercent, rule.Name)
+		}
+		return c, nil
+	}
+}
+
+// handleAddCancellationRule
+//
+// @Summary Add cancellation rule
+// @Description Creates function which adds a fee of bookings cancelled less than hours_before hours before their
+// @Description start, optionally only for a resource type. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param rule body models.CancellationRule true "name, hours_before, fee_percent and optional resource_type"
+//
+// @Success 201 {object} models.CancellationRule "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /cancellation-rules [post]
+func (s *Server) handleAddCancellationRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newRule models.CancellationRule
+
+		if err := json.NewDecoder(r.Body).Decode(&newRule); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newRule); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		time := time.Now()
+		newRule.CreatedAt = time
+		newRule.UpdatedAt = time
+
+		query := "INSERT INTO cancellation_rules (name,resource_type,hours_before,fee_percent,disabled,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newRule.Name, newRule.ResourceType, newRule.HoursBefore, newRule.FeePercent, newRule.Disabled, newRule.CreatedAt, newRule.UpdatedAt).Scan(&newRule.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newRule)
+		s.logger.Debug("Successefully added cancellation rule to database")
+	}
+}
+
+// handleGetCancellationRule
+//
+// @Summary Get cancellation rule
+// @Description Creates function which retrieves cancellation rule specified by id from database
+// @Produces json
+//
+// @Param id path int true "Cancellation rule ID"
+//
+// @Success 200 {object} models.CancellationRule "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /cancellation-rules/{id} [get]
+func (s *Server) handleGetCancellationRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var rule models.CancellationRule
+
+		query := "SELECT " + cancellationRuleColumns + " FROM cancellation_rules WHERE id=$1"
+
+		err := scanCancellationRule(s.database.QueryRow(context.Background(), query, id), &rule)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, rule)
+		s.logger.Debug("Successfully retrieved cancellation rule data")
+	}
+}
+
+// handleGetCancellationRules
+//
+// @Summary Get cancellation rules
+// @Description Creates function which retrieves all cancellation rules, narrowest windows first, optionally only
+// @Description those which apply to a resource type
+// @Produces json
+//
+// @Param resource_type query string false "Resource type"
+//
+// @Success 200 {array} models.CancellationRule "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /cancellation-rules [get]
+func (s *Server) handleGetCancellationRules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var ruleList []models.CancellationRule
+
+		resourceType := r.URL.Query().Get("resource_type")
+
+		query := `SELECT ` + cancellationRuleColumns + ` FROM cancellation_rules
+			WHERE $1 = '' OR resource_type IS NULL OR resource_type = $1 ORDER BY hours_before, resource_type IS NULL, fee_percent DESC, id`
+		data, err := s.database.Query(context.Background(), query, resourceType)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var rule models.CancellationRule
+			err = scanCancellationRule(data, &rule)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			ruleList = append(ruleList, rule)
+		}
+
+		if len(ruleList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, ruleList)
+		s.logger.Debug("Successfully retrieved cancellation rules data")
+	}
+}
+
+// handleUpdateCancellationRule
+//
+// @Summary Replace cancellation rule
+// @Description Creates function which replaces cancellation rule specified by id. Fees of bookings cancelled before
+// @Description are kept. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Cancellation rule ID"
+// @Param rule body models.CancellationRule true "name, hours_before, fee_percent and optional resource_type"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /cancellation-rules/{id} [put]
+func (s *Server) handleUpdateCancellationRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newRuleData models.CancellationRule
+
+		if err := json.NewDecoder(r.Body).Decode(&newRuleData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newRuleData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "UPDATE cancellation_rules SET name=$2, resource_type=$3, hours_before=$4, fee_percent=$5, disabled=$6, updated_at=$7 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newRuleData.Name, newRuleData.ResourceType, newRuleData.HoursBefore, newRuleData.FeePercent, newRuleData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated cancellation rule in database")
+	}
+}
+
+// handleDeleteCancellationRule
+//
+// @Summary Delete cancellation rule
+// @Description Creates function which deletes cancellation rule specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Cancellation rule ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /cancellation-rules/{id} [delete]
+func (s *Server) handleDeleteCancellationRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM cancellation_rules WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified cancellation rule; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified cancellation rule; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified cancellation rule from database")
+	}
+}
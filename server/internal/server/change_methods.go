@@ -0,0 +1,353 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/policy"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const changeColumns = "id, booking_id, kind, old_resource_id, new_resource_id, old_start_time, old_end_time, new_start_time, new_end_time, old_price, new_price, changed_by, created_at"
+
+func scanChange(row pgx.Row, c *models.BookingChange) error {
+	return row.Scan(&c.Id, &c.BookingId, &c.Kind, &c.OldResourceId, &c.NewResourceId, &c.OldStartTime, &c.OldEndTime, &c.NewStartTime, &c.NewEndTime, &c.OldPrice, &c.NewPrice, &c.ChangedBy, &c.CreatedAt)
+}
+
+// recordChange adds the change of a booking from before to after to its history
+func recordChange(ctx context.Context, tx pgx.Tx, kind string, before, after models.Booking, changedBy *int) error {
+	query := "INSERT INTO booking_changes (booking_id,kind,old_resource_id,new_resource_id,old_start_time,old_end_time,new_start_time,new_end_time,old_price,new_price,changed_by) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)"
+	_, err := tx.Exec(ctx, query, after.Id, kind, before.ResourceId, after.ResourceId, before.StartTime, before.EndTime, after.StartTime, after.EndTime, before.Price, after.Price, changedBy)
+	return err
+}
+
+// lockBooking locks the resource of booking id and returns the booking as it is once the lock is held
+func lockBooking(ctx context.Context, tx pgx.Tx, id int) (models.Booking, error) {
+	booking, err := getBooking(ctx, tx, id)
+	if err == pgx.ErrNoRows {
+		return booking, notFound(id)
+	} else if err != nil {
+		return booking, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	for {
+		locked := booking.ResourceId
+		if err := lockResource(ctx, tx, locked); err != nil {
+			return booking, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+		}
+
+		// the booking may have been changed, or moved to another resource, while waiting for the lock
+		if booking, err = getBooking(ctx, tx, id); err != nil {
+			return booking, newError(KindInternal, "Internal error; more info: %s", err)
+		}
+		if booking.ResourceId == locked {
+			return booking, nil
+		}
+	}
+}
+
+// checkResizable returns an error unless b is a booking whose end can still be moved
+func checkResizable(b models.Booking, now time.Time) error {
+	switch b.Status {
+	case models.BookingCancelled:
+		return newError(KindInvalid, "Booking {%d} is cancelled", b.Id)
+	case models.BookingHeld:
+		return newError(KindInvalid, "Booking {%d} is held for a waitlisted user and must be confirmed first", b.Id)
+	}
+	if !b.EndTime.After(now) {
+		return newError(KindInvalid, "TimeError: booking {%d} has already ended", b.Id)
+	}
+	return nil
+}
+
+// resizeBooking moves the end of b to end, reprices it, charges or refunds the difference to what
+// was paid, records the change as kind and publishes it. The resource must be locked.
+func (s *Server) resizeBooking(ctx context.Context, tx pgx.Tx, b models.Booking, end time.Time, kind string) (models.Booking, error) {
+	before := b
+	b.EndTime = end
+
+	if err := s.priceBooking(ctx, tx, &b); errorKind(err) == KindInvalid {
+		return b, err
+	} else if err != nil {
+		return b, newError(KindInternal, "Failed to calculate price; additional info: %s", err)
+	}
+
+	query := "UPDATE bookings SET end_time=$2, price=$3, price_breakdown=$4, overridden=series_id IS NOT NULL, sequence=sequence+1, updated_at=NOW() WHERE id=$1 RETURNING " + bookingColumns
+	if err := scanBooking(tx.QueryRow(ctx, query, b.Id, b.EndTime, b.Price, b.PriceBreakdown), &b); err != nil {
+		return b, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+	}
+
+	if err := saveRedemption(ctx, tx, b); err != nil {
+		return b, newError(KindInternal, "Failed to redeem promo code; additional info: %s", err)
+	}
+	if err := s.chargeBooking(ctx, tx, b); err != nil {
+		return b, err
+	}
+
+	if err := recordChange(ctx, tx, kind, before, b, nil); err != nil {
+		return b, newError(KindInternal, "Failed to record booking change; additional info: %s", err)
+	}
+
+	if err := s.publish(ctx, tx, outbox.BookingUpdated, b); err != nil {
+		return b, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+	return b, nil
+}
+
+// ExtendBooking adds the minutes of ext to the end of booking id if the following time on the
+// resource is free and open and the longer booking still meets the booking policy. Lead time
+// rules are not checked: they limit when a booking is made, not how long it lasts.
+func (s *Server) ExtendBooking(ctx context.Context, id int, ext models.Extension) (models.Booking, error) {
+	if err := validator.V.Struct(ext); err != nil {
+		return models.Booking{}, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return models.Booking{}, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	booking, err := lockBooking(ctx, tx, id)
+	if err != nil {
+		return booking, err
+	}
+	if err := checkResizable(booking, time.Now()); err != nil {
+		return booking, err
+	}
+
+	end := booking.EndTime.Add(time.Duration(ext.Minutes) * time.Minute)
+
+	if err := s.validateSchedule(ctx, tx, booking.ResourceId, booking.EndTime, end); err != nil {
+		return booking, err
+	}
+
+	rules, err := loadPolicy(ctx, tx, booking.ResourceId, booking.UserId)
+	if err != nil {
+		return booking, newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+	var durationRules []policy.Rule
+	for _, rule := range rules {
+		if rule.Kind != policy.KindMinLeadTime && rule.Kind != policy.KindMaxLeadTime {
+			durationRules = append(durationRules, rule)
+		}
+	}
+	extended := booking
+	extended.EndTime = end
+	violations, err := s.checkPolicy(ctx, tx, durationRules, extended)
+	if err != nil {
+		return booking, newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+	if len(violations) > 0 {
+		return booking, &Error{Kind: KindPolicy, Message: "Booking breaks policy rules", Violations: violations}
+	}
+
+	conflictId, err := findOverlap(ctx, tx, booking.ResourceId, booking.StartTime, end, booking.PartySize, id)
+	if err != nil {
+		return booking, newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+	}
+	if conflictId != 0 {
+		return booking, newError(KindConflict, "Time slot is already taken by booking with id {%d}", conflictId)
+	}
+
+	if booking, err = s.resizeBooking(ctx, tx, booking, end, models.ChangeExtended); err != nil {
+		return booking, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return booking, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return booking, nil
+}
+
+// EndBookingNow ends ongoing booking id at the current time, reprices it and offers the rest of
+// its slot to the waitlist
+func (s *Server) EndBookingNow(ctx context.Context, id int) (models.Booking, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return models.Booking{}, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	booking, err := lockBooking(ctx, tx, id)
+	if err != nil {
+		return booking, err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := checkResizable(booking, now); err != nil {
+		return booking, err
+	}
+	if !now.After(booking.StartTime) {
+		return booking, newError(KindInvalid, "TimeError: booking {%d} has not started yet, cancel it instead", id)
+	}
+
+	oldResourceId := booking.ResourceId
+	if booking, err = s.resizeBooking(ctx, tx, booking, now, models.ChangeEndedEarly); err != nil {
+		return booking, err
+	}
+
+	if _, err := s.promoteWaitlist(ctx, tx, oldResourceId); err != nil {
+		return booking, newError(KindInternal, "Failed to process waitlist; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return booking, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return booking, nil
+}
+
+// BookingHistory returns changes of booking id, oldest first
+func (s *Server) BookingHistory(ctx context.Context, id int) ([]models.BookingChange, error) {
+	if _, err := getBooking(ctx, s.database, id); err == pgx.ErrNoRows {
+		return nil, notFound(id)
+	} else if err != nil {
+		return nil, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	rows, err := s.database.Query(ctx, "SELECT "+changeColumns+" FROM booking_changes WHERE booking_id=$1 ORDER BY created_at, id", id)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.BookingChange, error) {
+		var c models.BookingChange
+		err := scanChange(row, &c)
+		return c, err
+	})
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+	return changes, nil
+}
+
+// handleExtendBooking
+//
+// @Summary Extend booking
+// @Description Creates function which adds minutes to the end of booking specified by id, e.g. one more hour while
+// @Description playing. The following time on the same resource must be free and open and the longer booking must
+// @Description meet the policy rules, except lead time. The booking is repriced, the difference is charged to the
+// @Description balance of the user and the change is recorded in the history of the booking.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Booking ID"
+// @Param extension body models.Extension true "minutes to add"
+//
+// @Success 200 {object} models.Booking "ok"
+// @Failure 400 {array} policy.Violation "Wrong ID, booking is cancelled or has ended, time breaks opening hours, holiday or maintenance rule, or booking breaks policy rules (list of violations)"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 402 {object} integer "Extension costs more than the balance of the user"
+// @Failure 409 {object} integer "Following time is already taken"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /booking/{id}/extend [post]
+func (s *Server) handleExtendBooking() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var ext models.Extension
+		if err := json.NewDecoder(r.Body).Decode(&ext); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := s.AuthorizeBooking(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		booking, err := s.ExtendBooking(r.Context(), id, ext)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, booking)
+		s.logger.Debug(fmt.Sprintf("Successefully extended booking {%d}", id))
+	}
+}
+
+// handleEndBookingNow
+//
+// @Summary End booking now
+// @Description Creates function which ends ongoing booking specified by id at the current time. The booking is
+// @Description repriced, the difference is refunded to the balance of the user, the rest of the slot is offered to
+// @Description the waitlist and the change is recorded in the history of the booking.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Booking ID"
+//
+// @Success 200 {object} models.Booking "ok"
+// @Failure 400 {object} integer "Wrong ID, booking is cancelled, has not started or has ended"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /booking/{id}/end-now [post]
+func (s *Server) handleEndBookingNow() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		if err := s.AuthorizeBooking(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		booking, err := s.EndBookingNow(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, booking)
+		s.logger.Debug(fmt.Sprintf("Successefully ended booking {%d}", id))
+	}
+}
+
+// handleGetBookingHistory
+//
+// @Summary Get booking history
+// @Description Creates function which retrieves extensions, early ends, relocations and swaps of booking specified by id, oldest first
+// @Produces json
+//
+// @Param id path int true "Booking ID"
+//
+// @Success 200 {array} models.BookingChange "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /booking/{id}/history [get]
+func (s *Server) handleGetBookingHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		changes, err := s.BookingHistory(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if len(changes) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, r, changes)
+		s.logger.Debug("Successfully retrieved booking history")
+	}
+}
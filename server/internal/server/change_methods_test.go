@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+// addTestBooking prices b by the tariffs, adds it and charges it like a new confirmed booking
+func addTestBooking(t *testing.T, s *Server, b models.Booking) models.Booking {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.priceBooking(ctx, tx, &b); err != nil {
+		t.Fatalf("failed to price booking: %s", err)
+	}
+	query := "INSERT INTO bookings (user_id,resource_id,start_time,end_time,text,price,price_breakdown) VALUES ($1,$2,$3,$4,'match',$5,$6) RETURNING " + bookingColumns
+	if err := scanBooking(tx.QueryRow(ctx, query, b.UserId, b.ResourceId, b.StartTime, b.EndTime, b.Price, b.PriceBreakdown), &b); err != nil {
+		t.Fatalf("failed to add booking: %s", err)
+	}
+	if err := s.chargeBooking(ctx, tx, b); err != nil {
+		t.Fatalf("failed to charge booking: %s", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestExtendBookingConflict(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	booking := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour)})
+	next := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start.Add(90 * time.Minute), EndTime: start.Add(150 * time.Minute)})
+
+	_, err := s.ExtendBooking(ctx, booking.Id, models.Extension{Minutes: 60})
+	if errorKind(err) != KindConflict {
+		t.Fatalf("ExtendBooking into booking %d returned %v, want a conflict", next.Id, err)
+	}
+	if b, _ := getBooking(ctx, s.database, booking.Id); !b.EndTime.Equal(booking.EndTime) {
+		t.Errorf("refused extension moved the end to %s", b.EndTime)
+	}
+	if changes, err := s.BookingHistory(ctx, booking.Id); err != nil || len(changes) != 0 {
+		t.Errorf("history after refused extension = %+v, %v, want none", changes, err)
+	}
+
+	extended, err := s.ExtendBooking(ctx, booking.Id, models.Extension{Minutes: 30})
+	if err != nil {
+		t.Fatalf("ExtendBooking up to the next booking failed: %s", err)
+	}
+	if want := next.StartTime; !extended.EndTime.Equal(want) {
+		t.Errorf("extended booking ends at %s, want %s", extended.EndTime, want)
+	}
+	changes, err := s.BookingHistory(ctx, booking.Id)
+	if err != nil || len(changes) != 1 || changes[0].Kind != models.ChangeExtended {
+		t.Errorf("history = %+v, %v, want one extension", changes, err)
+	}
+}
+
+func TestConcurrentExtendAndBook(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	otherId := addTestUser(t, s, "other")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	// the extension and a new booking race for 11:00-12:00
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	booking := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour)})
+	errs := parallel(2, func(i int) error {
+		if i == 0 {
+			_, err := s.ExtendBooking(ctx, booking.Id, models.Extension{Minutes: 60})
+			return err
+		}
+		b := models.Booking{UserId: otherId, ResourceId: resourceId, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour), Text: "match"}
+		_, _, err := s.CreateBooking(ctx, b, false)
+		return err
+	})
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errorKind(err) != KindConflict:
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of the extension and the booking succeeded, want 1", succeeded)
+	}
+
+	if pairs := overlappingPairs(t, s); pairs != 0 {
+		t.Errorf("%d pairs of bookings overlap, want none", pairs)
+	}
+}
+
+func TestEndBookingNowRefunds(t *testing.T) {
+	s := testServer(t, true)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	// 600 per hour all day long
+	if _, err := s.database.Exec(ctx, "INSERT INTO tariffs (name, start_time, end_time, price_per_hour) VALUES ('day', '00:00', '00:00', 600)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ChangeBalance(ctx, userId, models.LedgerTopUp, models.BalanceChange{Amount: 5000}, 0); err != nil {
+		t.Fatalf("top-up failed: %s", err)
+	}
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	booking := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(3 * time.Hour)})
+	if booking.Price != 1800 {
+		t.Fatalf("booking costs %d, want 1800", booking.Price)
+	}
+
+	ended, err := s.EndBookingNow(ctx, booking.Id)
+	if err != nil {
+		t.Fatalf("EndBookingNow failed: %s", err)
+	}
+	if ended.EndTime.After(time.Now()) || !ended.EndTime.After(start) {
+		t.Errorf("booking ended at %s, want now", ended.EndTime)
+	}
+	if ended.Price <= 0 || ended.Price >= booking.Price {
+		t.Errorf("ended booking costs %d, want less than %d", ended.Price, booking.Price)
+	}
+
+	paid, err := bookingPaid(ctx, s.database, booking.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, err := s.Balance(ctx, userId)
+	if err != nil {
+		t.Fatalf("Balance failed: %s", err)
+	}
+	if paid != ended.Price || balance.Balance != 5000-ended.Price {
+		t.Errorf("paid %d with balance %d left, want %d with %d left", paid, balance.Balance, ended.Price, 5000-ended.Price)
+	}
+
+	var refunds int
+	query := "SELECT COUNT(*) FROM ledger_entries WHERE booking_id=$1 AND kind=$2 AND amount=$3"
+	if err := s.database.QueryRow(ctx, query, booking.Id, models.LedgerRefund, booking.Price-ended.Price).Scan(&refunds); err != nil {
+		t.Fatal(err)
+	}
+	if refunds != 1 {
+		t.Errorf("%d refunds of %d were posted, want 1", refunds, booking.Price-ended.Price)
+	}
+
+	if _, err := s.EndBookingNow(ctx, booking.Id); errorKind(err) != KindInvalid {
+		t.Errorf("ending the booking again returned %v, want an error", err)
+	}
+}
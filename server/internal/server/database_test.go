@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/database"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDatabaseEnv names a connection string of a throwaway database for tests which need
+// postgres. They are skipped without it; every test empties all tables of the database.
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+// testServer returns a server on the migrated and emptied test database, charging bookings
+// to prepaid balances if prepaid
+func testServer(t *testing.T, prepaid bool) *Server {
+	t.Helper()
+	connString := os.Getenv(testDatabaseEnv)
+	if connString == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %s", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := database.MigrateUp(ctx, pool, time.UTC); err != nil {
+		t.Fatalf("failed to migrate test database: %s", err)
+	}
+
+	var tables string
+	query := "SELECT string_agg(quote_ident(tablename), ', ') FROM pg_tables WHERE schemaname = current_schema() AND tablename <> 'goose_db_version'"
+	if err := pool.QueryRow(ctx, query).Scan(&tables); err != nil {
+		t.Fatalf("failed to list tables: %s", err)
+	}
+	if _, err := pool.Exec(ctx, "TRUNCATE "+tables+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("failed to empty test database: %s", err)
+	}
+
+	return &Server{
+		database: pool,
+		location: time.UTC,
+		prepaid:  prepaid,
+		broker:   outbox.NewMemoryBroker(),
+		streams:  newStreamHub(),
+	}
+}
+
+// addTestUser adds a member called name and returns its id
+func addTestUser(t *testing.T, s *Server, name string) int {
+	t.Helper()
+	var id int
+	query := "INSERT INTO users (username, password, created_at, updated_at) VALUES ($1, '', NOW(), NOW()) RETURNING id"
+	if err := s.database.QueryRow(context.Background(), query, name).Scan(&id); err != nil {
+		t.Fatalf("failed to add user %s: %s", name, err)
+	}
+	return id
+}
+
+// addTestResource adds a resource called name holding capacity people and returns its id
+func addTestResource(t *testing.T, s *Server, name string, capacity int) int {
+	t.Helper()
+	var id int
+	query := "INSERT INTO resources (name, type, capacity, created_at, updated_at) VALUES ($1, 'room', $2, NOW(), NOW()) RETURNING id"
+	if err := s.database.QueryRow(context.Background(), query, name, capacity).Scan(&id); err != nil {
+		t.Fatalf("failed to add resource %s: %s", name, err)
+	}
+	return id
+}
+
+// overlappingPairs returns the number of pairs of active bookings which overlap on the same resource
+func overlappingPairs(t *testing.T, s *Server) int {
+	t.Helper()
+	var pairs int
+	query := `SELECT COUNT(*) FROM bookings a JOIN bookings b ON a.id < b.id AND a.resource_id = b.resource_id
+		AND a.start_time < b.end_time AND b.start_time < a.end_time
+		WHERE a.status <> $1 AND b.status <> $1`
+	if err := s.database.QueryRow(context.Background(), query, models.BookingCancelled).Scan(&pairs); err != nil {
+		t.Fatalf("failed to find overlapping bookings: %s", err)
+	}
+	return pairs
+}
+
+// parallel runs fn n times at once and returns the errors of the runs
+func parallel(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	done := make(chan struct{})
+	start := make(chan struct{})
+	for i := range n {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			<-start
+			errs[i] = fn(i)
+		}()
+	}
+	close(start)
+	for range n {
+		<-done
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/alexey-dobry/booking-service/server/internal/policy"
+)
+
+// ErrorKind classifies errors returned by service methods, so that every API can report them
+// with its own status codes
+type ErrorKind int
+
+const (
+	// KindInternal is a database or other server failure
+	KindInternal ErrorKind = iota
+	// KindInvalid is incorrect input data or a request breaking schedule rules
+	KindInvalid
+	// KindNotFound is a request for an entry which does not exist
+	KindNotFound
+	// KindConflict is a time slot which is already taken
+	KindConflict
+	// KindPolicy is a booking breaking policy rules, listed in Violations
+	KindPolicy
+	// KindInsufficientFunds is a booking which costs more than the balance of its user
+	KindInsufficientFunds
+	// KindUnauthenticated is a request without valid credentials
+	KindUnauthenticated
+	// KindForbidden is a request of a user who may not manage the data
+	KindForbidden
+)
+
+// Error is an error of a service method. Message is meant for the client.
+type Error struct {
+	Kind       ErrorKind
+	Message    string
+	Violations []policy.Violation
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind ErrorKind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(id int) *Error {
+	return newError(KindNotFound, "No entry with id {%d} was found in database", id)
+}
+
+// errorKind returns kind of err; errors not created by service methods are internal
+func errorKind(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// writeError responds with err returned by a service method. Entries which were not found are
+// reported with 400 like everywhere else in the API, policy violations as their json list.
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	switch e.Kind {
+	case KindPolicy:
+		messages := make([]string, len(e.Violations))
+		for i, v := range e.Violations {
+			messages[i] = v.Error()
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(e.Violations)
+		s.logger.Debug(fmt.Sprintf("PolicyError: %s", strings.Join(messages, "; ")))
+	case KindInvalid, KindNotFound:
+		http.Error(w, e.Message, http.StatusBadRequest)
+		s.logger.Debug(e.Message)
+	case KindConflict:
+		http.Error(w, e.Message, http.StatusConflict)
+		s.logger.Debug(e.Message)
+	case KindInsufficientFunds:
+		http.Error(w, e.Message, http.StatusPaymentRequired)
+		s.logger.Debug(e.Message)
+	case KindUnauthenticated:
+		w.Header().Set("WWW-Authenticate", `Basic realm="booking-service"`)
+		http.Error(w, e.Message, http.StatusUnauthorized)
+		s.logger.Debug(e.Message)
+	case KindForbidden:
+		http.Error(w, e.Message, http.StatusForbidden)
+		s.logger.Debug(e.Message)
+	default:
+		http.Error(w, e.Message, http.StatusInternalServerError)
+		s.logger.Error(e.Message)
+	}
+}
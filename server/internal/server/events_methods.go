@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// streamBuffer is the number of events a subscriber may lag behind before it is disconnected
+	streamBuffer = 64
+	// streamHeartbeat is how often idle streams get a comment, so that proxies keep them open
+	streamHeartbeat = 30 * time.Second
+	// streamRetry is the reconnection delay suggested to clients, in milliseconds
+	streamRetry = 3000
+	// listenRetry is the pause before listening again after the connection was lost
+	listenRetry = 5 * time.Second
+	// listenPoll is how often events are looked for without a notification: events which waited
+	// for an older transaction become final when it ends, which may not notify
+	listenPoll = time.Second
+)
+
+// availabilityTypes are the outbox events which change availability of resources
+var availabilityTypes = []string{
+	outbox.BookingCreated, outbox.BookingUpdated, outbox.BookingCancelled,
+	outbox.MaintenanceCreated, outbox.MaintenanceUpdated, outbox.MaintenanceDeleted,
+}
+
+// resourceStatusEvent is the type of the events streamed to subscribers
+const resourceStatusEvent = "resource.status"
+
+// availabilityEvent is an outbox event which changed availability of a resource during [Start, End),
+// together with the status of the resource then. The booking or maintenance window of the outbox
+// event is not streamed, the stream is public.
+type availabilityEvent struct {
+	Position   int64
+	ResourceId int
+	Zone       string
+	Start      time.Time
+	End        time.Time
+	Xid        int64
+	Status     models.ResourceStatus
+}
+
+// outboxCursor is a place in the outbox order: events of transaction Xid, then by Position.
+// Only final events are read after a cursor, so nothing can appear before it later.
+type outboxCursor struct {
+	Xid      int64
+	Position int64
+}
+
+// before reports whether c comes before o in outbox order
+func (c outboxCursor) before(o outboxCursor) bool {
+	return c.Xid < o.Xid || c.Xid == o.Xid && c.Position < o.Position
+}
+
+func (e availabilityEvent) cursor() outboxCursor {
+	return outboxCursor{Xid: e.Xid, Position: e.Position}
+}
+
+// matches reports whether e passes the filters of a subscriber; zero values match everything
+func (e availabilityEvent) matches(resourceId int, zone string) bool {
+	return (resourceId == 0 || e.ResourceId == resourceId) && (zone == "" || e.Zone == zone)
+}
+
+// getAvailabilityEvents retrieves final availability events of existing resources from the outbox
+// which come after c, in outbox order. Their status is the current one, so events a client missed
+// tell how the resource is booked now.
+func getAvailabilityEvents(ctx context.Context, q querier, c outboxCursor) ([]availabilityEvent, error) {
+	query := `SELECT o.position, r.id, r.zone, (o.payload->'data'->>'start_time')::timestamptz,
+		(o.payload->'data'->>'end_time')::timestamptz, o.xid::text::bigint
+		FROM outbox o JOIN resources r ON r.id = (o.payload->'data'->>'resource_id')::int
+		WHERE o.type = ANY($1) AND (o.xid, o.position) > ($2::text::xid8, $3) AND o.` + outboxFinal + `
+		ORDER BY o.xid, o.position`
+
+	rows, err := q.Query(ctx, query, availabilityTypes, strconv.FormatInt(c.Xid, 10), c.Position)
+	if err != nil {
+		return nil, err
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (availabilityEvent, error) {
+		var e availabilityEvent
+		err := row.Scan(&e.Position, &e.ResourceId, &e.Zone, &e.Start, &e.End, &e.Xid)
+		return e, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, e := range events {
+		events[i].Status = models.ResourceStatus{ResourceId: e.ResourceId, Start: e.Start, End: e.End}
+		if events[i].Status.Status, err = resourceStatus(ctx, q, e.ResourceId, e.Start, e.End); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// resourceStatus returns whether resourceId is under maintenance during [start, end), booked
+// without room for another party or available
+func resourceStatus(ctx context.Context, q querier, resourceId int, start, end time.Time) (string, error) {
+	var maintenance bool
+	query := "SELECT EXISTS (SELECT 1 FROM maintenance_windows WHERE resource_id=$1 AND start_time < $3 AND end_time > $2)"
+	if err := q.QueryRow(ctx, query, resourceId, start, end).Scan(&maintenance); err != nil {
+		return "", err
+	}
+	if maintenance {
+		return models.ResourceMaintenance, nil
+	}
+
+	conflictId, err := findOverlap(ctx, q, resourceId, start, end, 1, 0)
+	if err != nil {
+		return "", err
+	}
+	if conflictId != 0 {
+		return models.ResourceBooked, nil
+	}
+	return models.ResourceAvailable, nil
+}
+
+// currentCursor returns the cursor after every final event, where a stream starting now begins
+func currentCursor(ctx context.Context, q querier) (outboxCursor, error) {
+	var c outboxCursor
+	err := q.QueryRow(ctx, "SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&c.Xid)
+	return c, err
+}
+
+// eventCursor returns the cursor of event position, which a client resuming after it saw last.
+// If the event was already removed from the outbox, the client missed more than is kept and the
+// cursor comes before every kept event.
+func eventCursor(ctx context.Context, q querier, position int64) (outboxCursor, error) {
+	c := outboxCursor{Position: position}
+	err := q.QueryRow(ctx, "SELECT xid::text::bigint FROM outbox WHERE position=$1", position).Scan(&c.Xid)
+	if err == pgx.ErrNoRows {
+		return c, nil
+	}
+	return c, err
+}
+
+// streamHub fans availability events out to the streams served by this server
+type streamHub struct {
+	mu   sync.Mutex
+	subs map[chan availabilityEvent]struct{}
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{subs: make(map[chan availabilityEvent]struct{})}
+}
+
+// subscribe returns a channel of events which is closed when the subscriber can not keep up,
+// and a function which ends the subscription
+func (h *streamHub) subscribe() (<-chan availabilityEvent, func()) {
+	ch := make(chan availabilityEvent, streamBuffer)
+
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// broadcast hands e to every subscriber. Slow subscribers are dropped instead of blocking the
+// others; their clients reconnect and catch up with Last-Event-ID.
+func (h *streamHub) broadcast(e availabilityEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// listenOutbox broadcasts final availability events after c in outbox order until the connection
+// fails, looking for them when one is announced on the outbox channel and every listenPoll. The
+// cursor of the last broadcast event is returned.
+func (s *Server) listenOutbox(ctx context.Context, c outboxCursor) (outboxCursor, error) {
+	conn, err := s.database.Acquire(ctx)
+	if err != nil {
+		return c, err
+	}
+	defer conn.Release()
+	// the connection is not reusable once interrupted while listening
+	defer conn.Conn().Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+outbox.Channel); err != nil {
+		return c, err
+	}
+
+	for {
+		events, err := getAvailabilityEvents(ctx, s.database, c)
+		if err != nil {
+			return c, err
+		}
+		for _, e := range events {
+			s.streams.broadcast(e)
+			c = e.cursor()
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, listenPoll)
+		_, err = conn.Conn().WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && (ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded)) {
+			return c, err
+		}
+	}
+}
+
+// runOutboxListener keeps listening for outbox events until ctx is cancelled, starting with
+// events which become final after it started
+func (s *Server) runOutboxListener(ctx context.Context) {
+	var c outboxCursor
+	for {
+		var err error
+		if c.Xid == 0 {
+			c, err = currentCursor(ctx, s.database)
+		}
+		if err == nil {
+			c, err = s.listenOutbox(ctx, c)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error(fmt.Sprintf("Failed to listen for outbox events; additional info: %s", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+// handleGetAvailabilityEvents
+//
+// @Summary Stream availability changes
+// @Description Creates function which streams resource.status events as Server-Sent Events whenever a booking or
+// @Description maintenance window of a resource changes: the time slot of the change and whether the resource is
+// @Description available, booked or under maintenance then (models.ResourceStatus). Bookings themselves are not
+// @Description streamed. Every event has an id; a reconnecting client sends the last one in the Last-Event-ID header
+// @Description and first receives the events it missed (kept for 7 days) with the current status of their slots.
+// @Produces text/event-stream
+//
+// @Param zone query string false "Only events of resources in zone"
+// @Param resource_id query int false "Only events of resource"
+// @Param Last-Event-ID header int false "Resume after event"
+//
+// @Success 200 {object} models.ResourceStatus "event stream"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 500 {object} integer "Error retrieving missed events"
+// @Router /events/availability [get]
+func (s *Server) handleGetAvailabilityEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+
+		zone := r.URL.Query().Get("zone")
+
+		var resourceId int
+		if v := r.URL.Query().Get("resource_id"); v != "" {
+			if resourceId, err = strconv.Atoi(v); err != nil || resourceId < 1 {
+				http.Error(w, "Incorrect input data: resource_id must be a positive integer", http.StatusBadRequest)
+				s.logger.Debug("Incorrect input data: resource_id must be a positive integer")
+				return
+			}
+		}
+
+		var last int64
+		if v := r.Header.Get("Last-Event-ID"); v != "" {
+			if last, err = strconv.ParseInt(v, 10, 64); err != nil || last < 0 {
+				http.Error(w, "Incorrect input data: Last-Event-ID must be an event id", http.StatusBadRequest)
+				s.logger.Debug("Incorrect input data: Last-Event-ID must be an event id")
+				return
+			}
+		}
+
+		// subscribe before catching up, so that nothing is missed in between
+		events, unsubscribe := s.streams.subscribe()
+		defer unsubscribe()
+
+		var sent outboxCursor
+		var missed []availabilityEvent
+		if last != 0 {
+			if sent, err = eventCursor(r.Context(), s.database, last); err == nil {
+				missed, err = getAvailabilityEvents(r.Context(), s.database, sent)
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		// events are broadcast in outbox order, but the listener may be behind the catch up or
+		// the server the client was connected to, so events up to the last one sent are skipped
+		rc := http.NewResponseController(w)
+		send := func(e availabilityEvent) error {
+			if !sent.before(e.cursor()) {
+				return nil
+			}
+			sent = e.cursor()
+			if !e.matches(resourceId, zone) {
+				return nil
+			}
+			payload, err := json.Marshal(inZone(e.Status, ZoneFromContext(r.Context())))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Position, resourceStatusEvent, payload); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry); err != nil {
+			return
+		}
+		for _, e := range missed {
+			if err := send(e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		s.logger.Debug("Successfully opened availability stream")
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					s.logger.Debug("Closed availability stream which fell behind")
+					return
+				}
+				if err := send(e); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
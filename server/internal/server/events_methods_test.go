@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+)
+
+func TestAvailabilityEvents(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	b := models.Booking{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour), Text: "birthday of Anna", Status: models.BookingConfirmed}
+	query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	if err := s.database.QueryRow(ctx, query, b.UserId, b.ResourceId, b.StartTime, b.EndTime, b.Text, b.Status).Scan(&b.Id); err != nil {
+		t.Fatalf("failed to add booking: %s", err)
+	}
+	if err := s.publish(ctx, s.database, outbox.BookingCreated, b); err != nil {
+		t.Fatalf("failed to publish booking: %s", err)
+	}
+
+	if _, err := s.database.Exec(ctx, cancelBookings+"id=$1", b.Id); err != nil {
+		t.Fatalf("failed to cancel booking: %s", err)
+	}
+	if err := s.publish(ctx, s.database, outbox.BookingCancelled, b); err != nil {
+		t.Fatalf("failed to publish cancellation: %s", err)
+	}
+
+	events, err := getAvailabilityEvents(ctx, s.database, outboxCursor{})
+	if err != nil {
+		t.Fatalf("getAvailabilityEvents failed: %s", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+
+	// the booking is cancelled by now, so the status of both events is the current one
+	want := models.ResourceStatus{ResourceId: resourceId, Start: b.StartTime, End: b.EndTime, Status: models.ResourceAvailable}
+	for _, e := range events {
+		if !e.Status.Start.Equal(want.Start) || !e.Status.End.Equal(want.End) || e.Status.ResourceId != want.ResourceId || e.Status.Status != want.Status {
+			t.Errorf("status = %+v, want %+v", e.Status, want)
+		}
+	}
+}
+
+func TestResourceStatus(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	court := addTestResource(t, s, "court", 1)
+	lounge := addTestResource(t, s, "lounge", 4)
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	for _, resourceId := range []int{court, lounge} {
+		query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text, party_size) VALUES ($1, $2, $3, $4, '', 2)"
+		if _, err := s.database.Exec(ctx, query, userId, resourceId, start, end); err != nil {
+			t.Fatalf("failed to add booking: %s", err)
+		}
+	}
+	query := "INSERT INTO maintenance_windows (resource_id, start_time, end_time, reason) VALUES ($1, $2, $3, 'broken')"
+	if _, err := s.database.Exec(ctx, query, court, end, end.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add maintenance: %s", err)
+	}
+
+	tests := []struct {
+		name       string
+		resourceId int
+		start, end time.Time
+		want       string
+	}{
+		{"booked", court, start, end, models.ResourceBooked},
+		{"room for another party", lounge, start, end, models.ResourceAvailable},
+		{"maintenance", court, end, end.Add(time.Hour), models.ResourceMaintenance},
+		{"free", court, end.Add(time.Hour), end.Add(2 * time.Hour), models.ResourceAvailable},
+	}
+
+	for _, tt := range tests {
+		got, err := resourceStatus(ctx, s.database, tt.resourceId, tt.start, tt.end)
+		if err != nil {
+			t.Fatalf("%s: resourceStatus failed: %s", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: status = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,716 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const groupColumns = "id, organiser_id, start_time, end_time, text, created_at"
+
+const participantColumns = "user_id, status, invited_at, responded_at"
+
+func scanGroup(row pgx.Row, g *models.BookingGroup) error {
+	return row.Scan(&g.Id, &g.OrganiserId, &g.StartTime, &g.EndTime, &g.Text, &g.CreatedAt)
+}
+
+func scanParticipant(row pgx.Row, p *models.GroupParticipant) error {
+	return row.Scan(&p.UserId, &p.Status, &p.InvitedAt, &p.RespondedAt)
+}
+
+// getGroup reads group id together with its bookings and participants
+func getGroup(ctx context.Context, q querier, id int) (models.BookingGroup, error) {
+	var g models.BookingGroup
+	if err := scanGroup(q.QueryRow(ctx, "SELECT "+groupColumns+" FROM booking_groups WHERE id=$1", id), &g); err != nil {
+		return g, err
+	}
+	return g, loadGroupDetails(ctx, q, &g)
+}
+
+// loadGroupDetails reads bookings and participants of g
+func loadGroupDetails(ctx context.Context, q querier, g *models.BookingGroup) error {
+	rows, err := q.Query(ctx, "SELECT "+bookingColumns+" FROM bookings WHERE group_id=$1 ORDER BY resource_id, id", g.Id)
+	if err != nil {
+		return err
+	}
+	if g.Bookings, err = collectBookings(rows); err != nil {
+		return err
+	}
+
+	g.ResourceIds = []int{}
+	for _, b := range g.Bookings {
+		if !slices.Contains(g.ResourceIds, b.ResourceId) {
+			g.ResourceIds = append(g.ResourceIds, b.ResourceId)
+		}
+	}
+
+	rows, err = q.Query(ctx, "SELECT "+participantColumns+" FROM group_participants WHERE group_id=$1 ORDER BY invited_at, user_id", g.Id)
+	if err != nil {
+		return err
+	}
+	g.Participants, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.GroupParticipant, error) {
+		var p models.GroupParticipant
+		err := scanParticipant(row, &p)
+		return p, err
+	})
+	return err
+}
+
+// firstMissing returns the first of ids which is not an id of a row of table, or 0 if all of them exist
+func firstMissing(ctx context.Context, q querier, table string, ids []int) (int, error) {
+	rows, err := q.Query(ctx, "SELECT id FROM "+table+" WHERE id = ANY($1)", ids)
+	if err != nil {
+		return 0, err
+	}
+	found, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if !slices.Contains(found, id) {
+			return id, nil
+		}
+	}
+	return 0, nil
+}
+
+// canAccessGroup reports whether user may see group g: its organiser, participants and staff may
+func canAccessGroup(user models.User, g models.BookingGroup) bool {
+	if canAccessUser(user, g.OrganiserId) {
+		return true
+	}
+	return slices.ContainsFunc(g.Participants, func(p models.GroupParticipant) bool { return p.UserId == user.Id })
+}
+
+// CreateGroup books every resource of g for its interval in a single transaction for the organiser
+// and invites the participants. Bookings which overlap existing ones, break schedule or policy rules
+// or are not covered by the balance are returned as conflicts, and then nothing is booked.
+func (s *Server) CreateGroup(ctx context.Context, g models.BookingGroup) (models.BookingGroup, []models.Conflict, error) {
+	if err := validator.V.Struct(g); err != nil {
+		return g, nil, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	if !g.EndTime.After(g.StartTime) {
+		return g, nil, newError(KindInvalid, "TimeError: end_time is before start_time")
+	}
+
+	if slices.Contains(g.ParticipantIds, g.OrganiserId) {
+		return g, nil, newError(KindInvalid, "ParticipantError: organiser can not be invited to their own group")
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return g, nil, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if id, err := firstMissing(ctx, tx, "users", append([]int{g.OrganiserId}, g.ParticipantIds...)); err != nil {
+		return g, nil, newError(KindInternal, "Internal error; more info: %s", err)
+	} else if id != 0 {
+		return g, nil, notFound(id)
+	}
+
+	if id, err := firstMissing(ctx, tx, "resources", g.ResourceIds); err != nil {
+		return g, nil, newError(KindInternal, "Internal error; more info: %s", err)
+	} else if id != 0 {
+		return g, nil, notFound(id)
+	}
+
+	if err := lockResources(ctx, tx, g.ResourceIds...); err != nil {
+		return g, nil, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	query := "INSERT INTO booking_groups (organiser_id,start_time,end_time,text) VALUES ($1,$2,$3,$4) RETURNING id, created_at"
+	if err := tx.QueryRow(ctx, query, g.OrganiserId, g.StartTime, g.EndTime, g.Text).Scan(&g.Id, &g.CreatedAt); err != nil {
+		return g, nil, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+
+	g.Bookings = []models.Booking{}
+	conflicts := []models.Conflict{}
+	for _, resourceId := range g.ResourceIds {
+		conflict, err := s.bookGroupResource(ctx, tx, &g, resourceId)
+		if err != nil {
+			return g, nil, err
+		}
+		conflicts = append(conflicts, conflict...)
+	}
+	if len(conflicts) > 0 {
+		g.Id = 0
+		g.Bookings = nil
+		return g, conflicts, nil
+	}
+
+	g.Participants = []models.GroupParticipant{}
+	for _, userId := range g.ParticipantIds {
+		p := models.GroupParticipant{UserId: userId}
+		if err := scanParticipant(tx.QueryRow(ctx, "INSERT INTO group_participants (group_id,user_id) VALUES ($1,$2) RETURNING "+participantColumns, g.Id, userId), &p); err != nil {
+			return g, nil, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+		}
+		g.Participants = append(g.Participants, p)
+	}
+
+	if err := s.publishBookings(ctx, tx, outbox.BookingCreated, g.Bookings); err != nil {
+		return g, nil, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return g, nil, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return g, nil, nil
+}
+
+// bookGroupResource books resourceId for group g and appends the booking to g.Bookings, or returns
+// the conflicts which prevent it
+func (s *Server) bookGroupResource(ctx context.Context, tx pgx.Tx, g *models.BookingGroup, resourceId int) ([]models.Conflict, error) {
+	conflict := models.Conflict{ResourceId: resourceId, StartTime: g.StartTime, EndTime: g.EndTime}
+
+	sch, err := s.loadSchedule(ctx, tx, resourceId, g.StartTime, g.EndTime)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to check schedule; additional info: %s", err)
+	}
+	var violation *schedule.Violation
+	if errors.As(sch.Check(g.StartTime, g.EndTime), &violation) {
+		conflict.Rule, conflict.Reason = violation.Rule, violation.Message
+		return []models.Conflict{conflict}, nil
+	}
+
+	status, err := newBookingStatus(ctx, tx, resourceId)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to check resource; additional info: %s", err)
+	}
+	b := models.Booking{UserId: g.OrganiserId, ResourceId: resourceId, GroupId: &g.Id, StartTime: g.StartTime, EndTime: g.EndTime, Text: g.Text, Status: status}
+
+	rules, err := loadPolicy(ctx, tx, resourceId, g.OrganiserId)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+	violations, err := s.checkPolicy(ctx, tx, rules, b)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+	if len(violations) > 0 {
+		conflicts := make([]models.Conflict, len(violations))
+		for i, v := range violations {
+			conflicts[i] = conflict
+			conflicts[i].Rule, conflicts[i].Reason = v.Kind, fmt.Sprintf("%s: %s", v.Name, v.Message)
+		}
+		return conflicts, nil
+	}
+
+	conflictId, err := findOverlap(ctx, tx, resourceId, g.StartTime, g.EndTime, 1, 0)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+	}
+	if conflictId != 0 {
+		conflict.ConflictBookingId = conflictId
+		return []models.Conflict{conflict}, nil
+	}
+
+	if err := s.priceBooking(ctx, tx, &b); err != nil {
+		return nil, newError(KindInternal, "Failed to calculate price; additional info: %s", err)
+	}
+
+	// bookings are charged one by one, so the balance is checked against the earlier ones
+	if err := s.checkFunds(ctx, tx, b.UserId, b.Price); errorKind(err) == KindInsufficientFunds {
+		conflict.Rule, conflict.Reason = "balance", err.Error()
+		return []models.Conflict{conflict}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	query := "INSERT INTO bookings (user_id,resource_id,group_id,start_time,end_time,text,status,price,price_breakdown) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING " + bookingColumns
+	if err := scanBooking(tx.QueryRow(ctx, query, b.UserId, b.ResourceId, b.GroupId, b.StartTime, b.EndTime, b.Text, b.Status, b.Price, b.PriceBreakdown), &b); err != nil {
+		return nil, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+	if err := s.chargeBooking(ctx, tx, b); err != nil {
+		return nil, err
+	}
+	if err := requestApproval(ctx, tx, b); err != nil {
+		return nil, newError(KindInternal, "Failed to request approval; additional info: %s", err)
+	}
+
+	g.Bookings = append(g.Bookings, b)
+	return nil, nil
+}
+
+// GetGroup returns booking group specified by id with its bookings and participants
+func (s *Server) GetGroup(ctx context.Context, id int) (models.BookingGroup, error) {
+	g, err := getGroup(ctx, s.database, id)
+	if err == pgx.ErrNoRows {
+		return g, notFound(id)
+	} else if err != nil {
+		return g, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	return g, nil
+}
+
+// CancelGroup cancels bookings of group id which have not started yet, with cancellation rules
+// applied to each of them, and deletes the group
+func (s *Server) CancelGroup(ctx context.Context, id int) ([]models.Cancellation, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT DISTINCT resource_id FROM bookings WHERE group_id=$1", id)
+	if err != nil {
+		return nil, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	resourceIds, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	if err := lockResources(ctx, tx, resourceIds...); err != nil {
+		return nil, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	now := time.Now()
+	cancelled, cancellations, err := cancelAndRefund(ctx, tx, lateCancellationFee(tx, now), "group_id=$1 AND start_time > $2", id, now)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to delete group bookings; additional info: %s", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM booking_groups WHERE id=$1", id); err != nil {
+		return nil, newError(KindInternal, "Failed to delete specified group; additional info: %s", err)
+	}
+
+	for _, resourceId := range resourceIds {
+		if _, err := s.promoteWaitlist(ctx, tx, resourceId); err != nil {
+			return nil, newError(KindInternal, "Failed to process waitlist; additional info: %s", err)
+		}
+	}
+
+	if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled); err != nil {
+		return nil, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return cancellations, nil
+}
+
+// forbidGroup responds with 403 to user which may not change or see group id
+func (s *Server) forbidGroup(w http.ResponseWriter, user models.User, id int) {
+	http.Error(w, "Forbidden: only the organiser, participants and staff can access the group", http.StatusForbidden)
+	s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} accessing group {%d}", user.Id, id))
+}
+
+// handleAddGroup
+//
+// @Summary Add booking group
+// @Description Creates function which books several resources for the same interval in a single transaction,
+// @Description e.g. adjacent PCs for a team, and invites participants. If any resource is taken or breaks a rule,
+// @Description nothing is booked and the conflicts are returned. organiser_id defaults to the authenticated user.
+// @Description Requires basic auth of the organiser or of staff.
+// @Accept json
+// @Produces json
+//
+// @Param group body models.BookingGroup true "resource_ids, start_time, end_time, text and optional participant_ids"
+//
+// @Success 201 {object} models.BookingGroup "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {array} models.Conflict "Bookings of the group conflict with existing bookings or rules"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /groups [post]
+func (s *Server) handleAddGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newGroup models.BookingGroup
+
+		if err := json.NewDecoder(r.Body).Decode(&newGroup); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		user, _ := userFromContext(r.Context())
+		if newGroup.OrganiserId == 0 {
+			newGroup.OrganiserId = user.Id
+		}
+		if !canAccessUser(user, newGroup.OrganiserId) {
+			http.Error(w, "Forbidden: members can only organise their own groups", http.StatusForbidden)
+			s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} organising group for user {%d}", user.Id, newGroup.OrganiserId))
+			return
+		}
+
+		group, conflicts, err := s.CreateGroup(r.Context(), newGroup)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		if len(conflicts) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			writeJSON(w, r, conflicts)
+			s.logger.Debug(fmt.Sprintf("Group was not booked due to %d conflicts", len(conflicts)))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, group)
+		s.logger.Debug(fmt.Sprintf("Successefully booked %d resources for group {%d}", len(group.Bookings), group.Id))
+	}
+}
+
+// handleGetGroup
+//
+// @Summary Get booking group data
+// @Description Creates function which retrieves booking group specified by id with its bookings and participants.
+// @Description Requires basic auth of the organiser, of a participant or of staff.
+// @Produces json
+//
+// @Param id path int true "Group ID"
+//
+// @Success 200 {object} models.BookingGroup "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /groups/{id} [get]
+func (s *Server) handleGetGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		group, err := s.GetGroup(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if user, _ := userFromContext(r.Context()); !canAccessGroup(user, group) {
+			s.forbidGroup(w, user, id)
+			return
+		}
+
+		writeJSON(w, r, group)
+		s.logger.Debug("Successfully retrieved group data")
+	}
+}
+
+// handleGetGroups
+//
+// @Summary Get booking groups of user
+// @Description Creates function which retrieves groups organised by the user or which the user is invited to,
+// @Description latest first. user_id defaults to the authenticated user. Requires basic auth of the user or of staff.
+// @Produces json
+//
+// @Param user_id query int false "User ID"
+//
+// @Success 200 {array} models.BookingGroup "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /groups [get]
+func (s *Server) handleGetGroups() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		user, _ := userFromContext(r.Context())
+		userId := user.Id
+		if v := r.URL.Query().Get("user_id"); v != "" {
+			userId, _ = strconv.Atoi(v)
+		}
+		if !canAccessUser(user, userId) {
+			http.Error(w, "Forbidden: members can only access their own groups", http.StatusForbidden)
+			s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} accessing groups of user {%d}", user.Id, userId))
+			return
+		}
+
+		ctx := r.Context()
+
+		query := "SELECT " + groupColumns + " FROM booking_groups WHERE organiser_id=$1 OR id IN (SELECT group_id FROM group_participants WHERE user_id=$1) ORDER BY start_time DESC, id"
+		rows, err := s.database.Query(ctx, query, userId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+		groups, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.BookingGroup, error) {
+			var g models.BookingGroup
+			err := scanGroup(row, &g)
+			return g, err
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to scan data from database response; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to scan data from database response; additional info: %s", err))
+			return
+		}
+
+		if len(groups) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		for i := range groups {
+			if err := loadGroupDetails(ctx, s.database, &groups[i]); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to scan data from database response; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to scan data from database response; additional info: %s", err))
+				return
+			}
+		}
+
+		writeJSON(w, r, groups)
+		s.logger.Debug("Successfully retrieved groups data")
+	}
+}
+
+// handleDeleteGroup
+//
+// @Summary Cancel booking group
+// @Description Creates function which cancels bookings of group specified by id which have not started yet and
+// @Description deletes the group. Late cancellations are charged a fee like single bookings; the response lists
+// @Description fee and refund of every cancelled booking. Requires basic auth of the organiser or of staff.
+// @Produces json
+//
+// @Param id path int true "Group ID"
+//
+// @Success 200 {array} models.Cancellation "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error deleting data from database"
+// @Router /groups/{id} [delete]
+func (s *Server) handleDeleteGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		group, err := s.GetGroup(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if user, _ := userFromContext(r.Context()); !canAccessUser(user, group.OrganiserId) {
+			s.forbidGroup(w, user, id)
+			return
+		}
+
+		cancellations, err := s.CancelGroup(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, cancellations)
+		s.logger.Debug("Successefully deleted specified group from database")
+	}
+}
+
+// handleAddGroupParticipant
+//
+// @Summary Invite user to booking group
+// @Description Creates function which invites a registered user to group specified by id. Inviting a user who
+// @Description declined invites them again. Requires basic auth of the organiser or of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Group ID"
+// @Param participant body models.GroupParticipant true "user_id of the invited user"
+//
+// @Success 201 {object} models.GroupParticipant "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /groups/{id}/participants [post]
+func (s *Server) handleAddGroupParticipant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var participant models.GroupParticipant
+
+		if err := json.NewDecoder(r.Body).Decode(&participant); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(participant); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		ctx := r.Context()
+
+		group, err := s.GetGroup(ctx, id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if user, _ := userFromContext(ctx); !canAccessUser(user, group.OrganiserId) {
+			s.forbidGroup(w, user, id)
+			return
+		}
+
+		if participant.UserId == group.OrganiserId {
+			http.Error(w, "Incorrect input data: ParticipantError: organiser can not be invited to their own group", http.StatusBadRequest)
+			s.logger.Debug("Incorrect input data: ParticipantError: organiser can not be invited to their own group")
+			return
+		}
+
+		if missing, err := firstMissing(ctx, s.database, "users", []int{participant.UserId}); err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		} else if missing != 0 {
+			s.writeError(w, notFound(missing))
+			return
+		}
+
+		query := "INSERT INTO group_participants (group_id,user_id) VALUES ($1,$2) " +
+			"ON CONFLICT (group_id, user_id) DO UPDATE SET status='" + models.ParticipantInvited + "', invited_at=NOW(), responded_at=NULL " +
+			"WHERE group_participants.status='" + models.ParticipantDeclined + "' RETURNING " + participantColumns
+
+		err = scanParticipant(s.database.QueryRow(ctx, query, id, participant.UserId), &participant)
+		if err == pgx.ErrNoRows {
+			// already invited or accepted
+			err = scanParticipant(s.database.QueryRow(ctx, "SELECT "+participantColumns+" FROM group_participants WHERE group_id=$1 AND user_id=$2", id, participant.UserId), &participant)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, participant)
+		s.logger.Debug(fmt.Sprintf("Successefully invited user {%d} to group {%d}", participant.UserId, id))
+	}
+}
+
+// handleUpdateGroupParticipant
+//
+// @Summary Respond to booking group invitation
+// @Description Creates function which accepts or declines the invitation of user specified by user_id to group
+// @Description specified by id. Requires basic auth of the invited user or of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param participant body models.GroupParticipant true "status accepted or declined"
+//
+// @Success 200 {object} models.GroupParticipant "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /groups/{id}/participants/{user_id} [put]
+func (s *Server) handleUpdateGroupParticipant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		userId, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+
+		if user, _ := userFromContext(r.Context()); !canAccessUser(user, userId) {
+			http.Error(w, "Forbidden: members can only respond to their own invitations", http.StatusForbidden)
+			s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} responding to invitation of user {%d}", user.Id, userId))
+			return
+		}
+
+		var participant models.GroupParticipant
+
+		if err := json.NewDecoder(r.Body).Decode(&participant); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if participant.Status != models.ParticipantAccepted && participant.Status != models.ParticipantDeclined {
+			http.Error(w, "Incorrect input data: status must be accepted or declined", http.StatusBadRequest)
+			s.logger.Debug("Incorrect input data: status must be accepted or declined")
+			return
+		}
+
+		query := "UPDATE group_participants SET status=$3, responded_at=NOW() WHERE group_id=$1 AND user_id=$2 RETURNING " + participantColumns
+
+		err := scanParticipant(s.database.QueryRow(r.Context(), query, id, userId, participant.Status), &participant)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No invitation of user {%d} to group {%d} was found in database", userId, id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No invitation of user {%d} to group {%d} was found in database", userId, id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, participant)
+		s.logger.Debug(fmt.Sprintf("Successefully updated invitation of user {%d} to group {%d}", userId, id))
+	}
+}
+
+// handleDeleteGroupParticipant
+//
+// @Summary Remove participant from booking group
+// @Description Creates function which removes user specified by user_id from group specified by id. Requires basic
+// @Description auth of the organiser, of the participant or of staff.
+// @Produces json
+//
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error deleting data from database"
+// @Router /groups/{id}/participants/{user_id} [delete]
+func (s *Server) handleDeleteGroupParticipant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		userId, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+		ctx := r.Context()
+
+		group, err := s.GetGroup(ctx, id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if user, _ := userFromContext(ctx); !canAccessUser(user, userId) && !canAccessUser(user, group.OrganiserId) {
+			s.forbidGroup(w, user, id)
+			return
+		}
+
+		if _, err := s.database.Exec(ctx, "DELETE FROM group_participants WHERE group_id=$1 AND user_id=$2", id, userId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to delete data from database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug(fmt.Sprintf("Successefully removed user {%d} from group {%d}", userId, id))
+	}
+}
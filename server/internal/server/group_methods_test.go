@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+// countRows returns the number of rows of each of tables
+func countRows(t *testing.T, s *Server, tables ...string) []int {
+	t.Helper()
+	counts := make([]int, len(tables))
+	for i, table := range tables {
+		if err := s.database.QueryRow(context.Background(), "SELECT COUNT(*) FROM "+table).Scan(&counts[i]); err != nil {
+			t.Fatalf("failed to count %s: %s", table, err)
+		}
+	}
+	return counts
+}
+
+func TestCreateGroupRollsBackOnConflict(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	organiserId := addTestUser(t, s, "organiser")
+	guestId := addTestUser(t, s, "guest")
+	resourceIds := []int{addTestResource(t, s, "court 1", 1), addTestResource(t, s, "court 2", 1), addTestResource(t, s, "court 3", 1)}
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	var takenId int
+	query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text) VALUES ($1, $2, $3, $4, 'taken') RETURNING id"
+	if err := s.database.QueryRow(ctx, query, guestId, resourceIds[1], start.Add(30*time.Minute), start.Add(90*time.Minute)).Scan(&takenId); err != nil {
+		t.Fatalf("failed to add booking: %s", err)
+	}
+
+	g := models.BookingGroup{OrganiserId: organiserId, ResourceIds: resourceIds, StartTime: start, EndTime: start.Add(time.Hour), Text: "tournament", ParticipantIds: []int{guestId}}
+	g, conflicts, err := s.CreateGroup(ctx, g)
+	if err != nil {
+		t.Fatalf("CreateGroup failed: %s", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ResourceId != resourceIds[1] || conflicts[0].ConflictBookingId != takenId {
+		t.Errorf("conflicts = %+v, want court 2 taken by booking %d", conflicts, takenId)
+	}
+	if g.Id != 0 || g.Bookings != nil {
+		t.Errorf("group %d with bookings %v was returned, want none", g.Id, g.Bookings)
+	}
+
+	// court 1 was booked before the conflict on court 2 was found and must be rolled back with the group
+	counts := countRows(t, s, "bookings", "booking_groups", "group_participants", "outbox")
+	if want := []int{1, 0, 0, 0}; !slices.Equal(counts, want) {
+		t.Errorf("bookings, groups, participants and events = %v, want %v", counts, want)
+	}
+}
+
+func TestConcurrentGroupsDoNotOverlap(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	organisers := []int{addTestUser(t, s, "organiser 1"), addTestUser(t, s, "organiser 2")}
+	court1, court2, court3 := addTestResource(t, s, "court 1", 1), addTestResource(t, s, "court 2", 1), addTestResource(t, s, "court 3", 1)
+
+	// both groups want court 2, so one of them must get none of its courts
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	groups := []models.BookingGroup{
+		{OrganiserId: organisers[0], ResourceIds: []int{court1, court2}, StartTime: start, EndTime: start.Add(time.Hour), Text: "doubles"},
+		{OrganiserId: organisers[1], ResourceIds: []int{court3, court2}, StartTime: start, EndTime: start.Add(time.Hour), Text: "doubles"},
+	}
+
+	conflicts := make([][]models.Conflict, len(groups))
+	errs := parallel(len(groups), func(i int) error {
+		var err error
+		_, conflicts[i], err = s.CreateGroup(ctx, groups[i])
+		return err
+	})
+
+	booked := 0
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("CreateGroup failed: %s", err)
+		}
+		if len(conflicts[i]) == 0 {
+			booked++
+		} else if len(conflicts[i]) != 1 || conflicts[i][0].ResourceId != court2 {
+			t.Errorf("conflicts of group %d = %+v, want court 2", i, conflicts[i])
+		}
+	}
+	if booked != 1 {
+		t.Errorf("%d groups were booked, want 1", booked)
+	}
+
+	counts := countRows(t, s, "bookings", "booking_groups")
+	if want := []int{2, 1}; !slices.Equal(counts, want) {
+		t.Errorf("bookings and groups = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,480 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const ledgerEntryColumns = "id, user_id, kind, amount, account, booking_id, balance, description, created_by, created_at"
+
+func scanLedgerEntry(row pgx.Row, e *models.LedgerEntry) error {
+	return row.Scan(&e.Id, &e.UserId, &e.Kind, &e.Amount, &e.Account, &e.BookingId, &e.Balance, &e.Description, &e.CreatedBy, &e.CreatedAt)
+}
+
+// userBalance returns the total of the postings to the account of userId
+func userBalance(ctx context.Context, q querier, userId int) (int, error) {
+	var balance int
+	err := q.QueryRow(ctx, "SELECT COALESCE(SUM(amount), 0) FROM ledger_postings WHERE account=$1 AND user_id=$2", models.AccountUsers, userId).Scan(&balance)
+	return balance, err
+}
+
+// bookingPaid returns the amount charged for bookingId less the refunds
+func bookingPaid(ctx context.Context, q querier, bookingId int) (int, error) {
+	var paid int
+	err := q.QueryRow(ctx, "SELECT COALESCE(-SUM(amount), 0) FROM ledger_entries WHERE booking_id=$1 AND kind IN ($2, $3)",
+		bookingId, models.LedgerCharge, models.LedgerRefund).Scan(&paid)
+	return paid, err
+}
+
+// postings returns the double entry booking of e: its amount credited to the account of its user
+// and debited from the venue account it names
+func postings(e models.LedgerEntry) []models.Posting {
+	userId := e.UserId
+	return []models.Posting{
+		{Account: models.AccountUsers, UserId: &userId, Amount: e.Amount},
+		{Account: e.Account, Amount: -e.Amount},
+	}
+}
+
+// balanced reports whether postings book a non-zero amount from one account to another
+func balanced(postings []models.Posting) bool {
+	sum := 0
+	for _, p := range postings {
+		if p.Amount == 0 {
+			return false
+		}
+		sum += p.Amount
+	}
+	return len(postings) >= 2 && sum == 0
+}
+
+// postEntry adds e with its postings to the ledger. The user is locked until the end of tx, so
+// that balances of concurrent entries are calculated one after another. The database checks
+// again at commit that the postings of every entry sum to zero.
+func postEntry(ctx context.Context, tx pgx.Tx, e *models.LedgerEntry) error {
+	e.Postings = postings(*e)
+	if !balanced(e.Postings) {
+		return fmt.Errorf("ledger entry of user {%d} is not balanced: %v", e.UserId, e.Postings)
+	}
+
+	if err := lockUser(ctx, tx, e.UserId); err != nil {
+		return err
+	}
+
+	balance, err := userBalance(ctx, tx, e.UserId)
+	if err != nil {
+		return err
+	}
+	e.Balance = balance + e.Amount
+
+	query := "INSERT INTO ledger_entries (user_id,kind,amount,account,booking_id,balance,description,created_by) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id, created_at"
+	if err := tx.QueryRow(ctx, query, e.UserId, e.Kind, e.Amount, e.Account, e.BookingId, e.Balance, e.Description, e.CreatedBy).Scan(&e.Id, &e.CreatedAt); err != nil {
+		return err
+	}
+
+	for _, p := range e.Postings {
+		query := "INSERT INTO ledger_postings (entry_id,account,user_id,amount) VALUES ($1,$2,$3,$4)"
+		if _, err := tx.Exec(ctx, query, e.Id, p.Account, p.UserId, p.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPostings sets the postings of entries
+func loadPostings(ctx context.Context, q querier, entries []models.LedgerEntry) error {
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.Id
+	}
+
+	rows, err := q.Query(ctx, "SELECT entry_id, account, user_id, amount FROM ledger_postings WHERE entry_id=ANY($1) ORDER BY id", ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byEntry := make(map[int][]models.Posting, len(entries))
+	for rows.Next() {
+		var entryId int
+		var p models.Posting
+		if err := rows.Scan(&entryId, &p.Account, &p.UserId, &p.Amount); err != nil {
+			return err
+		}
+		byEntry[entryId] = append(byEntry[entryId], p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		entries[i].Postings = byEntry[entries[i].Id]
+	}
+	return nil
+}
+
+// checkFunds returns an error unless the balance of userId covers amount. Without prepaid
+// bookings every amount is covered.
+func (s *Server) checkFunds(ctx context.Context, tx pgx.Tx, userId, amount int) error {
+	if !s.prepaid || amount <= 0 {
+		return nil
+	}
+
+	if err := lockUser(ctx, tx, userId); err != nil {
+		return newError(KindInternal, "Failed to lock user; additional info: %s", err)
+	}
+	balance, err := userBalance(ctx, tx, userId)
+	if err != nil {
+		return newError(KindInternal, "Failed to check balance; additional info: %s", err)
+	}
+	if balance < amount {
+		return newError(KindInsufficientFunds, "Insufficient balance: booking costs %d, user {%d} has %d", amount, userId, balance)
+	}
+	return nil
+}
+
+// chargeBooking charges the user of a confirmed booking its price, or the difference to what
+// was paid for it before it was rescheduled. A booking which got cheaper is refunded the difference.
+func (s *Server) chargeBooking(ctx context.Context, tx pgx.Tx, b models.Booking) error {
+	if !s.prepaid || b.Status != models.BookingConfirmed {
+		return nil
+	}
+
+	paid, err := bookingPaid(ctx, tx, b.Id)
+	if err != nil {
+		return newError(KindInternal, "Failed to charge booking; additional info: %s", err)
+	}
+	due := b.Price - paid
+	if due == 0 {
+		return nil
+	}
+
+	if err := s.checkFunds(ctx, tx, b.UserId, due); err != nil {
+		return err
+	}
+
+	entry := models.LedgerEntry{UserId: b.UserId, Kind: models.LedgerCharge, Amount: -due, Account: models.AccountRevenue, BookingId: &b.Id,
+		Description: fmt.Sprintf("Booking {%d}", b.Id)}
+	if due < 0 {
+		entry.Kind = models.LedgerRefund
+		entry.Description = fmt.Sprintf("Booking {%d} was rescheduled", b.Id)
+	}
+	if err := postEntry(ctx, tx, &entry); err != nil {
+		return newError(KindInternal, "Failed to charge booking; additional info: %s", err)
+	}
+	return nil
+}
+
+// refundBooking returns what was paid for a cancelled booking to its user, less the fee of c,
+// and sets the paid and refunded amounts of c
+func refundBooking(ctx context.Context, tx pgx.Tx, b models.Booking, c *models.Cancellation) error {
+	paid, err := bookingPaid(ctx, tx, b.Id)
+	if err != nil {
+		return err
+	}
+	c.Paid = paid
+	if c.Refund = paid - c.Fee; c.Refund <= 0 {
+		c.Refund = 0
+		return nil
+	}
+
+	entry := models.LedgerEntry{UserId: b.UserId, Kind: models.LedgerRefund, Amount: c.Refund, Account: models.AccountRevenue, BookingId: &b.Id,
+		Description: fmt.Sprintf("Booking {%d} was cancelled", b.Id)}
+	if c.Fee > 0 {
+		entry.Description = fmt.Sprintf("Booking {%d} was cancelled with a fee of %d", b.Id, c.Fee)
+	}
+	return postEntry(ctx, tx, &entry)
+}
+
+// Balance returns the balance of user specified by id
+func (s *Server) Balance(ctx context.Context, userId int) (models.Balance, error) {
+	balance := models.Balance{UserId: userId}
+
+	if _, err := getUser(ctx, s.database, userId); err == pgx.ErrNoRows {
+		return balance, notFound(userId)
+	} else if err != nil {
+		return balance, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	var err error
+	if balance.Balance, err = userBalance(ctx, s.database, userId); err != nil {
+		return balance, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	return balance, nil
+}
+
+// LedgerEntries returns the ledger of user specified by id, latest entries first
+func (s *Server) LedgerEntries(ctx context.Context, userId int) ([]models.LedgerEntry, error) {
+	rows, err := s.database.Query(ctx, "SELECT "+ledgerEntryColumns+" FROM ledger_entries WHERE user_id=$1 ORDER BY id DESC", userId)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.LedgerEntry, error) {
+		var e models.LedgerEntry
+		err := scanLedgerEntry(row, &e)
+		return e, err
+	})
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+	if err := loadPostings(ctx, s.database, entries); err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return entries, nil
+}
+
+// ChangeBalance tops up (kind top_up) or adjusts (kind adjustment) the balance of userId on behalf
+// of user createdBy. Top-ups must be positive.
+func (s *Server) ChangeBalance(ctx context.Context, userId int, kind string, change models.BalanceChange, createdBy int) (models.LedgerEntry, error) {
+	entry := models.LedgerEntry{UserId: userId, Kind: kind, Amount: change.Amount, Description: change.Description}
+	if createdBy != 0 {
+		entry.CreatedBy = &createdBy
+	}
+
+	if err := validator.V.Struct(change); err != nil {
+		return entry, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+	switch kind {
+	case models.LedgerTopUp:
+		if change.Amount < 0 {
+			return entry, newError(KindInvalid, "Incorrect input data: top-up amount must be positive")
+		}
+		entry.Account = models.AccountCash
+	case models.LedgerAdjustment:
+		entry.Account = models.AccountAdjustments
+	default:
+		return entry, newError(KindInvalid, "Incorrect input data: unknown ledger entry kind %q", kind)
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return entry, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := getUser(ctx, tx, userId); err == pgx.ErrNoRows {
+		return entry, notFound(userId)
+	} else if err != nil {
+		return entry, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	if err := postEntry(ctx, tx, &entry); err != nil {
+		return entry, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return entry, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return entry, nil
+}
+
+// AccountBalances returns totals of the postings to every venue account and to the accounts of
+// users, which sum to zero
+func (s *Server) AccountBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	query := "SELECT account, SUM(amount)::int FROM ledger_postings GROUP BY account ORDER BY account"
+	rows, err := s.database.Query(ctx, query)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	accounts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AccountBalance])
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+	return accounts, nil
+}
+
+// checkUserAccess responds with 403 and returns false unless the authenticated user may
+// manage the balance of user specified by id
+func (s *Server) checkUserAccess(w http.ResponseWriter, r *http.Request, id int) bool {
+	if user, _ := userFromContext(r.Context()); !canAccessUser(user, id) {
+		http.Error(w, "Forbidden: members can only access their own balance", http.StatusForbidden)
+		s.logger.Debug(fmt.Sprintf("Forbidden: user {%d} accessing balance of user {%d}", user.Id, id))
+		return false
+	}
+	return true
+}
+
+// handleGetBalance
+//
+// @Summary Get user balance
+// @Description Creates function which retrieves the prepaid balance of user specified by id. Requires basic auth
+// @Description of the user or of staff.
+// @Produces json
+//
+// @Param id path int true "User ID"
+//
+// @Success 200 {object} models.Balance "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /user/{id}/balance [get]
+func (s *Server) handleGetBalance() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		if !s.checkUserAccess(w, r, id) {
+			return
+		}
+
+		balance, err := s.Balance(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, balance)
+		s.logger.Debug("Successfully retrieved user balance")
+	}
+}
+
+// handleGetLedger
+//
+// @Summary Get user ledger
+// @Description Creates function which retrieves all ledger entries of user specified by id, latest first: top-ups,
+// @Description booking charges, refunds and adjustments. Requires basic auth of the user or of staff.
+// @Produces json
+//
+// @Param id path int true "User ID"
+//
+// @Success 200 {array} models.LedgerEntry "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /user/{id}/ledger [get]
+func (s *Server) handleGetLedger() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		if !s.checkUserAccess(w, r, id) {
+			return
+		}
+
+		entries, err := s.LedgerEntries(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if len(entries) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, entries)
+		s.logger.Debug("Successfully retrieved ledger entries data")
+	}
+}
+
+// writeBalanceChange handles a top-up or an adjustment of the balance of user specified by id
+func (s *Server) writeBalanceChange(w http.ResponseWriter, r *http.Request, kind string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var change models.BalanceChange
+
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+		return
+	}
+
+	user, _ := userFromContext(r.Context())
+
+	entry, err := s.ChangeBalance(r.Context(), id, kind, change, user.Id)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, entry)
+	s.logger.Debug(fmt.Sprintf("Successefully added %s of user {%d} to ledger", kind, id))
+}
+
+// handleTopUp
+//
+// @Summary Top up user balance
+// @Description Creates function which credits the balance of user specified by id with money paid in at the front
+// @Description desk. Requires basic auth of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "User ID"
+// @Param change body models.BalanceChange true "positive amount and optional description"
+//
+// @Success 201 {object} models.LedgerEntry "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /user/{id}/top-up [post]
+func (s *Server) handleTopUp() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeBalanceChange(w, r, models.LedgerTopUp)
+	}
+}
+
+// handleAdjustBalance
+//
+// @Summary Adjust user balance
+// @Description Creates function which corrects the balance of user specified by id by a positive or negative
+// @Description amount, e.g. a compensation or a mistaken top-up. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "User ID"
+// @Param change body models.BalanceChange true "non-zero amount and description"
+//
+// @Success 201 {object} models.LedgerEntry "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /user/{id}/adjustment [post]
+func (s *Server) handleAdjustBalance() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeBalanceChange(w, r, models.LedgerAdjustment)
+	}
+}
+
+// handleGetAccounts
+//
+// @Summary Get venue accounts
+// @Description Creates function which retrieves totals of the venue accounts (cash, revenue, adjustments) and the
+// @Description sum of all user balances. Requires basic auth of an admin.
+// @Produces json
+//
+// @Success 200 {array} models.AccountBalance "ok"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /ledger/accounts [get]
+func (s *Server) handleGetAccounts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		accounts, err := s.AccountBalances(r.Context())
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, accounts)
+		s.logger.Debug("Successfully retrieved venue accounts data")
+	}
+}
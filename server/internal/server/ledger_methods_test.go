@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+func TestPostings(t *testing.T) {
+	for _, e := range []models.LedgerEntry{
+		{UserId: 1, Kind: models.LedgerTopUp, Amount: 5000, Account: models.AccountCash},
+		{UserId: 1, Kind: models.LedgerCharge, Amount: -1200, Account: models.AccountRevenue},
+		{UserId: 2, Kind: models.LedgerAdjustment, Amount: -300, Account: models.AccountAdjustments},
+	} {
+		p := postings(e)
+		if !balanced(p) {
+			t.Errorf("%s of %d: postings %+v are not balanced", e.Kind, e.Amount, p)
+			continue
+		}
+		if p[0].Account != models.AccountUsers || p[0].UserId == nil || *p[0].UserId != e.UserId || p[0].Amount != e.Amount {
+			t.Errorf("%s of %d: user posting = %+v, want %d to user %d", e.Kind, e.Amount, p[0], e.Amount, e.UserId)
+		}
+		if p[1].Account != e.Account || p[1].UserId != nil || p[1].Amount != -e.Amount {
+			t.Errorf("%s of %d: venue posting = %+v, want %d to %s", e.Kind, e.Amount, p[1], -e.Amount, e.Account)
+		}
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	user := 1
+	tests := []struct {
+		name     string
+		postings []models.Posting
+		want     bool
+	}{
+		{"pair", []models.Posting{{Account: models.AccountUsers, UserId: &user, Amount: 100}, {Account: models.AccountCash, Amount: -100}}, true},
+		{"split", []models.Posting{{Account: models.AccountUsers, UserId: &user, Amount: -100}, {Account: models.AccountRevenue, Amount: 80}, {Account: models.AccountAdjustments, Amount: 20}}, true},
+		{"single", []models.Posting{{Account: models.AccountUsers, UserId: &user, Amount: 100}}, false},
+		{"none", nil, false},
+		{"off by one", []models.Posting{{Account: models.AccountUsers, UserId: &user, Amount: 100}, {Account: models.AccountCash, Amount: -99}}, false},
+		{"zero amounts", []models.Posting{{Account: models.AccountUsers, UserId: &user}, {Account: models.AccountCash}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := balanced(tt.postings); got != tt.want {
+			t.Errorf("%s: balanced = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConcurrentChargesDoNotOverdraw(t *testing.T) {
+	s := testServer(t, true)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	if _, err := s.ChangeBalance(ctx, userId, models.LedgerTopUp, models.BalanceChange{Amount: 1000}, 0); err != nil {
+		t.Fatalf("top-up failed: %s", err)
+	}
+
+	// two bookings of 700 each, which the balance covers one at a time but not together
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	bookings := make([]models.Booking, 2)
+	for i := range bookings {
+		b := models.Booking{UserId: userId, Status: models.BookingConfirmed, Price: 700}
+		query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text, status, price) VALUES ($1, $2, $3, $4, '', $5, $6) RETURNING id"
+		from := start.Add(time.Duration(i) * time.Hour)
+		if err := s.database.QueryRow(ctx, query, userId, resourceId, from, from.Add(time.Hour), b.Status, b.Price).Scan(&b.Id); err != nil {
+			t.Fatalf("failed to add booking: %s", err)
+		}
+		bookings[i] = b
+	}
+
+	errs := parallel(len(bookings), func(i int) error {
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := s.chargeBooking(ctx, tx, bookings[i]); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+
+	charged := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			charged++
+		case errorKind(err) != KindInsufficientFunds:
+			t.Errorf("charge failed: %s", err)
+		}
+	}
+	if charged != 1 {
+		t.Errorf("%d bookings were charged, want 1", charged)
+	}
+
+	balance, err := s.Balance(ctx, userId)
+	if err != nil {
+		t.Fatalf("Balance failed: %s", err)
+	}
+	if balance.Balance != 300 {
+		t.Errorf("balance = %d, want 300", balance.Balance)
+	}
+}
+
+func TestDeleteUserWithLedger(t *testing.T) {
+	s := testServer(t, true)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+
+	if _, err := s.ChangeBalance(ctx, userId, models.LedgerTopUp, models.BalanceChange{Amount: 1000}, 0); err != nil {
+		t.Fatalf("top-up failed: %s", err)
+	}
+	if err := s.DeleteUser(ctx, userId); errorKind(err) != KindConflict {
+		t.Errorf("DeleteUser returned %v, want a conflict", err)
+	}
+	if _, err := s.database.Exec(ctx, "DELETE FROM users WHERE id=$1", userId); err == nil {
+		t.Error("database deleted a user who has ledger entries")
+	}
+}
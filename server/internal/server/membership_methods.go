@@ -0,0 +1,584 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// periodLayout is the layout of months in which included hours of memberships are counted
+const periodLayout = "2006-01"
+
+var periodPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+
+const membershipPlanColumns = "id, name, included_hours, resource_types, disabled, created_at, updated_at"
+
+func scanMembershipPlan(row pgx.Row, p *models.MembershipPlan) error {
+	return row.Scan(&p.Id, &p.Name, &p.IncludedHours, &p.ResourceTypes, &p.Disabled, &p.CreatedAt, &p.UpdatedAt)
+}
+
+const membershipColumns = "id, user_id, plan_id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), created_at"
+
+func scanMembership(row pgx.Row, m *models.Membership) error {
+	return row.Scan(&m.Id, &m.UserId, &m.PlanId, &m.StartDate, &m.EndDate, &m.CreatedAt)
+}
+
+// membershipUsage returns the minutes of membershipId used in period by bookings which are not
+// cancelled, except booking excludeId. Usage is read from the price breakdowns of the bookings,
+// so cancelling or rescheduling a booking gives its hours back.
+func membershipUsage(ctx context.Context, q querier, membershipId int, period string, excludeId int) (int, error) {
+	var used int
+	query := `SELECT COALESCE(SUM((price_breakdown->'membership'->>'minutes')::int), 0) FROM bookings
+		WHERE (price_breakdown->'membership'->>'membership_id')::int=$1 AND price_breakdown->'membership'->>'period'=$2 AND id<>$3 AND ` + activeBooking
+	err := q.QueryRow(ctx, query, membershipId, period, excludeId).Scan(&used)
+	return used, err
+}
+
+// findMembership returns the membership of the user of b on the day b starts with the minutes left
+// of its plan in that month, or nil if there is none or its plan does not apply to the resource.
+// Inside a transaction bookings of the user are serialized until it ends, so concurrent bookings
+// can not use the same included hours.
+func (s *Server) findMembership(ctx context.Context, q querier, b models.Booking) (*pricing.Membership, error) {
+	if b.UserId == 0 {
+		return nil, nil
+	}
+	if tx, ok := q.(pgx.Tx); ok {
+		if err := lockUser(ctx, tx, b.UserId); err != nil {
+			return nil, err
+		}
+	}
+
+	start := b.StartTime.In(s.location)
+	membership := pricing.Membership{Period: start.Format(periodLayout)}
+	var includedHours int
+
+	query := `SELECT m.id, p.name, p.included_hours FROM memberships m JOIN membership_plans p ON p.id=m.plan_id JOIN resources r ON r.id=$2
+		WHERE m.user_id=$1 AND m.start_date <= $3::date AND (m.end_date IS NULL OR m.end_date >= $3::date) AND NOT p.disabled
+		AND (cardinality(p.resource_types) = 0 OR r.type = ANY(p.resource_types))`
+	err := q.QueryRow(ctx, query, b.UserId, b.ResourceId, start.Format(time.DateOnly)).Scan(&membership.Id, &membership.Name, &includedHours)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	used, err := membershipUsage(ctx, q, membership.Id, membership.Period, b.Id)
+	if err != nil {
+		return nil, err
+	}
+	membership.RemainingMinutes = includedHours*60 - used
+	return &membership, nil
+}
+
+// membershipReport sets the usage fields of m for period
+func membershipReport(ctx context.Context, q querier, m *models.Membership, period string) error {
+	var includedHours int
+	if err := q.QueryRow(ctx, "SELECT included_hours FROM membership_plans WHERE id=$1", m.PlanId).Scan(&includedHours); err != nil {
+		return err
+	}
+	used, err := membershipUsage(ctx, q, m.Id, period, 0)
+	if err != nil {
+		return err
+	}
+	m.Period, m.IncludedMinutes, m.UsedMinutes = period, includedHours*60, used
+	return nil
+}
+
+// reportPeriod reads the period query parameter, the current month in the venue time zone by default
+func (s *Server) reportPeriod(r *http.Request) (string, error) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		return time.Now().In(s.location).Format(periodLayout), nil
+	}
+	if !periodPattern.MatchString(period) {
+		return "", fmt.Errorf("period must be YYYY-MM, got %q", period)
+	}
+	return period, nil
+}
+
+// saveMembership validates m and adds it, or replaces membership id unless it is 0. Memberships
+// of a user must not overlap, so the user is locked while they are checked.
+func saveMembership(ctx context.Context, tx pgx.Tx, id int, m *models.Membership) error {
+	if err := validator.V.Struct(*m); err != nil {
+		return newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+	if m.EndDate != nil && *m.EndDate < m.StartDate {
+		return newError(KindInvalid, "Incorrect input data: end_date is before start_date")
+	}
+
+	if err := lockUser(ctx, tx, m.UserId); err != nil {
+		return newError(KindInternal, "Failed to lock user; additional info: %s", err)
+	}
+
+	var overlapId int
+	query := `SELECT id FROM memberships WHERE user_id=$1 AND id<>$2 AND start_date <= COALESCE($4::date, 'infinity')
+		AND COALESCE(end_date, 'infinity') >= $3::date LIMIT 1`
+	err := tx.QueryRow(ctx, query, m.UserId, id, m.StartDate, m.EndDate).Scan(&overlapId)
+	if err == nil {
+		return newError(KindConflict, "Membership overlaps membership with id {%d} of the same user", overlapId)
+	} else if err != pgx.ErrNoRows {
+		return newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	if id == 0 {
+		query = "INSERT INTO memberships (user_id,plan_id,start_date,end_date) VALUES ($1,$2,$3::date,$4::date) RETURNING " + membershipColumns
+		err = scanMembership(tx.QueryRow(ctx, query, m.UserId, m.PlanId, m.StartDate, m.EndDate), m)
+	} else {
+		query = "UPDATE memberships SET user_id=$2, plan_id=$3, start_date=$4::date, end_date=$5::date WHERE id=$1 RETURNING " + membershipColumns
+		err = scanMembership(tx.QueryRow(ctx, query, id, m.UserId, m.PlanId, m.StartDate, m.EndDate), m)
+	}
+	if err == pgx.ErrNoRows {
+		return notFound(id)
+	} else if err != nil {
+		return newError(KindInvalid, "Failed to save membership; additional info: %s", err)
+	}
+	return nil
+}
+
+// handleAddMembershipPlan
+//
+// @Summary Add membership plan
+// @Description Creates function which adds a plan including hours of bookings every month, optionally only of some
+// @Description resource types. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param plan body models.MembershipPlan true "name, included_hours and optional resource_types"
+//
+// @Success 201 {object} models.MembershipPlan "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /membership-plans [post]
+func (s *Server) handleAddMembershipPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newPlan models.MembershipPlan
+
+		if err := json.NewDecoder(r.Body).Decode(&newPlan); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newPlan); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newPlan.ResourceTypes == nil {
+			newPlan.ResourceTypes = []string{}
+		}
+
+		time := time.Now()
+		newPlan.CreatedAt = time
+		newPlan.UpdatedAt = time
+
+		query := "INSERT INTO membership_plans (name,included_hours,resource_types,disabled,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newPlan.Name, newPlan.IncludedHours, newPlan.ResourceTypes, newPlan.Disabled, newPlan.CreatedAt, newPlan.UpdatedAt).Scan(&newPlan.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newPlan)
+		s.logger.Debug("Successefully added membership plan to database")
+	}
+}
+
+// handleGetMembershipPlans
+//
+// @Summary Get membership plans
+// @Description Creates function which retrieves all membership plans
+// @Produces json
+//
+// @Success 200 {array} models.MembershipPlan "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /membership-plans [get]
+func (s *Server) handleGetMembershipPlans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var planList []models.MembershipPlan
+
+		data, err := s.database.Query(context.Background(), "SELECT "+membershipPlanColumns+" FROM membership_plans ORDER BY id")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var plan models.MembershipPlan
+			err = scanMembershipPlan(data, &plan)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			planList = append(planList, plan)
+		}
+
+		if len(planList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, planList)
+		s.logger.Debug("Successfully retrieved membership plans data")
+	}
+}
+
+// handleUpdateMembershipPlan
+//
+// @Summary Replace membership plan
+// @Description Creates function which replaces membership plan specified by id. Hours already used are kept, the new
+// @Description included hours apply to bookings made afterwards. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Membership plan ID"
+// @Param plan body models.MembershipPlan true "name, included_hours and optional resource_types"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /membership-plans/{id} [put]
+func (s *Server) handleUpdateMembershipPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newPlanData models.MembershipPlan
+
+		if err := json.NewDecoder(r.Body).Decode(&newPlanData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newPlanData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newPlanData.ResourceTypes == nil {
+			newPlanData.ResourceTypes = []string{}
+		}
+
+		query := "UPDATE membership_plans SET name=$2, included_hours=$3, resource_types=$4, disabled=$5, updated_at=$6 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newPlanData.Name, newPlanData.IncludedHours, newPlanData.ResourceTypes, newPlanData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated membership plan in database")
+	}
+}
+
+// handleDeleteMembershipPlan
+//
+// @Summary Delete membership plan
+// @Description Creates function which deletes membership plan specified by id. Plans with memberships can only be
+// @Description disabled. Requires basic auth of an admin.
+//
+// @Param id path int true "Membership plan ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /membership-plans/{id} [delete]
+func (s *Server) handleDeleteMembershipPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM membership_plans WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified membership plan; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified membership plan; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified membership plan from database")
+	}
+}
+
+// writeMembership saves the membership in the request body as membership id, a new one if id is 0,
+// and responds with it
+func (s *Server) writeMembership(w http.ResponseWriter, r *http.Request, id int) {
+	var membership models.Membership
+
+	if err := json.NewDecoder(r.Body).Decode(&membership); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+		return
+	}
+
+	ctx := r.Context()
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := saveMembership(ctx, tx, id, &membership); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+		return
+	}
+
+	if id == 0 {
+		w.WriteHeader(http.StatusCreated)
+	}
+	writeJSON(w, r, membership)
+	s.logger.Debug("Successefully saved membership in database")
+}
+
+// handleAddMembership
+//
+// @Summary Add membership
+// @Description Creates function which subscribes a user to a membership plan from start_date, until end_date if it is
+// @Description given. Memberships of a user can not overlap. Requires basic auth of staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param membership body models.Membership true "user_id, plan_id, start_date and optional end_date"
+//
+// @Success 201 {object} models.Membership "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "Membership overlaps another one"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /memberships [post]
+func (s *Server) handleAddMembership() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		s.writeMembership(w, r, 0)
+	}
+}
+
+// handleUpdateMembership
+//
+// @Summary Replace membership
+// @Description Creates function which replaces membership specified by id, e.g. to end it by setting end_date.
+// @Description Requires basic auth of staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Membership ID"
+// @Param membership body models.Membership true "user_id, plan_id, start_date and optional end_date"
+//
+// @Success 200 {object} models.Membership "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "Membership overlaps another one"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /memberships/{id} [put]
+func (s *Server) handleUpdateMembership() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		if id == 0 {
+			http.Error(w, "No entry with id {0} was found in database", http.StatusBadRequest)
+			s.logger.Debug("No entry with id {0} was found in database")
+			return
+		}
+
+		s.writeMembership(w, r, id)
+	}
+}
+
+// handleGetMembership
+//
+// @Summary Get membership
+// @Description Creates function which retrieves membership specified by id with the hours included and used in a
+// @Description month, the current one by default. Requires basic auth of staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Membership ID"
+// @Param period query string false "Month, YYYY-MM"
+//
+// @Success 200 {object} models.Membership "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /memberships/{id} [get]
+func (s *Server) handleGetMembership() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		period, err := s.reportPeriod(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		var membership models.Membership
+
+		query := "SELECT " + membershipColumns + " FROM memberships WHERE id=$1"
+
+		err = scanMembership(s.database.QueryRow(r.Context(), query, id), &membership)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err == nil {
+			err = membershipReport(r.Context(), s.database, &membership, period)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, membership)
+		s.logger.Debug("Successfully retrieved membership data")
+	}
+}
+
+// handleGetMemberships
+//
+// @Summary Get memberships
+// @Description Creates function which retrieves all memberships, optionally only those of a user, with the hours
+// @Description included and used in a month, the current one by default. Requires basic auth of staff or an admin.
+// @Produces json
+//
+// @Param user_id query int false "User ID"
+// @Param period query string false "Month, YYYY-MM"
+//
+// @Success 200 {array} models.Membership "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /memberships [get]
+func (s *Server) handleGetMemberships() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		userId, _ := strconv.Atoi(r.URL.Query().Get("user_id"))
+
+		period, err := s.reportPeriod(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		var membershipList []models.Membership
+
+		query := "SELECT " + membershipColumns + " FROM memberships WHERE $1 = 0 OR user_id = $1 ORDER BY user_id, start_date"
+		data, err := s.database.Query(r.Context(), query, userId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var membership models.Membership
+			err = scanMembership(data, &membership)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			membershipList = append(membershipList, membership)
+		}
+
+		for i := range membershipList {
+			if err := membershipReport(r.Context(), s.database, &membershipList[i], period); err != nil {
+				http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+				return
+			}
+		}
+
+		if len(membershipList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, membershipList)
+		s.logger.Debug("Successfully retrieved memberships data")
+	}
+}
+
+// handleDeleteMembership
+//
+// @Summary Delete membership
+// @Description Creates function which deletes membership specified by id. Prices of bookings which used its hours
+// @Description are kept. Requires basic auth of staff or an admin.
+//
+// @Param id path int true "Membership ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /memberships/{id} [delete]
+func (s *Server) handleDeleteMembership() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM memberships WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified membership; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified membership; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified membership from database")
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/webhook"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// outboxInterval is how often the outbox is checked for new events
+	outboxInterval = time.Second
+	// outboxRetry is the pause after a sink failed to take an event
+	outboxRetry = 10 * time.Second
+	// outboxBatch is the number of events relayed in one transaction
+	outboxBatch = 100
+	// outboxRetention is how long relayed events are kept
+	outboxRetention = 7 * 24 * time.Hour
+	// outboxBrokerPrefix is the beginning of broker subjects, followed by the event type
+	outboxBrokerPrefix = "booking-service."
+)
+
+// outboxFinal is the condition of outbox events no other event can come before any
+// more: their transaction started before every running one. Events are ordered by xid, the
+// transaction which wrote them, and position within it.
+const outboxFinal = "xid < pg_snapshot_xmin(pg_current_snapshot())"
+
+// newSinks returns outbox sinks named in config
+func (s *Server) newSinks(names []string) []outbox.Sink {
+	var sinks []outbox.Sink
+	for _, name := range names {
+		switch name {
+		case outbox.SinkWebhooks:
+			sinks = append(sinks, webhookSink{s})
+		case outbox.SinkLog:
+			sinks = append(sinks, outbox.LogSink{Log: s.logger.Debug})
+		case outbox.SinkBroker:
+			sinks = append(sinks, outbox.BrokerSink{Broker: s.broker, Prefix: outboxBrokerPrefix})
+		}
+	}
+	return sinks
+}
+
+// publish writes event of eventType with data to the outbox. It must be called with the
+// transaction making the change, so the event is stored if and only if the change is.
+func (s *Server) publish(ctx context.Context, q querier, eventType string, data any) error {
+	id, err := newToken(16)
+	if err != nil {
+		return err
+	}
+
+	payload, err := outbox.NewPayload(id, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, "INSERT INTO outbox (event_id, type, payload) VALUES ($1, $2, $3)", id, eventType, string(payload))
+	return err
+}
+
+// publishBookings publishes eventType for each of bookings
+func (s *Server) publishBookings(ctx context.Context, q querier, eventType string, bookings []models.Booking) error {
+	for _, b := range bookings {
+		if err := s.publish(ctx, q, eventType, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishUser publishes eventType with current data of user specified by id
+func (s *Server) publishUser(ctx context.Context, q querier, eventType string, id int) error {
+	user, err := getUser(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, q, eventType, user)
+}
+
+// relayOutbox hands a batch of events to all sinks in outbox order and returns how many
+// were relayed. Only one server relays at a time. An event is marked delivered once every
+// sink took it; if one fails, relaying stops and the event is retried for all sinks later.
+// Events of transactions which started after the oldest running one wait for it to end,
+// since it may still write events which come before them.
+func (s *Server) relayOutbox(ctx context.Context) (int, error) {
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1, 0)", lockClassOutbox).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	query := `SELECT position, event_id, type, payload FROM outbox
+		WHERE delivered_at IS NULL AND ` + outboxFinal + ` ORDER BY xid, position LIMIT $1`
+	rows, err := tx.Query(ctx, query, outboxBatch)
+	if err != nil {
+		return 0, err
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[outbox.Event])
+	if err != nil {
+		return 0, err
+	}
+
+	var relayed []int64
+	var sinkErr error
+	for _, e := range events {
+		for _, sink := range s.sinks {
+			if sinkErr = sink.Publish(ctx, e); sinkErr != nil {
+				sinkErr = fmt.Errorf("%s sink failed on event {%s}: %w", sink.Name(), e.Id, sinkErr)
+				break
+			}
+		}
+		if sinkErr != nil {
+			break
+		}
+		relayed = append(relayed, e.Position)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE outbox SET delivered_at=NOW() WHERE position = ANY($1)", relayed); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(relayed), sinkErr
+}
+
+// runOutboxRelay relays outbox events until ctx is cancelled and removes old relayed ones
+func (s *Server) runOutboxRelay(ctx context.Context) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanup.C:
+			if _, err := s.database.Exec(ctx, "DELETE FROM outbox WHERE delivered_at < $1", time.Now().Add(-outboxRetention)); err != nil {
+				s.logger.Error(fmt.Sprintf("Failed to clean up outbox; additional info: %s", err))
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		// full batches mean more events are probably waiting
+		for {
+			n, err := s.relayOutbox(ctx)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("Failed to relay outbox events; additional info: %s", err))
+				select {
+				case <-ctx.Done():
+				case <-time.After(outboxRetry):
+				}
+				break
+			}
+			if n < outboxBatch {
+				break
+			}
+		}
+	}
+}
+
+// webhookSink queues deliveries of events for every enabled webhook subscribed to them
+type webhookSink struct {
+	s *Server
+}
+
+func (w webhookSink) Name() string {
+	return outbox.SinkWebhooks
+}
+
+func (w webhookSink) Publish(ctx context.Context, e outbox.Event) error {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, next_attempt_at)
+		SELECT id, $1, $2, $3, NOW() FROM webhooks WHERE NOT disabled AND ($2 = ANY(events) OR $4 = ANY(events))`
+	tag, err := w.s.database.Exec(ctx, query, e.Id, e.Type, string(e.Payload), webhook.AllEvents)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		w.s.wakeWebhooks()
+	}
+	return nil
+}
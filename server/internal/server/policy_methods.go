@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/policy"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const policyRuleColumns = "id, name, kind, value, resource_type, role, disabled, created_at, updated_at"
+
+func scanPolicyRule(row pgx.Row, p *models.PolicyRule) error {
+	return row.Scan(&p.Id, &p.Name, &p.Kind, &p.Value, &p.ResourceType, &p.Role, &p.Disabled, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// loadPolicy reads enabled rules which apply to bookings of resourceId made by userId:
+// global rules and rules scoped to the type of the resource and the role of the user
+func loadPolicy(ctx context.Context, q querier, resourceId, userId int) ([]policy.Rule, error) {
+	query := `SELECT p.id, p.name, p.kind, p.value FROM policy_rules p, resources r, users u
+		WHERE r.id=$1 AND u.id=$2 AND NOT p.disabled
+		AND (p.resource_type IS NULL OR p.resource_type=r.type) AND (p.role IS NULL OR p.role=u.role)
+		ORDER BY p.id`
+	rows, err := q.Query(ctx, query, resourceId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[policy.Rule])
+}
+
+// policyRequest collects usage of the booking's user which per-user rules are checked against.
+// The booking itself (by id) is not counted.
+func (s *Server) policyRequest(ctx context.Context, q querier, booking models.Booking) (policy.Request, error) {
+	req := policy.Request{Start: booking.StartTime, End: booking.EndTime, Now: time.Now(), Location: s.location}
+
+	query := "SELECT COUNT(*) FROM bookings WHERE user_id=$1 AND end_time > $2 AND id <> $3 AND " + activeBooking
+	if err := q.QueryRow(ctx, query, booking.UserId, req.Now, booking.Id).Scan(&req.ActiveBookings); err != nil {
+		return req, err
+	}
+
+	from, to := policy.Weeks(booking.StartTime, booking.EndTime, req.Location)
+	query = "SELECT start_time, end_time FROM bookings WHERE user_id=$1 AND start_time < $3 AND end_time > $2 AND id <> $4 AND " + activeBooking
+	rows, err := q.Query(ctx, query, booking.UserId, from, to, booking.Id)
+	if err != nil {
+		return req, err
+	}
+	req.Booked, err = pgx.CollectRows(rows, pgx.RowToStructByPos[schedule.Interval])
+
+	return req, err
+}
+
+// checkPolicy returns every rule booking breaks. The user of the booking is locked until the
+// end of tx so that concurrent bookings of the same user can not both pass per-user limits.
+func (s *Server) checkPolicy(ctx context.Context, tx pgx.Tx, rules []policy.Rule, booking models.Booking) ([]policy.Violation, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	if err := lockUser(ctx, tx, booking.UserId); err != nil {
+		return nil, err
+	}
+
+	req, err := s.policyRequest(ctx, tx, booking)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Evaluate(rules, req), nil
+}
+
+// validatePolicy returns an error listing the violated rules if booking breaks the booking policy
+func (s *Server) validatePolicy(ctx context.Context, tx pgx.Tx, booking models.Booking) error {
+	rules, err := loadPolicy(ctx, tx, booking.ResourceId, booking.UserId)
+	if err != nil {
+		return newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+
+	violations, err := s.checkPolicy(ctx, tx, rules, booking)
+	if err != nil {
+		return newError(KindInternal, "Failed to check booking policy; additional info: %s", err)
+	}
+
+	if len(violations) > 0 {
+		return &Error{Kind: KindPolicy, Message: "Booking breaks policy rules", Violations: violations}
+	}
+	return nil
+}
+
+// checkBookingPolicy responds with the list of violated rules and returns false
+// if booking breaks the booking policy
+func (s *Server) checkBookingPolicy(w http.ResponseWriter, tx pgx.Tx, booking models.Booking) bool {
+	if err := s.validatePolicy(context.Background(), tx, booking); err != nil {
+		s.writeError(w, err)
+		return false
+	}
+	return true
+}
+
+// handleAddPolicyRule
+//
+// @Summary Add booking policy rule
+// @Description Creates function which adds a business limit checked on booking creation and change, e.g.
+// @Description minimal duration or maximal hours per user per week. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param rule body models.PolicyRule true "name, kind, value and optional resource_type and role scope"
+//
+// @Success 201 {object} models.PolicyRule "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /policy-rules [post]
+func (s *Server) handleAddPolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newRule models.PolicyRule
+
+		if err := json.NewDecoder(r.Body).Decode(&newRule); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newRule); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		time := time.Now()
+		newRule.CreatedAt = time
+		newRule.UpdatedAt = time
+
+		query := "INSERT INTO policy_rules (name,kind,value,resource_type,role,disabled,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newRule.Name, newRule.Kind, newRule.Value, newRule.ResourceType, newRule.Role, newRule.Disabled, newRule.CreatedAt, newRule.UpdatedAt).Scan(&newRule.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newRule)
+		s.logger.Debug("Successefully added policy rule to database")
+	}
+}
+
+// handleGetPolicyRule
+//
+// @Summary Get booking policy rule
+// @Description Creates function which retrieves policy rule specified by id from database
+// @Produces json
+//
+// @Param id path int true "Policy rule ID"
+//
+// @Success 200 {object} models.PolicyRule "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /policy-rules/{id} [get]
+func (s *Server) handleGetPolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var rule models.PolicyRule
+
+		query := "SELECT " + policyRuleColumns + " FROM policy_rules WHERE id=$1"
+
+		err := scanPolicyRule(s.database.QueryRow(context.Background(), query, id), &rule)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, rule)
+		s.logger.Debug("Successfully retrieved policy rule data")
+	}
+}
+
+// handleGetPolicyRules
+//
+// @Summary Get booking policy
+// @Description Creates function which retrieves all policy rules, optionally only those which apply to a
+// @Description resource type or a user role
+// @Produces json
+//
+// @Param resource_type query string false "Resource type"
+// @Param role query string false "User role"
+//
+// @Success 200 {array} models.PolicyRule "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /policy-rules [get]
+func (s *Server) handleGetPolicyRules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var ruleList []models.PolicyRule
+
+		resourceType := r.URL.Query().Get("resource_type")
+		role := r.URL.Query().Get("role")
+
+		query := `SELECT ` + policyRuleColumns + ` FROM policy_rules
+			WHERE ($1 = '' OR resource_type IS NULL OR resource_type = $1) AND ($2 = '' OR role IS NULL OR role = $2) ORDER BY id`
+		data, err := s.database.Query(context.Background(), query, resourceType, role)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var rule models.PolicyRule
+			err = scanPolicyRule(data, &rule)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			ruleList = append(ruleList, rule)
+		}
+
+		if len(ruleList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, ruleList)
+		s.logger.Debug("Successfully retrieved policy rules data")
+	}
+}
+
+// handleUpdatePolicyRule
+//
+// @Summary Replace booking policy rule
+// @Description Creates function which replaces policy rule specified by id. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Policy rule ID"
+// @Param rule body models.PolicyRule true "name, kind, value and optional resource_type and role scope"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /policy-rules/{id} [put]
+func (s *Server) handleUpdatePolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newRuleData models.PolicyRule
+
+		if err := json.NewDecoder(r.Body).Decode(&newRuleData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newRuleData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "UPDATE policy_rules SET name=$2, kind=$3, value=$4, resource_type=$5, role=$6, disabled=$7, updated_at=$8 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newRuleData.Name, newRuleData.Kind, newRuleData.Value, newRuleData.ResourceType, newRuleData.Role, newRuleData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated policy rule in database")
+	}
+}
+
+// handleDeletePolicyRule
+//
+// @Summary Delete booking policy rule
+// @Description Creates function which deletes policy rule specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Policy rule ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /policy-rules/{id} [delete]
+func (s *Server) handleDeletePolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM policy_rules WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified policy rule; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified policy rule; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified policy rule from database")
+	}
+}
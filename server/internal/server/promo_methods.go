@@ -0,0 +1,442 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// activeRedemption filters redemptions r which count towards the limits of their code: those of
+// bookings which are not cancelled and those of purged bookings
+const activeRedemption = "(r.booking_id IS NULL OR EXISTS (SELECT 1 FROM bookings WHERE bookings.id=r.booking_id AND " + activeBooking + "))"
+
+const promoCodeColumns = `id, code, kind, value, resource_types, valid_from, valid_until, max_redemptions, max_per_user,
+	(SELECT COUNT(*) FROM promo_redemptions r WHERE r.promo_code_id=promo_codes.id AND ` + activeRedemption + `), disabled, created_at, updated_at`
+
+func scanPromoCode(row pgx.Row, p *models.PromoCode) error {
+	return row.Scan(&p.Id, &p.Code, &p.Kind, &p.Value, &p.ResourceTypes, &p.ValidFrom, &p.ValidUntil, &p.MaxRedemptions, &p.MaxPerUser,
+		&p.Redemptions, &p.Disabled, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// findPromo returns the promo code of b: the one redeemed for it if b exists, otherwise b.PromoCode
+// once it is checked that the code can be redeemed for b. The code is locked until the transaction
+// of q ends, so concurrent bookings can not redeem it beyond its limits.
+func (s *Server) findPromo(ctx context.Context, q querier, b models.Booking) (*pricing.Promo, error) {
+	if b.Id != 0 {
+		var promo pricing.Promo
+		query := "SELECT p.id, p.code, p.kind, p.value FROM promo_redemptions r JOIN promo_codes p ON p.id=r.promo_code_id WHERE r.booking_id=$1"
+		err := q.QueryRow(ctx, query, b.Id).Scan(&promo.Id, &promo.Code, &promo.Kind, &promo.Value)
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return &promo, nil
+	}
+	if b.PromoCode == "" {
+		return nil, nil
+	}
+
+	var code models.PromoCode
+	err := scanPromoCode(q.QueryRow(ctx, "SELECT "+promoCodeColumns+" FROM promo_codes WHERE UPPER(code)=UPPER($1) FOR UPDATE", b.PromoCode), &code)
+	if err == pgx.ErrNoRows || err == nil && code.Disabled {
+		return nil, newError(KindInvalid, "PromoError: promo code %q does not exist", b.PromoCode)
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if code.ValidFrom != nil && now.Before(*code.ValidFrom) {
+		return nil, newError(KindInvalid, "PromoError: promo code %q is valid from %s", code.Code, code.ValidFrom.In(s.location).Format(time.RFC3339))
+	}
+	if code.ValidUntil != nil && !now.Before(*code.ValidUntil) {
+		return nil, newError(KindInvalid, "PromoError: promo code %q expired at %s", code.Code, code.ValidUntil.In(s.location).Format(time.RFC3339))
+	}
+
+	if len(code.ResourceTypes) > 0 {
+		var resourceType string
+		if err := q.QueryRow(ctx, "SELECT type FROM resources WHERE id=$1", b.ResourceId).Scan(&resourceType); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(code.ResourceTypes, resourceType) {
+			return nil, newError(KindInvalid, "PromoError: promo code %q does not apply to resources of type %q", code.Code, resourceType)
+		}
+	}
+
+	if code.MaxRedemptions != nil && code.Redemptions >= *code.MaxRedemptions {
+		return nil, newError(KindInvalid, "PromoError: promo code %q has been fully redeemed", code.Code)
+	}
+	if code.MaxPerUser != nil && b.UserId != 0 {
+		var redeemed int
+		query := "SELECT COUNT(*) FROM promo_redemptions r WHERE r.promo_code_id=$1 AND r.user_id=$2 AND " + activeRedemption
+		if err := q.QueryRow(ctx, query, code.Id, b.UserId).Scan(&redeemed); err != nil {
+			return nil, err
+		}
+		if redeemed >= *code.MaxPerUser {
+			return nil, newError(KindInvalid, "PromoError: user {%d} has already redeemed promo code %q %d times", b.UserId, code.Code, redeemed)
+		}
+	}
+
+	return &pricing.Promo{Id: code.Id, Code: code.Code, Kind: code.Kind, Value: code.Value}, nil
+}
+
+// saveRedemption records the promo code in the price breakdown of b as redeemed by it, or updates
+// the amount it took off when b was repriced
+func saveRedemption(ctx context.Context, tx pgx.Tx, b models.Booking) error {
+	if b.PriceBreakdown == nil || b.PriceBreakdown.Promo == nil {
+		return nil
+	}
+
+	promo := b.PriceBreakdown.Promo
+	query := `INSERT INTO promo_redemptions (promo_code_id,user_id,booking_id,amount) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (booking_id) DO UPDATE SET amount=EXCLUDED.amount`
+	_, err := tx.Exec(ctx, query, promo.PromoCodeId, b.UserId, b.Id, promo.Amount)
+	return err
+}
+
+// validatePromoCode checks p beyond its validation tags
+func validatePromoCode(p models.PromoCode) error {
+	if err := validator.V.Struct(p); err != nil {
+		return err
+	}
+	if p.Kind == pricing.PromoPercent && p.Value > 100 {
+		return fmt.Errorf("percent promo code can not take off more than 100%%")
+	}
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		return fmt.Errorf("valid_until is before valid_from")
+	}
+	return nil
+}
+
+// parseOptionalTime reads query parameter name in RFC 3339 format; it is nil when missing
+func parseOptionalTime(r *http.Request, name string) (*time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &t, nil
+}
+
+// handleAddPromoCode
+//
+// @Summary Add promo code
+// @Description Creates function which adds a percentage or fixed promo code, optionally limited to a validity window,
+// @Description a number of redemptions in total and per user and to resource types. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param code body models.PromoCode true "code, kind, value and optional resource_types, valid_from, valid_until, max_redemptions and max_per_user"
+//
+// @Success 201 {object} models.PromoCode "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /promo-codes [post]
+func (s *Server) handleAddPromoCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newCode models.PromoCode
+
+		if err := json.NewDecoder(r.Body).Decode(&newCode); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validatePromoCode(newCode); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newCode.ResourceTypes == nil {
+			newCode.ResourceTypes = []string{}
+		}
+
+		time := time.Now()
+		newCode.CreatedAt = time
+		newCode.UpdatedAt = time
+		newCode.Redemptions = 0
+
+		query := `INSERT INTO promo_codes (code,kind,value,resource_types,valid_from,valid_until,max_redemptions,max_per_user,disabled,created_at,updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING id`
+
+		err := s.database.QueryRow(context.Background(), query, newCode.Code, newCode.Kind, newCode.Value, newCode.ResourceTypes, newCode.ValidFrom, newCode.ValidUntil,
+			newCode.MaxRedemptions, newCode.MaxPerUser, newCode.Disabled, newCode.CreatedAt, newCode.UpdatedAt).Scan(&newCode.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newCode)
+		s.logger.Debug("Successefully added promo code to database")
+	}
+}
+
+// handleGetPromoCode
+//
+// @Summary Get promo code
+// @Description Creates function which retrieves promo code specified by id with the number of its redemptions.
+// @Description Requires basic auth of staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Promo code ID"
+//
+// @Success 200 {object} models.PromoCode "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /promo-codes/{id} [get]
+func (s *Server) handleGetPromoCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var code models.PromoCode
+
+		query := "SELECT " + promoCodeColumns + " FROM promo_codes WHERE id=$1"
+
+		err := scanPromoCode(s.database.QueryRow(context.Background(), query, id), &code)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, code)
+		s.logger.Debug("Successfully retrieved promo code data")
+	}
+}
+
+// handleGetPromoCodes
+//
+// @Summary Get promo codes
+// @Description Creates function which retrieves all promo codes with the number of their redemptions. Requires basic
+// @Description auth of staff or an admin.
+// @Produces json
+//
+// @Success 200 {array} models.PromoCode "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /promo-codes [get]
+func (s *Server) handleGetPromoCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var codeList []models.PromoCode
+
+		data, err := s.database.Query(context.Background(), "SELECT "+promoCodeColumns+" FROM promo_codes ORDER BY id")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var code models.PromoCode
+			err = scanPromoCode(data, &code)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			codeList = append(codeList, code)
+		}
+
+		if len(codeList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, codeList)
+		s.logger.Debug("Successfully retrieved promo codes data")
+	}
+}
+
+// handleUpdatePromoCode
+//
+// @Summary Replace promo code
+// @Description Creates function which replaces promo code specified by id. Existing redemptions are kept and count
+// @Description towards the new limits. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Promo code ID"
+// @Param code body models.PromoCode true "code, kind, value and optional resource_types, valid_from, valid_until, max_redemptions and max_per_user"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /promo-codes/{id} [put]
+func (s *Server) handleUpdatePromoCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newCodeData models.PromoCode
+
+		if err := json.NewDecoder(r.Body).Decode(&newCodeData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validatePromoCode(newCodeData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newCodeData.ResourceTypes == nil {
+			newCodeData.ResourceTypes = []string{}
+		}
+
+		query := `UPDATE promo_codes SET code=$2, kind=$3, value=$4, resource_types=$5, valid_from=$6, valid_until=$7, max_redemptions=$8,
+			max_per_user=$9, disabled=$10, updated_at=$11 WHERE id=$1`
+
+		tag, err := s.database.Exec(context.Background(), query, id, newCodeData.Code, newCodeData.Kind, newCodeData.Value, newCodeData.ResourceTypes, newCodeData.ValidFrom,
+			newCodeData.ValidUntil, newCodeData.MaxRedemptions, newCodeData.MaxPerUser, newCodeData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated promo code in database")
+	}
+}
+
+// handleDeletePromoCode
+//
+// @Summary Delete promo code
+// @Description Creates function which deletes promo code specified by id. Codes which were redeemed are kept for
+// @Description redemption reports and can only be disabled. Requires basic auth of an admin.
+//
+// @Param id path int true "Promo code ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /promo-codes/{id} [delete]
+func (s *Server) handleDeletePromoCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM promo_codes WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified promo code; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified promo code; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified promo code from database")
+	}
+}
+
+// handleGetPromoRedemptions
+//
+// @Summary Get promo code redemptions
+// @Description Creates function which retrieves redemptions of promo code specified by id, latest first, optionally
+// @Description only those made within [from, to). Redemptions of cancelled bookings are reported with their booking
+// @Description status. Requires basic auth of staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Promo code ID"
+// @Param from query string false "Start of the range, RFC 3339"
+// @Param to query string false "End of the range, RFC 3339"
+//
+// @Success 200 {array} models.PromoRedemption "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /promo-codes/{id}/redemptions [get]
+func (s *Server) handleGetPromoRedemptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		from, err := parseOptionalTime(r, "from")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		to, err := parseOptionalTime(r, "to")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		var redemptionList []models.PromoRedemption
+
+		query := `SELECT r.id, r.promo_code_id, p.code, r.user_id, r.booking_id, COALESCE(b.status, ''), r.amount, r.created_at
+			FROM promo_redemptions r JOIN promo_codes p ON p.id=r.promo_code_id LEFT JOIN bookings b ON b.id=r.booking_id
+			WHERE r.promo_code_id=$1 AND ($2::timestamptz IS NULL OR r.created_at >= $2) AND ($3::timestamptz IS NULL OR r.created_at < $3)
+			ORDER BY r.id DESC`
+		data, err := s.database.Query(context.Background(), query, id, from, to)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var rd models.PromoRedemption
+			err = data.Scan(&rd.Id, &rd.PromoCodeId, &rd.Code, &rd.UserId, &rd.BookingId, &rd.BookingStatus, &rd.Amount, &rd.CreatedAt)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			redemptionList = append(redemptionList, rd)
+		}
+
+		if len(redemptionList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, redemptionList)
+		s.logger.Debug("Successfully retrieved promo code redemptions data")
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+// addTestPromoCode adds a fixed promo code called code with the limits and returns its id
+func addTestPromoCode(t *testing.T, s *Server, code string, maxRedemptions, maxPerUser *int) int {
+	t.Helper()
+	var id int
+	query := "INSERT INTO promo_codes (code, kind, value, max_redemptions, max_per_user) VALUES ($1, 'fixed', 100, $2, $3) RETURNING id"
+	if err := s.database.QueryRow(context.Background(), query, code, maxRedemptions, maxPerUser).Scan(&id); err != nil {
+		t.Fatalf("failed to add promo code %s: %s", code, err)
+	}
+	return id
+}
+
+func TestConcurrentPromoRedemptions(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	resourceId := addTestResource(t, s, "court", 1)
+	limit := 2
+	codeId := addTestPromoCode(t, s, "SPRING", &limit, nil)
+
+	// every booking takes its own slot, so only the promo code limits them
+	const bookings = 5
+	users := make([]int, bookings)
+	for i := range users {
+		users[i] = addTestUser(t, s, fmt.Sprintf("member%d", i))
+	}
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	errs := parallel(bookings, func(i int) error {
+		from := start.Add(time.Duration(i) * time.Hour)
+		b := models.Booking{UserId: users[i], ResourceId: resourceId, StartTime: from, EndTime: from.Add(time.Hour), Text: "match", PromoCode: "spring"}
+		_, _, err := s.CreateBooking(ctx, b, false)
+		return err
+	})
+
+	assertRedeemed(t, s, codeId, errs, limit)
+}
+
+func TestConcurrentPromoRedemptionsPerUser(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+	limit := 1
+	codeId := addTestPromoCode(t, s, "WELCOME", nil, &limit)
+
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	errs := parallel(3, func(i int) error {
+		from := start.Add(time.Duration(i) * time.Hour)
+		b := models.Booking{UserId: userId, ResourceId: resourceId, StartTime: from, EndTime: from.Add(time.Hour), Text: "match", PromoCode: "WELCOME"}
+		_, _, err := s.CreateBooking(ctx, b, false)
+		return err
+	})
+
+	assertRedeemed(t, s, codeId, errs, limit)
+}
+
+// assertRedeemed checks that want of the bookings which returned errs were created and redeemed
+// promo code codeId, and that the others were refused as invalid
+func assertRedeemed(t *testing.T, s *Server, codeId int, errs []error, want int) {
+	t.Helper()
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errorKind(err) != KindInvalid:
+			t.Errorf("CreateBooking failed: %s", err)
+		}
+	}
+	if created != want {
+		t.Errorf("%d bookings were created, want %d", created, want)
+	}
+
+	var redeemed, booked int
+	query := "SELECT (SELECT COUNT(*) FROM promo_redemptions WHERE promo_code_id=$1), (SELECT COUNT(*) FROM bookings)"
+	if err := s.database.QueryRow(context.Background(), query, codeId).Scan(&redeemed, &booked); err != nil {
+		t.Fatal(err)
+	}
+	if redeemed != want || booked != want {
+		t.Errorf("%d redemptions of %d bookings were saved, want %d of %d", redeemed, booked, want, want)
+	}
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is implemented by both *pgxpool.Pool and pgx.Tx, so helpers below can be
+// used inside and outside of transactions
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// advisory lock classes, used as the first key of pg_advisory_xact_lock
+const (
+	lockClassResource = 1
+	lockClassUser     = 2
+	lockClassOutbox   = 3
+)
+
+const bookingColumns = "id, user_id, resource_id, series_id, occurrence_start, overridden, group_id, start_time, end_time, party_size, text, status, hold_expires_at, sequence, price, price_breakdown, cancellation_fee, updated_at"
+
+// activeBooking filters out cancelled bookings, which no longer occupy their slot
+const activeBooking = "status <> '" + models.BookingCancelled + "'"
+
+// cancelBookings is the beginning of a statement which cancels bookings matching the condition appended to it
+const cancelBookings = "UPDATE bookings SET status='" + models.BookingCancelled + "', hold_expires_at=NULL, sequence=sequence+1, updated_at=NOW() WHERE " + activeBooking + " AND "
+
+func scanBooking(row pgx.Row, b *models.Booking) error {
+	return row.Scan(&b.Id, &b.UserId, &b.ResourceId, &b.SeriesId, &b.OccurrenceStart, &b.Overridden, &b.GroupId, &b.StartTime, &b.EndTime, &b.PartySize, &b.Text, &b.Status, &b.HoldExpiresAt, &b.Sequence, &b.Price, &b.PriceBreakdown, &b.CancellationFee, &b.UpdatedAt)
+}
+
+// collectBookings scans all rows, e.g. of a statement returning bookingColumns
+func collectBookings(rows pgx.Rows) ([]models.Booking, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Booking, error) {
+		var b models.Booking
+		err := scanBooking(row, &b)
+		return b, err
+	})
+}
+
+// cancelBookingsWhere cancels active bookings matching condition, refunds what was paid for them
+// and returns them
+func cancelBookingsWhere(ctx context.Context, tx pgx.Tx, condition string, args ...any) ([]models.Booking, error) {
+	cancelled, _, err := cancelAndRefund(ctx, tx, nil, condition, args...)
+	return cancelled, err
+}
+
+// feeFunc returns the cancellation of a booking with its fee set
+type feeFunc func(ctx context.Context, b models.Booking) (models.Cancellation, error)
+
+// cancelAndRefund cancels active bookings matching condition and refunds what was paid for them
+// less the fee set by fee, unless it is nil. It returns the bookings and their cancellations.
+func cancelAndRefund(ctx context.Context, tx pgx.Tx, fee feeFunc, condition string, args ...any) ([]models.Booking, []models.Cancellation, error) {
+	rows, err := tx.Query(ctx, cancelBookings+condition+" RETURNING "+bookingColumns, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cancelled, err := collectBookings(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancellations := make([]models.Cancellation, len(cancelled))
+	for i := range cancelled {
+		b := &cancelled[i]
+
+		c := models.Cancellation{BookingId: b.Id, Price: b.Price, Reason: "Cancelled free of charge"}
+		if fee != nil {
+			if c, err = fee(ctx, *b); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if c.Fee > 0 {
+			if _, err := tx.Exec(ctx, "UPDATE bookings SET cancellation_fee=$2 WHERE id=$1", b.Id, c.Fee); err != nil {
+				return nil, nil, err
+			}
+			b.CancellationFee = c.Fee
+		}
+
+		if err := refundBooking(ctx, tx, *b, &c); err != nil {
+			return nil, nil, err
+		}
+		cancellations[i] = c
+	}
+	return cancelled, cancellations, nil
+}
+
+func getBooking(ctx context.Context, q querier, id int) (models.Booking, error) {
+	var b models.Booking
+	err := scanBooking(q.QueryRow(ctx, "SELECT "+bookingColumns+" FROM bookings WHERE id=$1", id), &b)
+	return b, err
+}
+
+// getUser returns user specified by id without the password hash, which only Authenticate reads
+func getUser(ctx context.Context, q querier, id int) (models.User, error) {
+	var u models.User
+	err := q.QueryRow(ctx, "SELECT id, username, role, priority, created_at, updated_at FROM users WHERE id=$1", id).
+		Scan(&u.Id, &u.Username, &u.Role, &u.Priority, &u.CreatedAt, &u.UpdatedAt)
+	return u, err
+}
+
+// lockResource serializes booking changes on a resource until the transaction ends,
+// so concurrent requests cannot both pass the overlap check
+func lockResource(ctx context.Context, tx pgx.Tx, resourceId int) error {
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, $2)", lockClassResource, resourceId)
+	return err
+}
+
+// lockResources locks several resources in ascending id order to avoid deadlocks
+func lockResources(ctx context.Context, tx pgx.Tx, resourceIds ...int) error {
+	ids := slices.Clone(resourceIds)
+	slices.Sort(ids)
+	for _, id := range slices.Compact(ids) {
+		if err := lockResource(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockUser serializes booking changes of a user until the transaction ends,
+// so per-user limits are checked against up to date usage
+func lockUser(ctx context.Context, tx pgx.Tx, userId int) error {
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, $2)", lockClassUser, userId)
+	return err
+}
+
+// findOverlap returns id of a booking on resourceId which overlaps [start, end) and leaves no room
+// for partySize more people, or 0 if the slot is free. Resources hold a single party unless they have
+// a capacity, then overlapping bookings only conflict where their parties together exceed it.
+// Bookings block the resource for the buffers of the resource before and after them, so two bookings
+// must be apart by both buffers. Booking with id excludeId is not taken into account.
+func findOverlap(ctx context.Context, q querier, resourceId int, start, end time.Time, partySize, excludeId int) (int, error) {
+	limits, err := getResourceLimits(ctx, q, resourceId)
+	if err != nil {
+		return 0, err
+	}
+
+	query := "SELECT id, start_time, end_time, party_size FROM bookings WHERE resource_id=$1 AND start_time < $3 AND end_time > $2 AND id <> $4 AND " + activeBooking + " ORDER BY start_time"
+	rows, err := q.Query(ctx, query, resourceId, start.Add(-limits.gap()), end.Add(limits.gap()), excludeId)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	var bookings []schedule.Occupant
+	for rows.Next() {
+		var id int
+		var o schedule.Occupant
+		if err := rows.Scan(&id, &o.Start, &o.End, &o.Size); err != nil {
+			return 0, err
+		}
+		ids = append(ids, id)
+		bookings = append(bookings, o)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if i := limits.conflict(bookings, start, end, partySize); i >= 0 {
+		return ids[i], nil
+	}
+	return 0, nil
+}
+
+// resourceLimits are the rules of a resource which decide whether bookings can overlap
+type resourceLimits struct {
+	capacity int
+	before   time.Duration
+	after    time.Duration
+}
+
+// gap is the free time kept between two bookings: cleanup after the first and setup before the second
+func (l resourceLimits) gap() time.Duration {
+	return l.before + l.after
+}
+
+// block extends o by the buffers to the interval during which it blocks the resource
+func (l resourceLimits) block(o schedule.Occupant) schedule.Occupant {
+	o.Start, o.End = o.Start.Add(-l.before), o.End.Add(l.after)
+	return o
+}
+
+// conflict returns the index of one of bookings which leaves no room for partySize more people
+// during [start, end), or -1 if they fit. Bookings and the new one block the resource for the buffers.
+func (l resourceLimits) conflict(bookings []schedule.Occupant, start, end time.Time, partySize int) int {
+	occupants := make([]schedule.Occupant, len(bookings))
+	for i, b := range bookings {
+		occupants[i] = l.block(b)
+	}
+
+	blocked := l.block(schedule.Occupant{Start: start, End: end})
+	if peak, i := schedule.Peak(occupants, blocked.Start, blocked.End); i >= 0 && peak+max(partySize, 1) > l.capacity {
+		return i
+	}
+	return -1
+}
+
+// getResourceLimits returns capacity and buffers of resourceId; resources which do not exist hold
+// a single party without buffers
+func getResourceLimits(ctx context.Context, q querier, resourceId int) (resourceLimits, error) {
+	limits := resourceLimits{capacity: 1}
+
+	var before, after int
+	err := q.QueryRow(ctx, "SELECT capacity, buffer_before, buffer_after FROM resources WHERE id=$1", resourceId).Scan(&limits.capacity, &before, &after)
+	if err == pgx.ErrNoRows {
+		return limits, nil
+	}
+	limits.before, limits.after = time.Duration(before)*time.Minute, time.Duration(after)*time.Minute
+	return limits, err
+}
+
+// checkPartySize returns an error if a party of partySize people does not fit into resourceId
+// even when nothing else is booked
+func checkPartySize(ctx context.Context, q querier, resourceId, partySize int) error {
+	limits, err := getResourceLimits(ctx, q, resourceId)
+	if err != nil {
+		return newError(KindInternal, "Failed to check capacity; additional info: %s", err)
+	}
+	if partySize > limits.capacity {
+		return newError(KindInvalid, "CapacityError: party of %d does not fit into resource {%d} with capacity %d", partySize, resourceId, limits.capacity)
+	}
+	return nil
+}
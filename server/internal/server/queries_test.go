@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+)
+
+func TestResourceLimitsConflict(t *testing.T) {
+	start := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	// 12:00-13:00 for a single party and for a party of 2
+	single := []schedule.Occupant{{Start: at(0), End: at(60), Size: 1}}
+	booked := []schedule.Occupant{{Start: at(0), End: at(60), Size: 2}}
+	buffered := resourceLimits{capacity: 1, before: 10 * time.Minute, after: 15 * time.Minute}
+	lounge := resourceLimits{capacity: 4}
+
+	tests := []struct {
+		name       string
+		limits     resourceLimits
+		bookings   []schedule.Occupant
+		start, end time.Time
+		partySize  int
+		want       int
+	}{
+		{"no bookings", buffered, nil, at(0), at(60), 1, -1},
+		{"overlap", buffered, single, at(30), at(90), 1, 0},
+		{"right after the booking, during its cleanup", buffered, single, at(60), at(120), 1, 0},
+		{"after the cleanup, during own setup", buffered, single, at(75), at(135), 1, 0},
+		{"after the cleanup and own setup", buffered, single, at(85), at(145), 1, -1},
+		{"cleanup overlaps the setup of the next booking", buffered, single, at(-60), at(-10), 1, 0},
+		{"before the setup of the next booking", buffered, single, at(-85), at(-25), 1, -1},
+		{"without buffers right after", lounge, []schedule.Occupant{{Start: at(0), End: at(60), Size: 4}}, at(60), at(120), 4, -1},
+		{"party fits next to another", lounge, booked, at(30), at(90), 2, -1},
+		{"party exceeds capacity", lounge, booked, at(30), at(90), 3, 0},
+		{
+			"parties fit at every moment although more overlap in total", lounge,
+			[]schedule.Occupant{{Start: at(0), End: at(60), Size: 2}, {Start: at(60), End: at(120), Size: 2}},
+			at(30), at(90), 2, -1,
+		},
+		{
+			"second of two overlapping bookings is full", lounge,
+			[]schedule.Occupant{{Start: at(0), End: at(30), Size: 1}, {Start: at(45), End: at(120), Size: 3}},
+			at(0), at(60), 2, 1,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limits.conflict(tt.bookings, tt.start, tt.end, tt.partySize); got != tt.want {
+			t.Errorf("%s: conflict = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindOverlap(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	court := addTestResource(t, s, "court", 1)
+	lounge := addTestResource(t, s, "lounge", 4)
+	if _, err := s.database.Exec(ctx, "UPDATE resources SET buffer_before=10, buffer_after=15 WHERE id=$1", court); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2030, 1, 7, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	// 12:00-13:00 for a single party on the court and for a party of 2 in the lounge
+	ids := map[int]int{}
+	for resourceId, partySize := range map[int]int{court: 1, lounge: 2} {
+		query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text, party_size) VALUES ($1, $2, $3, $4, '', $5) RETURNING id"
+		var id int
+		if err := s.database.QueryRow(ctx, query, userId, resourceId, at(0), at(60), partySize).Scan(&id); err != nil {
+			t.Fatalf("failed to add booking: %s", err)
+		}
+		ids[resourceId] = id
+	}
+
+	tests := []struct {
+		name       string
+		resourceId int
+		start, end time.Time
+		partySize  int
+		excludeId  int
+		want       int
+	}{
+		{"during the cleanup", court, at(60), at(120), 1, 0, ids[court]},
+		{"after cleanup and setup", court, at(85), at(145), 1, 0, 0},
+		{"the booking itself", court, at(0), at(60), 1, ids[court], 0},
+		{"party fits", lounge, at(30), at(90), 2, 0, 0},
+		{"party exceeds capacity", lounge, at(30), at(90), 3, 0, ids[lounge]},
+	}
+
+	for _, tt := range tests {
+		got, err := findOverlap(ctx, s.database, tt.resourceId, tt.start, tt.end, tt.partySize, tt.excludeId)
+		if err != nil {
+			t.Fatalf("%s: findOverlap failed: %s", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: findOverlap = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+func getResource(ctx context.Context, q querier, id int) (models.Resource, error) {
+	var r models.Resource
+	err := scanResource(q.QueryRow(ctx, "SELECT "+resourceColumns+" FROM resources WHERE id=$1", id), &r)
+	return r, err
+}
+
+// checkCompatible returns an error unless bookings of source can be moved to target
+func checkCompatible(source, target models.Resource) error {
+	if source.Id == target.Id {
+		return newError(KindInvalid, "RelocationError: bookings can not be moved to the resource {%d} they are on", source.Id)
+	}
+	if source.Type != target.Type || source.Zone != target.Zone {
+		return newError(KindInvalid, "RelocationError: resource {%d} (%s, %s) is not compatible with resource {%d} (%s, %s), type and zone must match",
+			target.Id, target.Type, target.Zone, source.Id, source.Type, source.Zone)
+	}
+	return nil
+}
+
+// fitConflict returns the conflict which keeps b from being moved to resourceId, or nil if it fits:
+// its party must fit into the capacity, the time must be open and free on the resource
+func (s *Server) fitConflict(ctx context.Context, tx pgx.Tx, b models.Booking, resourceId int) (*models.Conflict, error) {
+	conflict := models.Conflict{BookingId: b.Id, ResourceId: resourceId, StartTime: b.StartTime, EndTime: b.EndTime}
+
+	limits, err := getResourceLimits(ctx, tx, resourceId)
+	if err != nil {
+		return nil, err
+	}
+	if b.PartySize > limits.capacity {
+		conflict.Rule, conflict.Reason = "capacity", fmt.Sprintf("party of %d does not fit into capacity %d", b.PartySize, limits.capacity)
+		return &conflict, nil
+	}
+
+	err = s.checkSchedule(ctx, tx, resourceId, b.StartTime, b.EndTime)
+	var violation *schedule.Violation
+	if errors.As(err, &violation) {
+		conflict.Rule, conflict.Reason = violation.Rule, violation.Message
+		return &conflict, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	conflictId, err := findOverlap(ctx, tx, resourceId, b.StartTime, b.EndTime, b.PartySize, b.Id)
+	if err != nil {
+		return nil, err
+	}
+	if conflictId != 0 {
+		conflict.ConflictBookingId = conflictId
+		return &conflict, nil
+	}
+	return nil, nil
+}
+
+// moveBooking moves b to resourceId keeping its price, records the change as kind made by
+// changedBy and publishes it. The resources must be locked.
+func (s *Server) moveBooking(ctx context.Context, tx pgx.Tx, b models.Booking, resourceId int, kind string, changedBy int) (models.Booking, error) {
+	before := b
+
+	query := "UPDATE bookings SET resource_id=$2, overridden=series_id IS NOT NULL, sequence=sequence+1, updated_at=NOW() WHERE id=$1 RETURNING " + bookingColumns
+	if err := scanBooking(tx.QueryRow(ctx, query, b.Id, resourceId), &b); err != nil {
+		return b, err
+	}
+	if err := recordChange(ctx, tx, kind, before, b, &changedBy); err != nil {
+		return b, err
+	}
+	return b, s.publish(ctx, tx, outbox.BookingUpdated, b)
+}
+
+// RelocateBookings moves confirmed and pending bookings of resource id selected by rel to compatible
+// resources on behalf of staff member staffId. Bookings are visited by start time and each one is
+// moved to the first candidate it fits into, so later bookings are checked against earlier moves.
+// With dryRun the result is reported but nothing is written.
+func (s *Server) RelocateBookings(ctx context.Context, id int, rel models.Relocation, dryRun bool, staffId int) (models.RelocationResult, error) {
+	result := models.RelocationResult{ResourceId: id, Mode: rel.Mode, DryRun: dryRun, Moves: []models.Move{}, Conflicts: []models.Conflict{}}
+	if result.Mode == "" {
+		result.Mode = models.RelocationModeAllOrNothing
+	}
+
+	if err := validator.V.Struct(rel); err != nil {
+		return result, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+	if rel.From.IsZero() {
+		rel.From = time.Now()
+	}
+	if !rel.To.IsZero() && !rel.To.After(rel.From) {
+		return result, newError(KindInvalid, "TimeError: to is before from")
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return result, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	source, err := getResource(ctx, tx, id)
+	if err == pgx.ErrNoRows {
+		return result, notFound(id)
+	} else if err != nil {
+		return result, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+
+	var candidates []models.Resource
+	if rel.TargetId != 0 {
+		target, err := getResource(ctx, tx, rel.TargetId)
+		if err == pgx.ErrNoRows {
+			return result, notFound(rel.TargetId)
+		} else if err != nil {
+			return result, newError(KindInternal, "Internal error; more info: %s", err)
+		}
+		if err := checkCompatible(source, target); err != nil {
+			return result, err
+		}
+		candidates = []models.Resource{target}
+	} else {
+		rows, err := tx.Query(ctx, "SELECT "+resourceColumns+" FROM resources WHERE type=$1 AND zone=$2 AND id <> $3 ORDER BY id", source.Type, source.Zone, source.Id)
+		if err != nil {
+			return result, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+		}
+		candidates, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Resource, error) {
+			var r models.Resource
+			err := scanResource(row, &r)
+			return r, err
+		})
+		if err != nil {
+			return result, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+		}
+		if len(candidates) == 0 {
+			return result, newError(KindInvalid, "RelocationError: there is no other resource of type %q in zone %q", source.Type, source.Zone)
+		}
+	}
+
+	resourceIds := []int{source.Id}
+	for _, c := range candidates {
+		resourceIds = append(resourceIds, c.Id)
+	}
+	if err := lockResources(ctx, tx, resourceIds...); err != nil {
+		return result, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	// held bookings are left to their waitlist offers, which expire on their own
+	args := []any{id, rel.From, []string{models.BookingConfirmed, models.BookingPending}}
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE resource_id=$1 AND end_time > $2 AND status=ANY($3)"
+	if !rel.To.IsZero() {
+		args = append(args, rel.To)
+		query += " AND start_time < $4"
+	}
+	rows, err := tx.Query(ctx, query+" ORDER BY start_time, id", args...)
+	if err != nil {
+		return result, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	bookings, err := collectBookings(rows)
+	if err != nil {
+		return result, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+	}
+
+	for _, b := range bookings {
+		var conflict *models.Conflict
+		for _, c := range candidates {
+			if conflict, err = s.fitConflict(ctx, tx, b, c.Id); err != nil {
+				return result, newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+			}
+			if conflict != nil {
+				continue
+			}
+
+			if _, err := s.moveBooking(ctx, tx, b, c.Id, models.ChangeRelocated, staffId); err != nil {
+				return result, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+			}
+			result.Moves = append(result.Moves, models.Move{BookingId: b.Id, UserId: b.UserId, FromResourceId: id, ToResourceId: c.Id, StartTime: b.StartTime, EndTime: b.EndTime})
+			break
+		}
+
+		if conflict != nil {
+			// with several candidates the reason of a single one would be misleading
+			if len(candidates) > 1 {
+				conflict = &models.Conflict{BookingId: b.Id, StartTime: b.StartTime, EndTime: b.EndTime, Rule: "relocation",
+					Reason: fmt.Sprintf("none of %d compatible resources is free", len(candidates))}
+			}
+			result.Conflicts = append(result.Conflicts, *conflict)
+		}
+	}
+
+	if dryRun || result.Mode == models.RelocationModeAllOrNothing && len(result.Conflicts) > 0 {
+		return result, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	result.Moved = len(result.Moves)
+	return result, nil
+}
+
+// checkSwap returns a conflict error unless b fits into resourceId
+func (s *Server) checkSwap(ctx context.Context, tx pgx.Tx, b models.Booking, resourceId int) error {
+	conflict, err := s.fitConflict(ctx, tx, b, resourceId)
+	if err != nil {
+		return newError(KindInternal, "Failed to check time slot; additional info: %s", err)
+	}
+	if conflict == nil {
+		return nil
+	}
+
+	reason := conflict.Reason
+	if conflict.ConflictBookingId != 0 {
+		reason = fmt.Sprintf("time slot is already taken by booking with id {%d}", conflict.ConflictBookingId)
+	}
+	return newError(KindConflict, "SwapError: booking {%d} does not fit into resource {%d}: %s", b.Id, resourceId, reason)
+}
+
+// SwapBookings exchanges the resources of bookings ids on behalf of staff member staffId and
+// returns both bookings
+func (s *Server) SwapBookings(ctx context.Context, swap models.Swap, staffId int) ([]models.Booking, error) {
+	if err := validator.V.Struct(swap); err != nil {
+		return nil, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	bookings := make([]models.Booking, 2)
+	resources := make([]models.Resource, 2)
+	for i, id := range swap.BookingIds {
+		if bookings[i], err = getBooking(ctx, tx, id); err == pgx.ErrNoRows {
+			return nil, notFound(id)
+		} else if err != nil {
+			return nil, newError(KindInternal, "Internal error; more info: %s", err)
+		}
+	}
+
+	if err := lockResources(ctx, tx, bookings[0].ResourceId, bookings[1].ResourceId); err != nil {
+		return nil, newError(KindInternal, "Failed to lock resource; additional info: %s", err)
+	}
+
+	for i, id := range swap.BookingIds {
+		// the bookings may have been moved or cancelled while waiting for the locks
+		if bookings[i], err = getBooking(ctx, tx, id); err != nil {
+			return nil, newError(KindInternal, "Internal error; more info: %s", err)
+		}
+		if bookings[i].Status != models.BookingConfirmed && bookings[i].Status != models.BookingPending {
+			return nil, newError(KindInvalid, "SwapError: booking {%d} is %s", id, bookings[i].Status)
+		}
+		if resources[i], err = getResource(ctx, tx, bookings[i].ResourceId); err != nil {
+			return nil, newError(KindInternal, "Internal error; more info: %s", err)
+		}
+	}
+	if err := checkCompatible(resources[0], resources[1]); err != nil {
+		return nil, err
+	}
+
+	// the first booking leaves its resource before the second one is checked against it and the
+	// other way round, so each is only checked against the other bookings of its new resource
+	first, second := bookings[0], bookings[1]
+	if bookings[0], err = s.moveBooking(ctx, tx, first, second.ResourceId, models.ChangeSwapped, staffId); err != nil {
+		return nil, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+	}
+	if err := s.checkSwap(ctx, tx, second, first.ResourceId); err != nil {
+		return nil, err
+	}
+	if bookings[1], err = s.moveBooking(ctx, tx, second, first.ResourceId, models.ChangeSwapped, staffId); err != nil {
+		return nil, newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+	}
+	if err := s.checkSwap(ctx, tx, first, second.ResourceId); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return bookings, nil
+}
+
+// handleRelocateBookings
+//
+// @Summary Relocate bookings of resource
+// @Description Creates function which moves upcoming and ongoing bookings of resource specified by id, e.g. a PC
+// @Description which broke, to a compatible resource of the same type and zone: target_id, or the first one free at
+// @Description the time of each booking. Prices are kept and every move is recorded in the history of the booking.
+// @Description In all_or_nothing mode (default) nothing is moved if any booking does not fit anywhere and the
+// @Description response is 409 with the conflicts; in skip_conflicts mode such bookings stay. With dry_run the
+// @Description result is previewed without moving anything. Requires basic auth of staff.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Resource ID"
+// @Param relocation body models.Relocation false "target_id, from, to and mode"
+// @Param dry_run query bool false "only preview the moves"
+//
+// @Success 200 {object} models.RelocationResult "ok"
+// @Failure 400 {object} integer "Wrong ID, incorrect input data or no compatible resource"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} models.RelocationResult "Some bookings can not be relocated, nothing was moved"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /resource/{id}/relocate [post]
+func (s *Server) handleRelocateBookings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var rel models.Relocation
+		if err := json.NewDecoder(r.Body).Decode(&rel); err != nil && err.Error() != "EOF" {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		user, _ := userFromContext(r.Context())
+
+		result, err := s.RelocateBookings(r.Context(), id, rel, r.URL.Query().Get("dry_run") == "true", user.Id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if !result.DryRun && result.Mode == models.RelocationModeAllOrNothing && len(result.Conflicts) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			writeJSON(w, r, result)
+			s.logger.Debug(fmt.Sprintf("Bookings of resource {%d} were not relocated due to %d conflicts", id, len(result.Conflicts)))
+			return
+		}
+
+		writeJSON(w, r, result)
+		s.logger.Debug(fmt.Sprintf("Successefully relocated %d bookings of resource {%d}", result.Moved, id))
+	}
+}
+
+// handleSwapBookings
+//
+// @Summary Swap resources of two bookings
+// @Description Creates function which exchanges the resources of two bookings in one transaction, e.g. the seats
+// @Description of two customers. The resources must have the same type and zone and each booking must fit into the
+// @Description resource of the other one. Prices are kept and the swap is recorded in the history of both bookings.
+// @Description Requires basic auth of staff.
+// @Accept json
+// @Produces json
+//
+// @Param swap body models.Swap true "booking_ids"
+//
+// @Success 200 {array} models.Booking "ok"
+// @Failure 400 {object} integer "Wrong ID, booking is not active or resources are not compatible"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "A booking does not fit into the other resource"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /bookings/swap [post]
+func (s *Server) handleSwapBookings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var swap models.Swap
+		if err := json.NewDecoder(r.Body).Decode(&swap); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		user, _ := userFromContext(r.Context())
+
+		bookings, err := s.SwapBookings(r.Context(), swap, user.Id)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, bookings)
+		s.logger.Debug(fmt.Sprintf("Successefully swapped bookings {%d} and {%d}", swap.BookingIds[0], swap.BookingIds[1]))
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+// resourcesOf returns the resources bookings ids are on
+func resourcesOf(t *testing.T, s *Server, ids ...int) []int {
+	t.Helper()
+	resourceIds := make([]int, len(ids))
+	for i, id := range ids {
+		b, err := getBooking(context.Background(), s.database, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resourceIds[i] = b.ResourceId
+	}
+	return resourceIds
+}
+
+func TestRelocateBookings(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	staffId := addTestUser(t, s, "staff")
+	userId := addTestUser(t, s, "member")
+	source, target := addTestResource(t, s, "pc 1", 1), addTestResource(t, s, "pc 2", 1)
+
+	// the second booking does not fit into the target, which is taken from 12:30
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	fits := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: source, StartTime: start, EndTime: start.Add(time.Hour)})
+	blocked := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: source, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)})
+	taken := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: target, StartTime: start.Add(150 * time.Minute), EndTime: start.Add(4 * time.Hour)})
+
+	tests := []struct {
+		name      string
+		mode      string
+		dryRun    bool
+		moved     int
+		resources []int
+	}{
+		{"dry run", models.RelocationModeSkipConflicts, true, 0, []int{source, source}},
+		{"all or nothing", models.RelocationModeAllOrNothing, false, 0, []int{source, source}},
+		{"skip conflicts", models.RelocationModeSkipConflicts, false, 1, []int{target, source}},
+	}
+
+	for _, tt := range tests {
+		result, err := s.RelocateBookings(ctx, source, models.Relocation{TargetId: target, Mode: tt.mode}, tt.dryRun, staffId)
+		if err != nil {
+			t.Fatalf("%s: RelocateBookings failed: %s", tt.name, err)
+		}
+		if len(result.Moves) != 1 || result.Moves[0].BookingId != fits.Id || result.Moved != tt.moved {
+			t.Errorf("%s: moves = %+v, moved %d, want booking %d and %d moved", tt.name, result.Moves, result.Moved, fits.Id, tt.moved)
+		}
+		if len(result.Conflicts) != 1 || result.Conflicts[0].BookingId != blocked.Id || result.Conflicts[0].ConflictBookingId != taken.Id {
+			t.Errorf("%s: conflicts = %+v, want booking %d blocked by %d", tt.name, result.Conflicts, blocked.Id, taken.Id)
+		}
+
+		if got := resourcesOf(t, s, fits.Id, blocked.Id); got[0] != tt.resources[0] || got[1] != tt.resources[1] {
+			t.Errorf("%s: bookings are on resources %v, want %v", tt.name, got, tt.resources)
+		}
+		if counts := countRows(t, s, "booking_changes", "outbox"); counts[0] != tt.moved || counts[1] != tt.moved {
+			t.Errorf("%s: %d changes were recorded and %d events published, want %d", tt.name, counts[0], counts[1], tt.moved)
+		}
+	}
+}
+
+func TestConcurrentRelocateAndBook(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	staffId := addTestUser(t, s, "staff")
+	userId := addTestUser(t, s, "member")
+	source, target := addTestResource(t, s, "pc 1", 1), addTestResource(t, s, "pc 2", 1)
+
+	// the relocation and a new booking race for the target at the same time
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	booking := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: source, StartTime: start, EndTime: start.Add(time.Hour)})
+	var result models.RelocationResult
+	errs := parallel(2, func(i int) error {
+		var err error
+		if i == 0 {
+			result, err = s.RelocateBookings(ctx, source, models.Relocation{TargetId: target}, false, staffId)
+			return err
+		}
+		b := models.Booking{UserId: userId, ResourceId: target, StartTime: start, EndTime: start.Add(time.Hour), Text: "match"}
+		_, _, err = s.CreateBooking(ctx, b, false)
+		return err
+	})
+
+	if errs[0] != nil {
+		t.Fatalf("RelocateBookings failed: %s", errs[0])
+	}
+	switch {
+	case result.Moved == 1 && errorKind(errs[1]) == KindConflict:
+	case result.Moved == 0 && len(result.Conflicts) == 1 && errs[1] == nil:
+	default:
+		t.Errorf("relocation moved %d with conflicts %+v and booking returned %v, want exactly one of them to get the target", result.Moved, result.Conflicts, errs[1])
+	}
+
+	if got := resourcesOf(t, s, booking.Id)[0]; result.Moved == 0 && got != source || result.Moved == 1 && got != target {
+		t.Errorf("booking is on resource %d after moving %d", got, result.Moved)
+	}
+	if pairs := overlappingPairs(t, s); pairs != 0 {
+		t.Errorf("%d pairs of bookings overlap, want none", pairs)
+	}
+}
+
+func TestSwapBookings(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	staffId := addTestUser(t, s, "staff")
+	userId := addTestUser(t, s, "member")
+	seat1, seat2 := addTestResource(t, s, "seat 1", 1), addTestResource(t, s, "seat 2", 1)
+
+	// the long booking on seat 1 overlaps both bookings on seat 2
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	long := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: seat1, StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	short := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: seat2, StartTime: start, EndTime: start.Add(time.Hour)})
+	next := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: seat2, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)})
+	later1 := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: seat1, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour)})
+	later2 := addTestBooking(t, s, models.Booking{UserId: userId, ResourceId: seat2, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour)})
+
+	if _, err := s.SwapBookings(ctx, models.Swap{BookingIds: []int{long.Id, short.Id}}, staffId); errorKind(err) != KindConflict {
+		t.Fatalf("swapping into booking %d returned %v, want a conflict", next.Id, err)
+	}
+	if got := resourcesOf(t, s, long.Id, short.Id); got[0] != seat1 || got[1] != seat2 {
+		t.Errorf("refused swap left bookings on resources %v, want %v", got, []int{seat1, seat2})
+	}
+	if counts := countRows(t, s, "booking_changes", "outbox"); counts[0] != 0 || counts[1] != 0 {
+		t.Errorf("refused swap recorded %d changes and published %d events", counts[0], counts[1])
+	}
+
+	swapped, err := s.SwapBookings(ctx, models.Swap{BookingIds: []int{later1.Id, later2.Id}}, staffId)
+	if err != nil {
+		t.Fatalf("SwapBookings failed: %s", err)
+	}
+	if swapped[0].ResourceId != seat2 || swapped[1].ResourceId != seat1 {
+		t.Errorf("swapped bookings are on resources %d and %d, want %d and %d", swapped[0].ResourceId, swapped[1].ResourceId, seat2, seat1)
+	}
+	if changes, err := s.BookingHistory(ctx, later1.Id); err != nil || len(changes) != 1 || changes[0].Kind != models.ChangeSwapped {
+		t.Errorf("history = %+v, %v, want one swap", changes, err)
+	}
+	if pairs := overlappingPairs(t, s); pairs != 0 {
+		t.Errorf("%d pairs of bookings overlap, want none", pairs)
+	}
+}
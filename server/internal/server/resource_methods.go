@@ -0,0 +1,352 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const resourceColumns = "id, name, type, zone, capacity, buffer_before, buffer_after, requires_approval, created_at, updated_at"
+
+func scanResource(row pgx.Row, r *models.Resource) error {
+	return row.Scan(&r.Id, &r.Name, &r.Type, &r.Zone, &r.Capacity, &r.BufferBefore, &r.BufferAfter, &r.RequiresApproval, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// CreateResource validates r and adds it to database; the created resource is returned with its id
+func (s *Server) CreateResource(ctx context.Context, r models.Resource) (models.Resource, error) {
+	if err := validator.V.Struct(r); err != nil {
+		return r, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	if r.Capacity == 0 {
+		r.Capacity = 1
+	}
+
+	time := time.Now()
+	r.CreatedAt = time
+	r.UpdatedAt = time
+
+	query := "INSERT INTO resources (name,type,zone,capacity,buffer_before,buffer_after,requires_approval,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id"
+
+	if err := s.database.QueryRow(ctx, query, r.Name, r.Type, r.Zone, r.Capacity, r.BufferBefore, r.BufferAfter, r.RequiresApproval, r.CreatedAt, r.UpdatedAt).Scan(&r.Id); err != nil {
+		return r, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+	return r, nil
+}
+
+// ListResources returns all resources ordered by id
+func (s *Server) ListResources(ctx context.Context) ([]models.Resource, error) {
+	data, err := s.database.Query(ctx, "SELECT "+resourceColumns+" FROM resources ORDER BY id")
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	defer data.Close()
+
+	var resourceList []models.Resource
+	for data.Next() {
+		var Resource models.Resource
+		if err := scanResource(data, &Resource); err != nil {
+			return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+		}
+		resourceList = append(resourceList, Resource)
+	}
+	if err := data.Err(); err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return resourceList, nil
+}
+
+// ResourcesByIds returns resources specified by ids in a single query. Resources which do
+// not exist are left out.
+func (s *Server) ResourcesByIds(ctx context.Context, ids []int) (map[int]models.Resource, error) {
+	data, err := s.database.Query(ctx, "SELECT "+resourceColumns+" FROM resources WHERE id=ANY($1)", ids)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	defer data.Close()
+
+	resources := make(map[int]models.Resource, len(ids))
+	for data.Next() {
+		var Resource models.Resource
+		if err := scanResource(data, &Resource); err != nil {
+			return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+		}
+		resources[Resource.Id] = Resource
+	}
+	if err := data.Err(); err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return resources, nil
+}
+
+// handleAddResource
+//
+// @Summary Add new resource to database
+// @Description Creates function which adds new bookable resource (PC, console, room) to database. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param Name formData string true "2 <= length <= 50"
+// @Param Type formData string true "length <= 30"
+// @Param Zone formData string false "length <= 30"
+// @Param Capacity formData int false "people it holds at the same time, 1 by default"
+// @Param BufferBefore formData int false "minutes kept free before every booking for setup"
+// @Param BufferAfter formData int false "minutes kept free after every booking for cleanup"
+// @Param RequiresApproval formData bool false "bookings are pending until staff approve them"
+//
+// @Success 201 {object} integer "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /resource [post]
+func (s *Server) handleAddResource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newResource models.Resource
+
+		if err := json.NewDecoder(r.Body).Decode(&newResource); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		newResource, err := s.CreateResource(r.Context(), newResource)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newResource)
+		s.logger.Debug("Successefully added resource data to database")
+	}
+}
+
+// handleGetResource
+//
+// @Summary Get resource data
+// @Description Creates function which retrieves data of resource specified by id from database
+// @Produces json
+//
+// @Param id path int true "Resource ID"
+//
+// @Success 200 {object} models.Resource "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /resource/{id} [get]
+func (s *Server) handleGetResource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var Resource models.Resource
+
+		query := "SELECT " + resourceColumns + " FROM resources WHERE id=$1"
+
+		err := scanResource(s.database.QueryRow(context.Background(), query, id), &Resource)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, Resource)
+		s.logger.Debug("Successfully retrieved resource data")
+	}
+}
+
+// handleGetResources
+//
+// @Summary Get resource data
+// @Description Creates function which retrieves data of all resources from database
+// @Produces json
+//
+// @Success 200 {array} models.Resource "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /resources [get]
+func (s *Server) handleGetResources() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resourceList, err := s.ListResources(r.Context())
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if len(resourceList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, resourceList)
+		s.logger.Debug("Successfully retrieved resources data")
+	}
+}
+
+// handleUpdateResource
+//
+// @Summary Update resource data
+// @Description Creates function which updates data of resource specified by id in database. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Resource ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /resource/{id} [put]
+func (s *Server) handleUpdateResource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newResourceData struct {
+			models.Resource
+			// buffers are pointers, so that they can be set back to zero
+			BufferBefore *int `json:"buffer_before"`
+			BufferAfter  *int `json:"buffer_after"`
+			// requires_approval is a pointer, so that it can be switched off
+			RequiresApproval *bool `json:"requires_approval"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&newResourceData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		var sets []string
+		args := []any{time.Now(), id}
+
+		if newResourceData.Name != "" {
+			if err := validator.V.Var(newResourceData.Name, "required,min=2,max=50,excludesall=\\/#@$"); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+				return
+			}
+			args = append(args, newResourceData.Name)
+			sets = append(sets, fmt.Sprintf("name=$%d", len(args)))
+		}
+		if newResourceData.Type != "" {
+			if err := validator.V.Var(newResourceData.Type, "max=30"); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+				return
+			}
+			args = append(args, newResourceData.Type)
+			sets = append(sets, fmt.Sprintf("type=$%d", len(args)))
+		}
+		if newResourceData.Zone != "" {
+			if err := validator.V.Var(newResourceData.Zone, "max=30"); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+				return
+			}
+			args = append(args, newResourceData.Zone)
+			sets = append(sets, fmt.Sprintf("zone=$%d", len(args)))
+		}
+		// lowering the capacity keeps bookings which were made before
+		if newResourceData.Capacity != 0 {
+			if err := validator.V.Var(newResourceData.Capacity, "min=1,max=10000"); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+				return
+			}
+			args = append(args, newResourceData.Capacity)
+			sets = append(sets, fmt.Sprintf("capacity=$%d", len(args)))
+		}
+		// changed buffers apply to existing bookings as well, which keep their times
+		buffers := []struct {
+			column string
+			value  *int
+		}{{"buffer_before", newResourceData.BufferBefore}, {"buffer_after", newResourceData.BufferAfter}}
+		for _, b := range buffers {
+			column, buffer := b.column, b.value
+			if buffer == nil {
+				continue
+			}
+			if err := validator.V.Var(*buffer, "min=0,max=1440"); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s: %s", column, err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s: %s", column, err))
+				return
+			}
+			args = append(args, *buffer)
+			sets = append(sets, fmt.Sprintf("%s=$%d", column, len(args)))
+		}
+
+		// bookings which are already pending or confirmed keep their status
+		if newResourceData.RequiresApproval != nil {
+			args = append(args, *newResourceData.RequiresApproval)
+			sets = append(sets, fmt.Sprintf("requires_approval=$%d", len(args)))
+		}
+
+		sets = append(sets, "updated_at=$1")
+		query := fmt.Sprintf("UPDATE resources SET %s WHERE id=$2", strings.Join(sets, ","))
+
+		tag, err := s.database.Exec(context.Background(), query, args...)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated resource data in database")
+	}
+}
+
+// handleDeleteResource
+//
+// @Summary Delete specified resource data
+// @Description Creates function which deletes data of resource specified by id and its bookings from database. Requires basic auth of an admin.
+//
+// @Param id path int true "Resource ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /resource/{id} [delete]
+func (s *Server) handleDeleteResource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		query := "DELETE FROM resources WHERE id=$1"
+		_, err := s.database.Exec(context.Background(), query, id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified resource; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified resource; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified resource data from database")
+	}
+}
@@ -4,21 +4,149 @@ import (
 	"net/http"
 
 	_ "github.com/alexey-dobry/booking-service/server/docs"
+	"github.com/alexey-dobry/booking-service/server/internal/models"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
 func (s *Server) initRoutes() {
+	s.router.Use(s.withZone)
+
 	s.router.HandleFunc("/user", s.handleAddUser()).Methods("POST")
 	s.router.HandleFunc("/user/{id}", s.handleGetUser()).Methods("GET")
 	s.router.HandleFunc("/users", s.handleGetUsers()).Methods("GET")
-	s.router.HandleFunc("/user/{id}", s.handleUpdateUser()).Methods("PUT")
-	s.router.HandleFunc("/user/{id}", s.handleDeleteUser()).Methods("DELETE")
+	s.router.HandleFunc("/user/{id}", s.requireRole(s.handleUpdateUser(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/user/{id}", s.requireRole(s.handleDeleteUser(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/user/{id}/priority", s.requireRole(s.handleUpdateUserPriority(), models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/user/{id}/role", s.requireRole(s.handleUpdateUserRole(), models.RoleAdmin)).Methods("PUT")
+
+	s.router.HandleFunc("/user/{id}/balance", s.requireRole(s.handleGetBalance(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/user/{id}/ledger", s.requireRole(s.handleGetLedger(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/user/{id}/top-up", s.requireRole(s.handleTopUp(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/user/{id}/adjustment", s.requireRole(s.handleAdjustBalance(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/ledger/accounts", s.requireRole(s.handleGetAccounts(), models.RoleAdmin)).Methods("GET")
+
+	s.router.HandleFunc("/user/{id}/calendar-token", s.requireRole(s.handleGetCalendarToken(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/user/{id}/calendar-token", s.requireRole(s.handleResetCalendarToken(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
 
-	s.router.HandleFunc("/booking", s.handleAddBooking()).Methods("POST")
+	// registered before /booking/{id}, which would match "{id}.ics" as well
+	s.router.HandleFunc("/booking/{id:[0-9]+}.ics", s.handleGetBookingCalendar()).Methods("GET")
+	s.router.HandleFunc("/calendar/{token:[0-9a-f]+}.ics", s.handleGetCalendarFeed()).Methods("GET")
+
+	s.router.HandleFunc("/booking", s.requireRole(s.handleAddBooking(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
 	s.router.HandleFunc("/booking/{id}", s.handleGetBooking()).Methods("GET")
 	s.router.HandleFunc("/bookings", s.handleGetBookings()).Methods("GET")
-	s.router.HandleFunc("/booking/{id}", s.handleUpdateBooking()).Methods("PUT")
-	s.router.HandleFunc("/booking/{id}", s.handleDeleteBooking()).Methods("DELETE")
+	s.router.HandleFunc("/booking/{id}", s.requireRole(s.handleUpdateBooking(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/booking/{id}", s.requireRole(s.handleDeleteBooking(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/booking/{id}/extend", s.requireRole(s.handleExtendBooking(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/booking/{id}/end-now", s.requireRole(s.handleEndBookingNow(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/booking/{id}/history", s.handleGetBookingHistory()).Methods("GET")
+	s.router.HandleFunc("/bookings/swap", s.requireRole(s.handleSwapBookings(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+
+	s.router.HandleFunc("/resource", s.requireRole(s.handleAddResource(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/resource/{id}", s.handleGetResource()).Methods("GET")
+	s.router.HandleFunc("/resources", s.handleGetResources()).Methods("GET")
+	s.router.HandleFunc("/resource/{id}", s.requireRole(s.handleUpdateResource(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/resource/{id}", s.requireRole(s.handleDeleteResource(), models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/resource/{id}/availability", s.handleGetAvailability()).Methods("GET")
+	s.router.HandleFunc("/resource/{id}/relocate", s.requireRole(s.handleRelocateBookings(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/events/availability", s.handleGetAvailabilityEvents()).Methods("GET")
+
+	s.router.HandleFunc("/opening-hours", s.requireRole(s.handleAddOpeningHours(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/opening-hours", s.handleGetOpeningHours()).Methods("GET")
+	s.router.HandleFunc("/opening-hours/{id}", s.requireRole(s.handleUpdateOpeningHours(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/opening-hours/{id}", s.requireRole(s.handleDeleteOpeningHours(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/schedule-exceptions", s.requireRole(s.handleAddScheduleException(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/schedule-exceptions", s.handleGetScheduleExceptions()).Methods("GET")
+	s.router.HandleFunc("/schedule-exceptions/{id}", s.requireRole(s.handleUpdateScheduleException(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/schedule-exceptions/{id}", s.requireRole(s.handleDeleteScheduleException(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/maintenance", s.requireRole(s.handleAddMaintenanceWindow(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/maintenance", s.handleGetMaintenanceWindows()).Methods("GET")
+	s.router.HandleFunc("/maintenance/{id}", s.requireRole(s.handleUpdateMaintenanceWindow(), models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/maintenance/{id}", s.requireRole(s.handleDeleteMaintenanceWindow(), models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/policy-rules", s.requireRole(s.handleAddPolicyRule(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/policy-rules", s.handleGetPolicyRules()).Methods("GET")
+	s.router.HandleFunc("/policy-rules/{id}", s.handleGetPolicyRule()).Methods("GET")
+	s.router.HandleFunc("/policy-rules/{id}", s.requireRole(s.handleUpdatePolicyRule(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/policy-rules/{id}", s.requireRole(s.handleDeletePolicyRule(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/tariffs", s.requireRole(s.handleAddTariff(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/tariffs", s.handleGetTariffs()).Methods("GET")
+	s.router.HandleFunc("/tariffs/{id}", s.handleGetTariff()).Methods("GET")
+	s.router.HandleFunc("/tariffs/{id}", s.requireRole(s.handleUpdateTariff(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/tariffs/{id}", s.requireRole(s.handleDeleteTariff(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/discounts", s.requireRole(s.handleAddDiscount(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/discounts", s.handleGetDiscounts()).Methods("GET")
+	s.router.HandleFunc("/discounts/{id}", s.requireRole(s.handleUpdateDiscount(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/discounts/{id}", s.requireRole(s.handleDeleteDiscount(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/quote", s.handleQuote()).Methods("POST")
+
+	s.router.HandleFunc("/cancellation-rules", s.requireRole(s.handleAddCancellationRule(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/cancellation-rules", s.handleGetCancellationRules()).Methods("GET")
+	s.router.HandleFunc("/cancellation-rules/{id}", s.handleGetCancellationRule()).Methods("GET")
+	s.router.HandleFunc("/cancellation-rules/{id}", s.requireRole(s.handleUpdateCancellationRule(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/cancellation-rules/{id}", s.requireRole(s.handleDeleteCancellationRule(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/promo-codes", s.requireRole(s.handleAddPromoCode(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/promo-codes", s.requireRole(s.handleGetPromoCodes(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/promo-codes/{id}", s.requireRole(s.handleGetPromoCode(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/promo-codes/{id}", s.requireRole(s.handleUpdatePromoCode(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/promo-codes/{id}", s.requireRole(s.handleDeletePromoCode(), models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/promo-codes/{id}/redemptions", s.requireRole(s.handleGetPromoRedemptions(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+
+	s.router.HandleFunc("/membership-plans", s.requireRole(s.handleAddMembershipPlan(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/membership-plans", s.handleGetMembershipPlans()).Methods("GET")
+	s.router.HandleFunc("/membership-plans/{id}", s.requireRole(s.handleUpdateMembershipPlan(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/membership-plans/{id}", s.requireRole(s.handleDeleteMembershipPlan(), models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/memberships", s.requireRole(s.handleAddMembership(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/memberships", s.requireRole(s.handleGetMemberships(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/memberships/{id}", s.requireRole(s.handleGetMembership(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/memberships/{id}", s.requireRole(s.handleUpdateMembership(), models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/memberships/{id}", s.requireRole(s.handleDeleteMembership(), models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/series", s.requireRole(s.handleAddSeries(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/series/{id}", s.handleGetSeries()).Methods("GET")
+	s.router.HandleFunc("/series/{id}", s.requireRole(s.handleUpdateSeries(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/series/{id}", s.requireRole(s.handleDeleteSeries(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/series/{id}/occurrence/{booking_id}", s.requireRole(s.handleUpdateOccurrence(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/series/{id}/occurrence/{booking_id}", s.requireRole(s.handleDeleteOccurrence(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/groups", s.requireRole(s.handleAddGroup(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/groups", s.requireRole(s.handleGetGroups(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/groups/{id}", s.requireRole(s.handleGetGroup(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/groups/{id}", s.requireRole(s.handleDeleteGroup(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/groups/{id}/participants", s.requireRole(s.handleAddGroupParticipant(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/groups/{id}/participants/{user_id}", s.requireRole(s.handleUpdateGroupParticipant(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/groups/{id}/participants/{user_id}", s.requireRole(s.handleDeleteGroupParticipant(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/approvals", s.requireRole(s.handleGetApprovals(), models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/approvals/{id}/approve", s.requireRole(s.handleApproveBooking(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/approvals/{id}/reject", s.requireRole(s.handleRejectBooking(), models.RoleStaff, models.RoleAdmin)).Methods("POST")
+
+	s.router.HandleFunc("/waitlist", s.requireRole(s.handleAddWaitlistEntry(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/waitlist", s.requireRole(s.handleGetWaitlist(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/waitlist/{id}", s.requireRole(s.handleGetWaitlistEntry(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/waitlist/{id}/confirm", s.requireRole(s.handleConfirmWaitlistEntry(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/waitlist/{id}", s.requireRole(s.handleDeleteWaitlistEntry(), models.RoleMember, models.RoleStaff, models.RoleAdmin)).Methods("DELETE")
+
+	s.router.HandleFunc("/import/users", s.requireRole(s.handleImportUsers(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/import/bookings", s.requireRole(s.handleImportBookings(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/export/users", s.requireRole(s.handleExportUsers(), models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/export/bookings", s.requireRole(s.handleExportBookings(), models.RoleAdmin)).Methods("GET")
+
+	s.router.HandleFunc("/webhooks", s.requireRole(s.handleAddWebhook(), models.RoleAdmin)).Methods("POST")
+	s.router.HandleFunc("/webhooks", s.requireRole(s.handleGetWebhooks(), models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/webhooks/{id}", s.requireRole(s.handleGetWebhook(), models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/webhooks/{id}", s.requireRole(s.handleUpdateWebhook(), models.RoleAdmin)).Methods("PUT")
+	s.router.HandleFunc("/webhooks/{id}", s.requireRole(s.handleDeleteWebhook(), models.RoleAdmin)).Methods("DELETE")
+	s.router.HandleFunc("/webhooks/{id}/deliveries", s.requireRole(s.handleGetWebhookDeliveries(), models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/webhook-deliveries/dead", s.requireRole(s.handleGetDeadDeliveries(), models.RoleAdmin)).Methods("GET")
+	s.router.HandleFunc("/webhook-deliveries/{id}/retry", s.requireRole(s.handleRetryDelivery(), models.RoleAdmin)).Methods("POST")
 
 	s.router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8000/swagger/doc.json"),
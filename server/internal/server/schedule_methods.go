@@ -0,0 +1,843 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxAvailabilityRange limits the period availability is calculated for in one request
+const maxAvailabilityRange = 31 * 24 * time.Hour
+
+// loadSchedule reads opening hours, exceptions and maintenance windows which
+// affect bookings of resourceId within [from, to)
+func (s *Server) loadSchedule(ctx context.Context, q querier, resourceId int, from, to time.Time) (schedule.Schedule, error) {
+	sch := schedule.Schedule{Location: s.location}
+
+	// resource specific hours replace venue hours
+	query := `SELECT weekday, EXTRACT(EPOCH FROM open_time)::int / 60, EXTRACT(EPOCH FROM close_time)::int / 60 FROM opening_hours
+		WHERE resource_id=$1 OR (resource_id IS NULL AND NOT EXISTS (SELECT 1 FROM opening_hours WHERE resource_id=$1))`
+	rows, err := q.Query(ctx, query, resourceId)
+	if err != nil {
+		return sch, err
+	}
+	sch.Hours, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (schedule.Hours, error) {
+		var h schedule.Hours
+		err := row.Scan(&h.Weekday, &h.Open, &h.Close)
+		return h, err
+	})
+	if err != nil {
+		return sch, err
+	}
+
+	// closed days go first so a venue holiday wins over special hours of a resource
+	query = `SELECT id, date, closed, COALESCE(EXTRACT(EPOCH FROM open_time)::int / 60, 0), COALESCE(EXTRACT(EPOCH FROM close_time)::int / 60, 0), reason
+		FROM schedule_exceptions WHERE (resource_id=$1 OR resource_id IS NULL) AND date BETWEEN $2::date - 1 AND $3::date
+		ORDER BY closed DESC, resource_id NULLS LAST`
+	rows, err = q.Query(ctx, query, resourceId, from.In(s.location).Format(time.DateOnly), to.In(s.location).Format(time.DateOnly))
+	if err != nil {
+		return sch, err
+	}
+	sch.Exceptions, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (schedule.Exception, error) {
+		var e schedule.Exception
+		err := row.Scan(&e.Id, &e.Date, &e.Closed, &e.Open, &e.Close, &e.Reason)
+		return e, err
+	})
+	if err != nil {
+		return sch, err
+	}
+
+	query = "SELECT id, start_time, end_time, reason FROM maintenance_windows WHERE resource_id=$1 AND start_time < $3 AND end_time > $2"
+	rows, err = q.Query(ctx, query, resourceId, from, to)
+	if err != nil {
+		return sch, err
+	}
+	sch.Maintenance, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (schedule.Maintenance, error) {
+		var m schedule.Maintenance
+		err := row.Scan(&m.Id, &m.Start, &m.End, &m.Reason)
+		return m, err
+	})
+
+	return sch, err
+}
+
+// checkSchedule returns *schedule.Violation if resourceId can not be booked for [start, end)
+func (s *Server) checkSchedule(ctx context.Context, q querier, resourceId int, start, end time.Time) error {
+	sch, err := s.loadSchedule(ctx, q, resourceId, start, end)
+	if err != nil {
+		return err
+	}
+	return sch.Check(start, end)
+}
+
+// validateSchedule returns an error naming the violated rule if resourceId can not be booked for [start, end)
+func (s *Server) validateSchedule(ctx context.Context, q querier, resourceId int, start, end time.Time) error {
+	err := s.checkSchedule(ctx, q, resourceId, start, end)
+
+	var violation *schedule.Violation
+	if errors.As(err, &violation) {
+		return newError(KindInvalid, "ScheduleError: %s", violation)
+	} else if err != nil {
+		return newError(KindInternal, "Failed to check schedule; additional info: %s", err)
+	}
+	return nil
+}
+
+// checkBookingSchedule writes an error response naming the violated rule and returns false
+// if resourceId can not be booked for [start, end)
+func (s *Server) checkBookingSchedule(w http.ResponseWriter, q querier, resourceId int, start, end time.Time) bool {
+	if err := s.validateSchedule(context.Background(), q, resourceId, start, end); err != nil {
+		s.writeError(w, err)
+		return false
+	}
+	return true
+}
+
+// handleGetAvailability
+//
+// @Summary Get resource availability
+// @Description Creates function which calculates free intervals of resource specified by id, taking opening hours,
+// @Description schedule exceptions, maintenance windows and existing bookings together with the buffers of the
+// @Description resource kept free around them into account. Every interval reports
+// @Description how many places of the capacity of the resource are left during all of it; intervals with fewer
+// @Description places than party_size are left out.
+// @Produces json
+//
+// @Param id path int true "Resource ID"
+// @Param from query string false "RFC 3339, defaults to now"
+// @Param to query string false "RFC 3339, defaults to from + 24h, at most 31 days after from"
+// @Param party_size query int false "people to fit, defaults to 1"
+//
+// @Success 200 {array} schedule.Slot "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /resource/{id}/availability [get]
+func (s *Server) handleGetAvailability() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		from, to, err := parseRange(r, 24*time.Hour)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		partySize := 1
+		if v := r.URL.Query().Get("party_size"); v != "" {
+			if partySize, err = strconv.Atoi(v); err != nil || partySize < 1 {
+				http.Error(w, "Incorrect input data: party_size must be a positive number", http.StatusBadRequest)
+				s.logger.Debug("Incorrect input data: party_size must be a positive number")
+				return
+			}
+		}
+
+		ctx := context.Background()
+
+		limits, err := getResourceLimits(ctx, s.database, id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		sch, err := s.loadSchedule(ctx, s.database, id, from, to)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		gap := limits.gap()
+		query := "SELECT start_time, end_time, party_size FROM bookings WHERE resource_id=$1 AND start_time < $3 AND end_time > $2 AND " + activeBooking
+		rows, err := s.database.Query(ctx, query, id, from.Add(-gap), to.Add(gap))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+		occupants, err := pgx.CollectRows(rows, pgx.RowToStructByPos[schedule.Occupant])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+			return
+		}
+
+		// a new booking must keep both buffers away from existing ones, so they block the resource
+		// for the whole gap on either side
+		for i := range occupants {
+			occupants[i].Start, occupants[i].End = occupants[i].Start.Add(-gap), occupants[i].End.Add(gap)
+		}
+
+		free := schedule.Remaining(sch.OpenIntervals(from, to), occupants, limits.capacity, partySize)
+		if free == nil {
+			free = []schedule.Slot{}
+		}
+
+		writeJSON(w, r, free)
+		s.logger.Debug("Successfully calculated resource availability")
+	}
+}
+
+// parseRange reads from and to query parameters in RFC 3339 format
+func parseRange(r *http.Request, defaultLength time.Duration) (time.Time, time.Time, error) {
+	from, to := time.Now(), time.Time{}
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, fmt.Errorf("from: %w", err)
+		}
+		from = t
+	}
+
+	to = from.Add(defaultLength)
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, fmt.Errorf("to: %w", err)
+		}
+		to = t
+	}
+
+	if !to.After(from) {
+		return from, to, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > maxAvailabilityRange {
+		return from, to, fmt.Errorf("range must not be longer than %s", maxAvailabilityRange)
+	}
+	return from, to, nil
+}
+
+// handleAddOpeningHours
+//
+// @Summary Add opening hours
+// @Description Creates function which adds weekly opening hours of the venue or of a resource. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param hours body models.OpeningHours true "weekday, open_time, close_time and optional resource_id"
+//
+// @Success 201 {object} models.OpeningHours "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /opening-hours [post]
+func (s *Server) handleAddOpeningHours() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newHours models.OpeningHours
+
+		if err := json.NewDecoder(r.Body).Decode(&newHours); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newHours); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "INSERT INTO opening_hours (resource_id,weekday,open_time,close_time) VALUES ($1,$2,$3::time,$4::time) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newHours.ResourceId, newHours.Weekday, newHours.OpenTime, newHours.CloseTime).Scan(&newHours.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newHours)
+		s.logger.Debug("Successefully added opening hours to database")
+	}
+}
+
+// handleGetOpeningHours
+//
+// @Summary Get opening hours
+// @Description Creates function which retrieves weekly opening hours, optionally only those of a resource
+// @Produces json
+//
+// @Param resource_id query int false "Resource ID"
+//
+// @Success 200 {array} models.OpeningHours "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /opening-hours [get]
+func (s *Server) handleGetOpeningHours() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resourceId, _ := strconv.Atoi(r.URL.Query().Get("resource_id"))
+
+		query := `SELECT id, resource_id, weekday, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI') FROM opening_hours
+			WHERE $1 = 0 OR resource_id = $1 ORDER BY resource_id NULLS FIRST, weekday, open_time`
+		data, err := s.database.Query(context.Background(), query, resourceId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		hoursList, err := pgx.CollectRows(data, pgx.RowToStructByPos[models.OpeningHours])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+			return
+		}
+
+		if len(hoursList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, hoursList)
+		s.logger.Debug("Successfully retrieved opening hours data")
+	}
+}
+
+// handleUpdateOpeningHours
+//
+// @Summary Replace opening hours
+// @Description Creates function which replaces opening hours specified by id. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Opening hours ID"
+// @Param hours body models.OpeningHours true "weekday, open_time, close_time and optional resource_id"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /opening-hours/{id} [put]
+func (s *Server) handleUpdateOpeningHours() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newHoursData models.OpeningHours
+
+		if err := json.NewDecoder(r.Body).Decode(&newHoursData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newHoursData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "UPDATE opening_hours SET resource_id=$2, weekday=$3, open_time=$4::time, close_time=$5::time WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newHoursData.ResourceId, newHoursData.Weekday, newHoursData.OpenTime, newHoursData.CloseTime)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated opening hours in database")
+	}
+}
+
+// handleDeleteOpeningHours
+//
+// @Summary Delete opening hours
+// @Description Creates function which deletes opening hours specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Opening hours ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /opening-hours/{id} [delete]
+func (s *Server) handleDeleteOpeningHours() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM opening_hours WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified opening hours; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified opening hours; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified opening hours from database")
+	}
+}
+
+// handleAddScheduleException
+//
+// @Summary Add schedule exception
+// @Description Creates function which adds a holiday (closed) or special opening hours for a single date of the
+// @Description venue or of a resource. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param exception body models.ScheduleException true "date, closed or open_time and close_time, reason"
+//
+// @Success 201 {object} models.ScheduleException "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /schedule-exceptions [post]
+func (s *Server) handleAddScheduleException() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newException models.ScheduleException
+
+		if err := json.NewDecoder(r.Body).Decode(&newException); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newException); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newException.Closed {
+			newException.OpenTime, newException.CloseTime = "", ""
+		}
+
+		query := "INSERT INTO schedule_exceptions (resource_id,date,closed,open_time,close_time,reason) VALUES ($1,$2::date,$3,NULLIF($4,'')::time,NULLIF($5,'')::time,$6) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newException.ResourceId, newException.Date, newException.Closed, newException.OpenTime, newException.CloseTime, newException.Reason).Scan(&newException.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newException)
+		s.logger.Debug("Successefully added schedule exception to database")
+	}
+}
+
+// handleGetScheduleExceptions
+//
+// @Summary Get schedule exceptions
+// @Description Creates function which retrieves holidays and special opening hours, optionally only those of a resource
+// @Produces json
+//
+// @Param resource_id query int false "Resource ID"
+//
+// @Success 200 {array} models.ScheduleException "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /schedule-exceptions [get]
+func (s *Server) handleGetScheduleExceptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resourceId, _ := strconv.Atoi(r.URL.Query().Get("resource_id"))
+
+		query := `SELECT id, resource_id, to_char(date, 'YYYY-MM-DD'), closed, COALESCE(to_char(open_time, 'HH24:MI'), ''), COALESCE(to_char(close_time, 'HH24:MI'), ''), reason
+			FROM schedule_exceptions WHERE $1 = 0 OR resource_id = $1 ORDER BY date, resource_id NULLS FIRST`
+		data, err := s.database.Query(context.Background(), query, resourceId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		exceptionList, err := pgx.CollectRows(data, pgx.RowToStructByPos[models.ScheduleException])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+			return
+		}
+
+		if len(exceptionList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, exceptionList)
+		s.logger.Debug("Successfully retrieved schedule exceptions data")
+	}
+}
+
+// handleUpdateScheduleException
+//
+// @Summary Replace schedule exception
+// @Description Creates function which replaces schedule exception specified by id. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Schedule exception ID"
+// @Param exception body models.ScheduleException true "date, closed or open_time and close_time, reason"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /schedule-exceptions/{id} [put]
+func (s *Server) handleUpdateScheduleException() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newExceptionData models.ScheduleException
+
+		if err := json.NewDecoder(r.Body).Decode(&newExceptionData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newExceptionData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+		if newExceptionData.Closed {
+			newExceptionData.OpenTime, newExceptionData.CloseTime = "", ""
+		}
+
+		query := "UPDATE schedule_exceptions SET resource_id=$2, date=$3::date, closed=$4, open_time=NULLIF($5,'')::time, close_time=NULLIF($6,'')::time, reason=$7 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newExceptionData.ResourceId, newExceptionData.Date, newExceptionData.Closed, newExceptionData.OpenTime, newExceptionData.CloseTime, newExceptionData.Reason)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated schedule exception in database")
+	}
+}
+
+// handleDeleteScheduleException
+//
+// @Summary Delete schedule exception
+// @Description Creates function which deletes schedule exception specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Schedule exception ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /schedule-exceptions/{id} [delete]
+func (s *Server) handleDeleteScheduleException() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM schedule_exceptions WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified schedule exception; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified schedule exception; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified schedule exception from database")
+	}
+}
+
+// handleAddMaintenanceWindow
+//
+// @Summary Add maintenance window
+// @Description Creates function which blocks a resource for maintenance. Existing bookings are not touched.
+// @Description Requires basic auth of staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param window body models.MaintenanceWindow true "resource_id, start_time, end_time, reason"
+//
+// @Success 201 {object} models.MaintenanceWindow "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /maintenance [post]
+func (s *Server) handleAddMaintenanceWindow() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newWindow models.MaintenanceWindow
+
+		if err := json.NewDecoder(r.Body).Decode(&newWindow); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newWindow); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if !newWindow.EndTime.After(newWindow.StartTime) {
+			http.Error(w, "TimeError: end_time is before start_time", http.StatusBadRequest)
+			s.logger.Debug("TimeError: end_time is before start_time")
+			return
+		}
+
+		query := "INSERT INTO maintenance_windows (resource_id,start_time,end_time,reason) VALUES ($1,$2,$3,$4) RETURNING id"
+
+		ctx := context.Background()
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		err = tx.QueryRow(ctx, query, newWindow.ResourceId, newWindow.StartTime, newWindow.EndTime, newWindow.Reason).Scan(&newWindow.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		if err := s.publish(ctx, tx, outbox.MaintenanceCreated, newWindow); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newWindow)
+		s.logger.Debug("Successefully added maintenance window to database")
+	}
+}
+
+// handleGetMaintenanceWindows
+//
+// @Summary Get maintenance windows
+// @Description Creates function which retrieves maintenance windows which have not ended yet, optionally only those of a resource
+// @Produces json
+//
+// @Param resource_id query int false "Resource ID"
+//
+// @Success 200 {array} models.MaintenanceWindow "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /maintenance [get]
+func (s *Server) handleGetMaintenanceWindows() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resourceId, _ := strconv.Atoi(r.URL.Query().Get("resource_id"))
+
+		query := "SELECT id, resource_id, start_time, end_time, reason FROM maintenance_windows WHERE ($1 = 0 OR resource_id = $1) AND end_time > $2 ORDER BY start_time"
+		data, err := s.database.Query(context.Background(), query, resourceId, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		windowList, err := pgx.CollectRows(data, pgx.RowToStructByPos[models.MaintenanceWindow])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+			return
+		}
+
+		if len(windowList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, windowList)
+		s.logger.Debug("Successfully retrieved maintenance windows data")
+	}
+}
+
+// handleUpdateMaintenanceWindow
+//
+// @Summary Replace maintenance window
+// @Description Creates function which replaces maintenance window specified by id. Requires basic auth of staff or an admin.
+// @Accept json
+//
+// @Param id path int true "Maintenance window ID"
+// @Param window body models.MaintenanceWindow true "resource_id, start_time, end_time, reason"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /maintenance/{id} [put]
+func (s *Server) handleUpdateMaintenanceWindow() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newWindowData models.MaintenanceWindow
+
+		if err := json.NewDecoder(r.Body).Decode(&newWindowData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newWindowData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if !newWindowData.EndTime.After(newWindowData.StartTime) {
+			http.Error(w, "TimeError: end_time is before start_time", http.StatusBadRequest)
+			s.logger.Debug("TimeError: end_time is before start_time")
+			return
+		}
+
+		query := "UPDATE maintenance_windows SET resource_id=$2, start_time=$3, end_time=$4, reason=$5 WHERE id=$1"
+
+		ctx := context.Background()
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		tag, err := tx.Exec(ctx, query, id, newWindowData.ResourceId, newWindowData.StartTime, newWindowData.EndTime, newWindowData.Reason)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+		newWindowData.Id = id
+
+		if err := s.publish(ctx, tx, outbox.MaintenanceUpdated, newWindowData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated maintenance window in database")
+	}
+}
+
+// handleDeleteMaintenanceWindow
+//
+// @Summary Delete maintenance window
+// @Description Creates function which deletes maintenance window specified by id. Requires basic auth of staff or an admin.
+//
+// @Param id path int true "Maintenance window ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /maintenance/{id} [delete]
+func (s *Server) handleDeleteMaintenanceWindow() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		ctx := context.Background()
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		query := "DELETE FROM maintenance_windows WHERE id=$1 RETURNING id, resource_id, start_time, end_time, reason"
+		rows, err := tx.Query(ctx, query, id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified maintenance window; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified maintenance window; additional info: %s", err))
+			return
+		}
+		deleted, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.MaintenanceWindow])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified maintenance window; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified maintenance window; additional info: %s", err))
+			return
+		}
+
+		for _, window := range deleted {
+			if err := s.publish(ctx, tx, outbox.MaintenanceDeleted, window); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified maintenance window from database")
+	}
+}
@@ -0,0 +1,1073 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/recurrence"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const seriesColumns = "id, user_id, resource_id, start_time, end_time, rrule, exdates, text, created_at"
+
+func scanSeries(row pgx.Row, bs *models.BookingSeries) error {
+	return row.Scan(&bs.Id, &bs.UserId, &bs.ResourceId, &bs.StartTime, &bs.EndTime, &bs.RRule, &bs.ExDates, &bs.Text, &bs.CreatedAt)
+}
+
+func getSeries(ctx context.Context, q querier, id int) (models.BookingSeries, error) {
+	var bs models.BookingSeries
+	err := scanSeries(q.QueryRow(ctx, "SELECT "+seriesColumns+" FROM booking_series WHERE id=$1", id), &bs)
+	return bs, err
+}
+
+// authorizeSeries returns an error unless the user authenticated in ctx may manage series specified
+// by id. Missing series are left to the handler to report.
+func (s *Server) authorizeSeries(ctx context.Context, id int) error {
+	if _, ok := userFromContext(ctx); !ok {
+		return newError(KindUnauthenticated, "Unauthorized: credentials are required")
+	}
+
+	series, err := getSeries(ctx, s.database, id)
+	if err == pgx.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return AuthorizeUser(ctx, series.UserId)
+}
+
+func insertSeries(ctx context.Context, q querier, bs *models.BookingSeries) error {
+	query := "INSERT INTO booking_series (user_id,resource_id,start_time,end_time,rrule,exdates,text,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
+	return q.QueryRow(ctx, query, bs.UserId, bs.ResourceId, bs.StartTime, bs.EndTime, bs.RRule, nonNilTimes(bs.ExDates), bs.Text, bs.CreatedAt).Scan(&bs.Id)
+}
+
+func saveSeries(ctx context.Context, q querier, bs models.BookingSeries) error {
+	query := "UPDATE booking_series SET resource_id=$2, start_time=$3, end_time=$4, rrule=$5, exdates=$6, text=$7 WHERE id=$1"
+	_, err := q.Exec(ctx, query, bs.Id, bs.ResourceId, bs.StartTime, bs.EndTime, bs.RRule, nonNilTimes(bs.ExDates), bs.Text)
+	return err
+}
+
+func nonNilTimes(t []time.Time) []time.Time {
+	if t == nil {
+		return []time.Time{}
+	}
+	return t
+}
+
+// mergeSeries applies non-empty fields of patch to bs and validates the result, reading a floating
+// UNTIL of the rule in loc
+func mergeSeries(bs *models.BookingSeries, patch models.BookingSeries, loc *time.Location) error {
+	if patch.ResourceId != 0 {
+		bs.ResourceId = patch.ResourceId
+	}
+	if !patch.StartTime.IsZero() {
+		bs.StartTime = patch.StartTime
+	}
+	if !patch.EndTime.IsZero() {
+		bs.EndTime = patch.EndTime
+	}
+	if patch.RRule != "" {
+		bs.RRule = patch.RRule
+	}
+	if patch.ExDates != nil {
+		bs.ExDates = patch.ExDates
+	}
+	if patch.Text != "" {
+		bs.Text = patch.Text
+	}
+	if patch.Mode != "" {
+		bs.Mode = patch.Mode
+	}
+	if bs.Mode == "" {
+		bs.Mode = models.SeriesModeAllOrNothing
+	}
+
+	if err := validator.V.Struct(bs); err != nil {
+		return err
+	}
+	if !bs.EndTime.After(bs.StartTime) {
+		return errors.New("TimeError: end_time is before start_time")
+	}
+
+	rule, err := recurrence.Normalize(bs.RRule, loc)
+	if err != nil {
+		return err
+	}
+	bs.RRule = rule
+	return nil
+}
+
+// expandSeries returns start times of all occurrences of bs. The rule is expanded in the
+// venue time zone, so that BYDAY and daylight saving time changes follow local time.
+func (s *Server) expandSeries(bs models.BookingSeries) ([]time.Time, error) {
+	return recurrence.Expand(recurrence.Rule{RRule: bs.RRule, DTStart: bs.StartTime.In(s.location), ExDates: bs.ExDates})
+}
+
+// bookOccurrences creates a booking for each occurrence of bs which starts at or after from.
+// Occurrences which overlap existing bookings or break schedule or policy rules are reported as conflicts. In all_or_nothing
+// mode the bookings of a series with a conflict are rolled back to a savepoint, so nothing is inserted or charged
+// and result.Bookings is empty, whatever the caller does with tx.
+func (s *Server) bookOccurrences(ctx context.Context, outer pgx.Tx, bs models.BookingSeries, occurrences []time.Time, from time.Time) (models.SeriesResult, error) {
+	result := models.SeriesResult{SeriesId: bs.Id, Bookings: []models.Booking{}, Conflicts: []models.Conflict{}}
+	duration := bs.EndTime.Sub(bs.StartTime)
+
+	if len(occurrences) == 0 {
+		return result, nil
+	}
+
+	tx, err := outer.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	sch, err := s.loadSchedule(ctx, tx, bs.ResourceId, occurrences[0], occurrences[len(occurrences)-1].Add(duration))
+	if err != nil {
+		return result, err
+	}
+
+	rules, err := loadPolicy(ctx, tx, bs.ResourceId, bs.UserId)
+	if err != nil {
+		return result, err
+	}
+
+	status, err := newBookingStatus(ctx, tx, bs.ResourceId)
+	if err != nil {
+		return result, err
+	}
+
+	var pending []models.Booking
+	for _, start := range occurrences {
+		if start.Before(from) {
+			continue
+		}
+		end := start.Add(duration)
+
+		var violation *schedule.Violation
+		if errors.As(sch.Check(start, end), &violation) {
+			result.Conflicts = append(result.Conflicts, models.Conflict{StartTime: start, EndTime: end, Rule: violation.Rule, Reason: violation.Message})
+			continue
+		}
+
+		// occurrences are inserted right away so that later occurrences of the
+		// same series are checked against them as well
+		b := models.Booking{UserId: bs.UserId, ResourceId: bs.ResourceId, SeriesId: &bs.Id, OccurrenceStart: &start, StartTime: start, EndTime: end, Text: bs.Text, Status: status}
+
+		violations, err := s.checkPolicy(ctx, tx, rules, b)
+		if err != nil {
+			return result, err
+		}
+		if len(violations) > 0 {
+			for _, v := range violations {
+				result.Conflicts = append(result.Conflicts, models.Conflict{StartTime: start, EndTime: end, Rule: v.Kind, Reason: fmt.Sprintf("%s: %s", v.Name, v.Message)})
+			}
+			continue
+		}
+
+		conflictId, err := findOverlap(ctx, tx, bs.ResourceId, start, end, 1, 0)
+		if err != nil {
+			return result, err
+		}
+		if conflictId != 0 {
+			result.Conflicts = append(result.Conflicts, models.Conflict{StartTime: start, EndTime: end, ConflictBookingId: conflictId})
+			continue
+		}
+
+		if err := s.priceBooking(ctx, tx, &b); err != nil {
+			return result, err
+		}
+
+		// every occurrence is charged right away, so the balance is checked against the earlier ones
+		if err := s.checkFunds(ctx, tx, b.UserId, b.Price); errorKind(err) == KindInsufficientFunds {
+			result.Conflicts = append(result.Conflicts, models.Conflict{StartTime: start, EndTime: end, Rule: "balance", Reason: err.Error()})
+			continue
+		} else if err != nil {
+			return result, err
+		}
+
+		query := "INSERT INTO bookings (user_id,resource_id,series_id,occurrence_start,start_time,end_time,text,status,price,price_breakdown) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING id, updated_at"
+		if err := tx.QueryRow(ctx, query, b.UserId, b.ResourceId, b.SeriesId, b.OccurrenceStart, b.StartTime, b.EndTime, b.Text, b.Status, b.Price, b.PriceBreakdown).Scan(&b.Id, &b.UpdatedAt); err != nil {
+			return result, err
+		}
+		if err := s.chargeBooking(ctx, tx, b); err != nil {
+			return result, err
+		}
+		if err := requestApproval(ctx, tx, b); err != nil {
+			return result, err
+		}
+		pending = append(pending, b)
+	}
+
+	if bs.Mode == models.SeriesModeAllOrNothing && len(result.Conflicts) > 0 {
+		return result, tx.Rollback(ctx)
+	}
+	result.Bookings = append(result.Bookings, pending...)
+	return result, tx.Commit(ctx)
+}
+
+// writeSeriesResult responds with result, or with 409 when no occurrence could be booked.
+// Together with the change event is published for the bookings of result and booking.cancelled for cancelled.
+func (s *Server) writeSeriesResult(w http.ResponseWriter, r *http.Request, tx pgx.Tx, result models.SeriesResult, status int, event string, cancelled []models.Booking) {
+	ctx := context.Background()
+
+	if len(result.Bookings) == 0 && len(result.Conflicts) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		writeJSON(w, r, result)
+		s.logger.Debug(fmt.Sprintf("Series was not booked due to %d conflicting occurrences", len(result.Conflicts)))
+		return
+	}
+
+	err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled)
+	if err == nil {
+		err = s.publishBookings(ctx, tx, event, result.Bookings)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+		return
+	}
+
+	w.WriteHeader(status)
+	writeJSON(w, r, result)
+	s.logger.Debug(fmt.Sprintf("Successefully booked %d occurrences of series {%d}", len(result.Bookings), result.SeriesId))
+}
+
+// handleAddSeries
+//
+// @Summary Adds new recurring booking series
+// @Description Creates function which expands an iCalendar RRULE (with UNTIL or COUNT and optional EXDATE list)
+// @Description into individual bookings. In all_or_nothing mode (default) nothing is booked if any occurrence
+// @Description conflicts with an existing booking; in skip_conflicts mode conflicting occurrences are skipped.
+// @Description Requires basic auth of the user of the series, staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param series body models.BookingSeries true "start_time and end_time describe the first occurrence, rrule e.g. FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10"
+//
+// @Success 201 {object} models.SeriesResult "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} models.SeriesResult "Occurrences conflict with existing bookings"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /series [post]
+func (s *Server) handleAddSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newSeriesData models.BookingSeries
+
+		if err := json.NewDecoder(r.Body).Decode(&newSeriesData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := AuthorizeUser(r.Context(), newSeriesData.UserId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		newSeries := models.BookingSeries{UserId: newSeriesData.UserId}
+		if err := mergeSeries(&newSeries, newSeriesData, s.location); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		occurrences, err := s.expandSeries(newSeries)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		if err := lockResource(ctx, tx, newSeries.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+			return
+		}
+
+		newSeries.CreatedAt = time.Now()
+		if err := insertSeries(ctx, tx, &newSeries); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		result, err := s.bookOccurrences(ctx, tx, newSeries, occurrences, newSeries.StartTime)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		s.writeSeriesResult(w, r, tx, result, http.StatusCreated, outbox.BookingCreated, nil)
+	}
+}
+
+// handleGetSeries
+//
+// @Summary Get recurring series data
+// @Description Creates function which retrieves recurring series specified by id together with its bookings
+// @Produces json
+//
+// @Param id path int true "Series ID"
+//
+// @Success 200 {object} models.SeriesResult "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /series/{id} [get]
+func (s *Server) handleGetSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		ctx := context.Background()
+
+		series, err := getSeries(ctx, s.database, id)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		query := "SELECT " + bookingColumns + " FROM bookings WHERE series_id=$1 ORDER BY start_time"
+		data, err := s.database.Query(ctx, query, id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+		defer data.Close()
+
+		result := struct {
+			models.BookingSeries
+			Bookings []models.Booking `json:"bookings"`
+		}{BookingSeries: series, Bookings: []models.Booking{}}
+
+		for data.Next() {
+			var Booking models.Booking
+			if err := scanBooking(data, &Booking); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			result.Bookings = append(result.Bookings, Booking)
+		}
+
+		writeJSON(w, r, result)
+		s.logger.Debug("Successfully retrieved series data")
+	}
+}
+
+// handleUpdateSeries
+//
+// @Summary Updates whole recurring series
+// @Description Creates function which changes definition of series specified by id and rebuilds all of its
+// @Description occurrences which have not started yet. Past occurrences are left untouched. Occurrences changed on
+// @Description their own keep their changes as long as the new definition still gives them.
+// @Description Requires basic auth of the user of the series, staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Series ID"
+// @Param series body models.BookingSeries true "fields to change"
+//
+// @Success 200 {object} models.SeriesResult "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} models.SeriesResult "Occurrences conflict with existing bookings"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /series/{id} [put]
+func (s *Server) handleUpdateSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newSeriesData models.BookingSeries
+
+		if err := json.NewDecoder(r.Body).Decode(&newSeriesData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := s.authorizeSeries(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		series, err := getSeries(ctx, tx, id)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		s.rebuildSeries(w, r, tx, series, newSeriesData, time.Now(), true)
+	}
+}
+
+// occurrenceStart returns the time the rule of its series gives occurrence b, which differs from
+// its start time if it was moved on its own
+func occurrenceStart(b models.Booking) time.Time {
+	if b.OccurrenceStart != nil {
+		return *b.OccurrenceStart
+	}
+	return b.StartTime
+}
+
+// followingFrom returns the occurrence of series which a change of occurrence at and all following
+// ones starts from: at itself, or the first occurrence which has not started by now, so that past
+// occurrences are neither rewritten nor cancelled. It is false if every occurrence from at on has started.
+func (s *Server) followingFrom(series models.BookingSeries, at, now time.Time) (time.Time, bool, error) {
+	if !at.Before(now) {
+		return at, true, nil
+	}
+
+	occurrences, err := s.expandSeries(series)
+	if err != nil {
+		return at, false, err
+	}
+	for _, t := range occurrences {
+		if !t.Before(now) {
+			return t, true, nil
+		}
+	}
+	return at, false, nil
+}
+
+// keptOverrides returns occurrences of series from on which were changed on their own and which
+// the rule still gives, i.e. whose time is one of occurrences
+func keptOverrides(ctx context.Context, tx pgx.Tx, seriesId int, occurrences []time.Time, from time.Time) ([]models.Booking, error) {
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE series_id=$1 AND overridden AND occurrence_start >= $2 AND " + activeBooking
+	rows, err := tx.Query(ctx, query, seriesId, from)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := collectBookings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []models.Booking
+	for _, b := range overrides {
+		for _, t := range occurrences {
+			if t.Equal(occurrenceStart(b)) {
+				kept = append(kept, b)
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// rebuildSeries applies patch to series and replaces its occurrences from on. With keepOverrides
+// occurrences changed on their own are kept as they are if the new rule still gives them.
+func (s *Server) rebuildSeries(w http.ResponseWriter, r *http.Request, tx pgx.Tx, series models.BookingSeries, patch models.BookingSeries, from time.Time, keepOverrides bool) {
+	ctx := context.Background()
+	oldResourceId := series.ResourceId
+
+	if err := mergeSeries(&series, patch, s.location); err != nil {
+		http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+		return
+	}
+
+	occurrences, err := s.expandSeries(series)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+		return
+	}
+
+	if err := lockResources(ctx, tx, oldResourceId, series.ResourceId); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+		return
+	}
+
+	var kept []models.Booking
+	if keepOverrides {
+		if kept, err = keptOverrides(ctx, tx, series.Id, occurrences, from); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+	}
+	keptIds := []int{}
+	var rebooked []time.Time
+	for _, t := range occurrences {
+		overridden := false
+		for _, b := range kept {
+			overridden = overridden || t.Equal(occurrenceStart(b))
+		}
+		if !overridden {
+			rebooked = append(rebooked, t)
+		}
+	}
+	for _, b := range kept {
+		keptIds = append(keptIds, b.Id)
+	}
+
+	cancelled, err := cancelBookingsWhere(ctx, tx, "series_id=$1 AND occurrence_start >= $2 AND NOT id = ANY($3)", series.Id, from, keptIds)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete series bookings; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to delete series bookings; additional info: %s", err))
+		return
+	}
+
+	if err := saveSeries(ctx, tx, series); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+		return
+	}
+
+	result, err := s.bookOccurrences(ctx, tx, series, rebooked, from)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+		return
+	}
+
+	if _, err := s.promoteWaitlist(ctx, tx, oldResourceId); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to process waitlist; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to process waitlist; additional info: %s", err))
+		return
+	}
+
+	s.writeSeriesResult(w, r, tx, result, http.StatusOK, outbox.BookingCreated, cancelled)
+}
+
+// handleDeleteSeries
+//
+// @Summary Cancel whole recurring series
+// @Description Creates function which deletes series specified by id and all of its occurrences which have not
+// @Description started yet. Past occurrences are kept as ordinary bookings. Occurrences cancelled late are charged
+// @Description the fee of the cancellation rules; the response reports the cancellation of every occurrence.
+// @Description Requires basic auth of the user of the series, staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Series ID"
+//
+// @Success 200 {array} models.Cancellation "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /series/{id} [delete]
+func (s *Server) handleDeleteSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		if err := s.authorizeSeries(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		series, err := getSeries(ctx, tx, id)
+		if err == pgx.ErrNoRows {
+			w.WriteHeader(http.StatusOK)
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		if err := lockResource(ctx, tx, series.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+			return
+		}
+
+		now := time.Now()
+		cancelled, cancellations, err := cancelAndRefund(ctx, tx, lateCancellationFee(tx, now), "series_id=$1 AND start_time > $2", id, now)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete series bookings; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete series bookings; additional info: %s", err))
+			return
+		}
+
+		if _, err := tx.Exec(ctx, "DELETE FROM booking_series WHERE id=$1", id); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified series; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified series; additional info: %s", err))
+			return
+		}
+
+		if _, err := s.promoteWaitlist(ctx, tx, series.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to process waitlist; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to process waitlist; additional info: %s", err))
+			return
+		}
+
+		if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, cancellations)
+		s.logger.Debug("Successefully deleted specified series from database")
+	}
+}
+
+// loadOccurrence reads booking bookingId of series seriesId and writes an error response if it fails
+func (s *Server) loadOccurrence(w http.ResponseWriter, tx pgx.Tx, seriesId, bookingId int) (models.BookingSeries, models.Booking, bool) {
+	ctx := context.Background()
+
+	series, err := getSeries(ctx, tx, seriesId)
+	if err == pgx.ErrNoRows {
+		http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", seriesId), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", seriesId))
+		return series, models.Booking{}, false
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+		return series, models.Booking{}, false
+	}
+
+	booking, err := getBooking(ctx, tx, bookingId)
+	if err != nil && err != pgx.ErrNoRows {
+		http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+		return series, booking, false
+	}
+	if err == pgx.ErrNoRows || booking.SeriesId == nil || *booking.SeriesId != seriesId {
+		http.Error(w, fmt.Sprintf("Booking {%d} is not an occurrence of series {%d}", bookingId, seriesId), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Booking {%d} is not an occurrence of series {%d}", bookingId, seriesId))
+		return series, booking, false
+	}
+	if booking.Status == models.BookingCancelled {
+		http.Error(w, fmt.Sprintf("Booking {%d} is cancelled", bookingId), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Booking {%d} is cancelled", bookingId))
+		return series, booking, false
+	}
+
+	return series, booking, true
+}
+
+func occurrenceScope(r *http.Request) (string, error) {
+	scope := r.URL.Query().Get("scope")
+	switch scope {
+	case "":
+		return models.ScopeThis, nil
+	case models.ScopeThis, models.ScopeFollowing:
+		return scope, nil
+	}
+	return "", fmt.Errorf("unknown scope %q, expected %q or %q", scope, models.ScopeThis, models.ScopeFollowing)
+}
+
+// handleUpdateOccurrence
+//
+// @Summary Updates occurrence of recurring series
+// @Description Creates function which changes a single occurrence (scope=this, default) or the occurrence and all
+// @Description following ones (scope=following). The latter splits the series: the original series ends before
+// @Description the occurrence and a new series with the changed definition is created from it. The split is made
+// @Description at the time the rule gives the occurrence, even if the occurrence was moved on its own, or at the
+// @Description first occurrence which has not started yet if it is in the past; past occurrences are kept.
+// @Description Requires basic auth of the user of the series, staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param id path int true "Series ID"
+// @Param booking_id path int true "Booking ID of the occurrence"
+// @Param scope query string false "this | following"
+// @Param series body models.BookingSeries true "fields to change"
+//
+// @Success 200 {object} models.SeriesResult "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} models.SeriesResult "Occurrences conflict with existing bookings"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /series/{id}/occurrence/{booking_id} [put]
+func (s *Server) handleUpdateOccurrence() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		seriesId, _ := strconv.Atoi(mux.Vars(r)["id"])
+		bookingId, _ := strconv.Atoi(mux.Vars(r)["booking_id"])
+
+		scope, err := occurrenceScope(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		var patch models.BookingSeries
+
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := s.authorizeSeries(r.Context(), seriesId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		series, booking, ok := s.loadOccurrence(w, tx, seriesId, bookingId)
+		if !ok {
+			return
+		}
+
+		if scope == models.ScopeThis {
+			s.updateSingleOccurrence(w, r, tx, booking, patch)
+			return
+		}
+
+		// the series is split at the time the rule gives the occurrence, even if it was moved
+		at, ok, err := s.followingFrom(series, occurrenceStart(booking), time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		} else if !ok {
+			http.Error(w, fmt.Sprintf("Incorrect input data: occurrences from booking {%d} on have already started", bookingId), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: occurrences from booking {%d} on have already started", bookingId))
+			return
+		}
+		if !at.After(series.StartTime) {
+			s.rebuildSeries(w, r, tx, series, patch, at, false)
+			return
+		}
+
+		head, tail, err := recurrence.Split(recurrence.Rule{RRule: series.RRule, DTStart: series.StartTime.In(s.location)}, at)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		tailSeries := series
+		tailSeries.RRule = tail
+		tailSeries.StartTime = at
+		tailSeries.EndTime = at.Add(series.EndTime.Sub(series.StartTime))
+		exdates := series.ExDates
+		tailSeries.ExDates = nil
+		series.RRule = head
+		series.ExDates = nil
+		for _, ex := range exdates {
+			if ex.Before(at) {
+				series.ExDates = append(series.ExDates, ex)
+			} else {
+				tailSeries.ExDates = append(tailSeries.ExDates, ex)
+			}
+		}
+
+		if err := saveSeries(ctx, tx, series); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+
+		tailSeries.CreatedAt = time.Now()
+		if err := insertSeries(ctx, tx, &tailSeries); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE bookings SET series_id=$1 WHERE series_id=$2 AND occurrence_start >= $3", tailSeries.Id, series.Id, at); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+
+		s.rebuildSeries(w, r, tx, tailSeries, patch, at, false)
+	}
+}
+
+// updateSingleOccurrence changes time or text of one occurrence, keeping it in its series. The
+// occurrence is marked overridden, so that updates of the whole series keep the change.
+func (s *Server) updateSingleOccurrence(w http.ResponseWriter, r *http.Request, tx pgx.Tx, booking models.Booking, patch models.BookingSeries) {
+	ctx := context.Background()
+
+	if !patch.StartTime.IsZero() {
+		booking.StartTime = patch.StartTime
+	}
+	if !patch.EndTime.IsZero() {
+		booking.EndTime = patch.EndTime
+	}
+	if patch.Text != "" {
+		booking.Text = patch.Text
+	}
+
+	if err := validator.V.Struct(booking); err != nil {
+		http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+		s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+		return
+	}
+	if !booking.EndTime.After(booking.StartTime) {
+		http.Error(w, "TimeError: end_time is before start_time", http.StatusBadRequest)
+		s.logger.Debug("TimeError: end_time is before start_time")
+		return
+	}
+
+	if !s.checkBookingSchedule(w, tx, booking.ResourceId, booking.StartTime, booking.EndTime) {
+		return
+	}
+
+	if err := lockResource(ctx, tx, booking.ResourceId); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+		return
+	}
+
+	if !s.checkBookingPolicy(w, tx, booking) {
+		return
+	}
+
+	conflictId, err := findOverlap(ctx, tx, booking.ResourceId, booking.StartTime, booking.EndTime, booking.PartySize, booking.Id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to check time slot; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to check time slot; additional info: %s", err))
+		return
+	}
+	if conflictId != 0 {
+		result := models.SeriesResult{
+			SeriesId:  *booking.SeriesId,
+			Bookings:  []models.Booking{},
+			Conflicts: []models.Conflict{{StartTime: booking.StartTime, EndTime: booking.EndTime, ConflictBookingId: conflictId}},
+		}
+		s.writeSeriesResult(w, r, tx, result, http.StatusOK, outbox.BookingUpdated, nil)
+		return
+	}
+
+	if err := s.priceBooking(ctx, tx, &booking); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to calculate price; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to calculate price; additional info: %s", err))
+		return
+	}
+
+	query := "UPDATE bookings SET start_time=$2, end_time=$3, text=$4, price=$5, price_breakdown=$6, overridden=TRUE, sequence=sequence+1, updated_at=NOW() WHERE id=$1 RETURNING " + bookingColumns
+	if err := scanBooking(tx.QueryRow(ctx, query, booking.Id, booking.StartTime, booking.EndTime, booking.Text, booking.Price, booking.PriceBreakdown), &booking); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+		return
+	}
+
+	if err := s.chargeBooking(ctx, tx, booking); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	if _, err := s.promoteWaitlist(ctx, tx, booking.ResourceId); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to process waitlist; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to process waitlist; additional info: %s", err))
+		return
+	}
+
+	result := models.SeriesResult{SeriesId: *booking.SeriesId, Bookings: []models.Booking{booking}, Conflicts: []models.Conflict{}}
+	s.writeSeriesResult(w, r, tx, result, http.StatusOK, outbox.BookingUpdated, nil)
+}
+
+// handleDeleteOccurrence
+//
+// @Summary Cancel occurrence of recurring series
+// @Description Creates function which cancels a single occurrence (scope=this, default), adding it to EXDATE of
+// @Description the series, or the occurrence and all following ones (scope=following), ending the series before it.
+// @Description Following occurrences which have already started are kept, the series ends before the first one which has not.
+// @Description Occurrences cancelled late are charged the fee of the cancellation rules; the response reports the
+// @Description cancellation of every occurrence.
+// @Description Requires basic auth of the user of the series, staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Series ID"
+// @Param booking_id path int true "Booking ID of the occurrence"
+// @Param scope query string false "this | following"
+//
+// @Success 200 {array} models.Cancellation "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /series/{id}/occurrence/{booking_id} [delete]
+func (s *Server) handleDeleteOccurrence() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		seriesId, _ := strconv.Atoi(mux.Vars(r)["id"])
+		bookingId, _ := strconv.Atoi(mux.Vars(r)["booking_id"])
+
+		scope, err := occurrenceScope(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if err := s.authorizeSeries(r.Context(), seriesId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		series, booking, ok := s.loadOccurrence(w, tx, seriesId, bookingId)
+		if !ok {
+			return
+		}
+
+		if err := lockResource(ctx, tx, booking.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+			return
+		}
+
+		// EXDATE and the split use the time the rule gives the occurrence, even if it was moved
+		at := occurrenceStart(booking)
+		if scope == models.ScopeFollowing {
+			var ok bool
+			if at, ok, err = s.followingFrom(series, at, time.Now()); err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+				return
+			} else if !ok {
+				http.Error(w, fmt.Sprintf("Incorrect input data: occurrences from booking {%d} on have already started", bookingId), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: occurrences from booking {%d} on have already started", bookingId))
+				return
+			}
+		}
+
+		var cancelled []models.Booking
+		var cancellations []models.Cancellation
+		fee := lateCancellationFee(tx, time.Now())
+		switch {
+		case scope == models.ScopeThis:
+			series.ExDates = append(series.ExDates, at)
+			cancelled, cancellations, err = cancelAndRefund(ctx, tx, fee, "id=$1", booking.Id)
+			if err == nil {
+				err = saveSeries(ctx, tx, series)
+			}
+		case !at.After(series.StartTime):
+			// cancelling from the first occurrence on removes the whole series
+			cancelled, cancellations, err = cancelAndRefund(ctx, tx, fee, "series_id=$1 AND occurrence_start >= $2", series.Id, at)
+			if err == nil {
+				_, err = tx.Exec(ctx, "DELETE FROM booking_series WHERE id=$1", series.Id)
+			}
+		default:
+			head, _, splitErr := recurrence.Split(recurrence.Rule{RRule: series.RRule, DTStart: series.StartTime.In(s.location)}, at)
+			if splitErr != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: %s", splitErr), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", splitErr))
+				return
+			}
+			series.RRule = head
+			cancelled, cancellations, err = cancelAndRefund(ctx, tx, fee, "series_id=$1 AND occurrence_start >= $2", series.Id, at)
+			if err == nil {
+				err = saveSeries(ctx, tx, series)
+			}
+		}
+		if err == nil {
+			_, err = s.promoteWaitlist(ctx, tx, booking.ResourceId)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified occurrence; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified occurrence; additional info: %s", err))
+			return
+		}
+
+		if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, cancelled); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, cancellations)
+		s.logger.Debug("Successefully deleted specified occurrence from database")
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+func TestFollowingFrom(t *testing.T) {
+	s := &Server{location: time.UTC}
+	start := time.Date(2025, 3, 3, 18, 0, 0, 0, time.UTC)
+	series := models.BookingSeries{StartTime: start, EndTime: start.Add(time.Hour), RRule: "FREQ=WEEKLY;COUNT=4"}
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		now    time.Time
+		want   time.Time
+		wantOk bool
+	}{
+		{"future occurrence", start.Add(week), start, start.Add(week), true},
+		{"past occurrence is clamped to the next one", start, start.Add(week + time.Hour), start.Add(2 * week), true},
+		{"running occurrence is kept", start.Add(week), start.Add(week + time.Minute), start.Add(2 * week), true},
+		{"every occurrence has started", start, start.Add(3*week + time.Minute), start, false},
+	}
+
+	for _, tt := range tests {
+		got, ok, err := s.followingFrom(series, tt.at, tt.now)
+		if err != nil {
+			t.Fatalf("%s: followingFrom failed: %s", tt.name, err)
+		}
+		if ok != tt.wantOk || ok && !got.Equal(tt.want) {
+			t.Errorf("%s: followingFrom = %s, %t, want %s, %t", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestBookOccurrencesAllOrNothing(t *testing.T) {
+	s := testServer(t, false)
+	ctx := context.Background()
+	userId := addTestUser(t, s, "member")
+	resourceId := addTestResource(t, s, "court", 1)
+
+	start := time.Date(2030, 1, 7, 18, 0, 0, 0, time.UTC)
+	// the third occurrence is taken
+	query := "INSERT INTO bookings (user_id, resource_id, start_time, end_time, text) VALUES ($1, $2, $3, $4, 'taken')"
+	if _, err := s.database.Exec(ctx, query, userId, resourceId, start.AddDate(0, 0, 14), start.AddDate(0, 0, 14).Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add booking: %s", err)
+	}
+
+	series := models.BookingSeries{UserId: userId, ResourceId: resourceId, StartTime: start, EndTime: start.Add(time.Hour),
+		RRule: "FREQ=WEEKLY;COUNT=4", Text: "training", Mode: models.SeriesModeAllOrNothing, CreatedAt: time.Now()}
+	occurrences, err := s.expandSeries(series)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	if err := insertSeries(ctx, tx, &series); err != nil {
+		t.Fatalf("failed to add series: %s", err)
+	}
+	result, err := s.bookOccurrences(ctx, tx, series, occurrences, start)
+	if err != nil {
+		t.Fatalf("bookOccurrences failed: %s", err)
+	}
+	if len(result.Bookings) != 0 || len(result.Conflicts) != 1 {
+		t.Errorf("result has %d bookings and %d conflicts, want none and 1", len(result.Bookings), len(result.Conflicts))
+	}
+	// the caller commits, yet the occurrences before the conflict were not kept
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var booked int
+	if err := s.database.QueryRow(ctx, "SELECT COUNT(*) FROM bookings WHERE series_id=$1", series.Id).Scan(&booked); err != nil {
+		t.Fatal(err)
+	}
+	if booked != 0 {
+		t.Errorf("%d occurrences were booked, want none", booked)
+	}
+}
@@ -1,26 +1,59 @@
 package server
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/alexey-dobry/booking-service/server/internal/config"
 	"github.com/alexey-dobry/booking-service/server/internal/logger"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
 	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Server struct {
 	router   *mux.Router
-	database *pgx.Conn
+	database *pgxpool.Pool
 	logger   *logger.Logger
+	location *time.Location
+	// prepaid charges bookings to user balances
+	prepaid bool
+	// defaultResource is booked by requests which name no resource, 0 for the general resource
+	defaultResource int
+
+	sinks  []outbox.Sink
+	broker *outbox.MemoryBroker
+
+	// credentials caches recently verified basic auth credentials
+	credentials credentialCache
+
+	// streams serves availability events to subscribers of this server
+	streams *streamHub
+
+	webhookClient *http.Client
+	// webhookWake triggers delivery of freshly relayed events
+	webhookWake chan struct{}
 }
 
-func New(database *pgx.Conn, logger *logger.Logger) *Server {
+func New(database *pgxpool.Pool, logger *logger.Logger, cfg config.Config) *Server {
 	s := Server{
 		router:   mux.NewRouter(),
 		database: database,
 		logger:   logger,
+		location: cfg.Location,
+		prepaid:  cfg.Prepaid,
+
+		defaultResource: cfg.DefaultResourceId,
+
+		webhookClient: &http.Client{Timeout: webhookTimeout},
+		webhookWake:   make(chan struct{}, 1),
+
+		broker:  outbox.NewMemoryBroker(),
+		streams: newStreamHub(),
 	}
+	s.sinks = s.newSinks(cfg.OutboxSinks)
 
 	s.initRoutes()
 
@@ -28,7 +61,24 @@ func New(database *pgx.Conn, logger *logger.Logger) *Server {
 	return &s
 }
 
+// Handle serves handler on path next to the REST routes, e.g. another API built on the
+// service methods of s
+func (s *Server) Handle(path string, handler http.Handler) *mux.Route {
+	return s.router.Handle(path, handler)
+}
+
+// Location returns the venue time zone
+func (s *Server) Location() *time.Location {
+	return s.location
+}
+
 func (s *Server) Run() {
+	go s.runHoldExpiry(context.Background())
+	go s.runApprovalExpiry(context.Background())
+	go s.runOutboxRelay(context.Background())
+	go s.runOutboxListener(context.Background())
+	go s.runWebhookDelivery(context.Background())
+
 	s.logger.Debug("Server is started")
 	log.Fatal(http.ListenAndServe(":8000", s.router))
 }
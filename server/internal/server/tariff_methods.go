@@ -0,0 +1,571 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/pricing"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const tariffColumns = "id, name, resource_type, days, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), price_per_hour, priority, disabled, created_at, updated_at"
+
+func scanTariff(row pgx.Row, t *models.Tariff) error {
+	return row.Scan(&t.Id, &t.Name, &t.ResourceType, &t.Days, &t.StartTime, &t.EndTime, &t.PricePerHour, &t.Priority, &t.Disabled, &t.CreatedAt, &t.UpdatedAt)
+}
+
+const discountColumns = "id, name, percent, role, resource_type, disabled, created_at, updated_at"
+
+func scanDiscount(row pgx.Row, d *models.Discount) error {
+	return row.Scan(&d.Id, &d.Name, &d.Percent, &d.Role, &d.ResourceType, &d.Disabled, &d.CreatedAt, &d.UpdatedAt)
+}
+
+// loadTariffs reads enabled tariffs which apply to resourceId in order of precedence: by priority,
+// then tariffs of the resource type before global ones
+func loadTariffs(ctx context.Context, q querier, resourceId int) ([]pricing.Tariff, error) {
+	query := `SELECT t.id, t.name, t.days, EXTRACT(EPOCH FROM t.start_time)::int / 60, EXTRACT(EPOCH FROM t.end_time)::int / 60, t.price_per_hour
+		FROM tariffs t, resources r WHERE r.id=$1 AND NOT t.disabled AND (t.resource_type IS NULL OR t.resource_type=r.type)
+		ORDER BY t.priority DESC, t.resource_type IS NULL, t.id`
+	rows, err := q.Query(ctx, query, resourceId)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[pricing.Tariff])
+}
+
+// loadDiscounts reads enabled discounts which apply to bookings of resourceId made by userId.
+// Without a user only discounts which are not scoped to a role apply.
+func loadDiscounts(ctx context.Context, q querier, resourceId, userId int) ([]pricing.Discount, error) {
+	query := `SELECT d.id, d.name, d.percent FROM discounts d JOIN resources r ON r.id=$1 LEFT JOIN users u ON u.id=$2
+		WHERE NOT d.disabled AND (d.resource_type IS NULL OR d.resource_type=r.type) AND (d.role IS NULL OR d.role=u.role)
+		ORDER BY d.id`
+	rows, err := q.Query(ctx, query, resourceId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[pricing.Discount])
+}
+
+// quote prices b by the current tariffs, discounts and the membership of its user. A new booking
+// gets its promo code, which is checked and locked until q ends, an existing one keeps the code
+// redeemed for it. Invalid promo codes are reported as KindInvalid errors.
+func (s *Server) quote(ctx context.Context, q querier, b models.Booking) (pricing.Quote, error) {
+	tariffs, err := loadTariffs(ctx, q, b.ResourceId)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+	discounts, err := loadDiscounts(ctx, q, b.ResourceId, b.UserId)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+	membership, err := s.findMembership(ctx, q, b)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+	promo, err := s.findPromo(ctx, q, b)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+
+	req := pricing.Request{Start: b.StartTime, End: b.EndTime, Location: s.location, Membership: membership, Promo: promo}
+	return pricing.Price(tariffs, discounts, req), nil
+}
+
+// priceBooking sets the price of b and its breakdown by the current tariffs, memberships and
+// promo codes. Errors of invalid promo codes are KindInvalid, all others are internal.
+func (s *Server) priceBooking(ctx context.Context, q querier, b *models.Booking) error {
+	quote, err := s.quote(ctx, q, *b)
+	if err != nil {
+		return err
+	}
+	b.Price, b.PriceBreakdown = quote.Total, &quote
+	return nil
+}
+
+// QuoteBooking prices a booking described by req without creating it
+func (s *Server) QuoteBooking(ctx context.Context, req models.QuoteRequest) (pricing.Quote, error) {
+	if err := validator.V.Struct(req); err != nil {
+		return pricing.Quote{}, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return pricing.Quote{}, newError(KindInvalid, "TimeError: end_time is before start_time")
+	}
+
+	var exists bool
+	if err := s.database.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM resources WHERE id=$1)", req.ResourceId).Scan(&exists); err != nil {
+		return pricing.Quote{}, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	if !exists {
+		return pricing.Quote{}, notFound(req.ResourceId)
+	}
+
+	b := models.Booking{UserId: req.UserId, ResourceId: req.ResourceId, StartTime: req.StartTime, EndTime: req.EndTime, PromoCode: req.PromoCode}
+	quote, err := s.quote(ctx, s.database, b)
+	if errorKind(err) == KindInvalid {
+		return quote, err
+	} else if err != nil {
+		return quote, newError(KindInternal, "Failed to calculate price; additional info: %s", err)
+	}
+	return quote, nil
+}
+
+// handleQuote
+//
+// @Summary Quote booking price
+// @Description Creates function which prices a hypothetical booking by the current tariffs, discounts, the
+// @Description membership of the user and a promo code without creating it. The promo code is checked but not
+// @Description redeemed. Opening hours, policy rules and availability are not checked.
+// @Accept json
+// @Produces json
+//
+// @Param booking body models.QuoteRequest true "resource_id, start_time, end_time and optional user_id and promo_code"
+//
+// @Success 200 {object} pricing.Quote "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 500 {object} integer "Error calculating price"
+// @Router /quote [post]
+func (s *Server) handleQuote() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req models.QuoteRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		quote, err := s.QuoteBooking(r.Context(), req)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, quote)
+		s.logger.Debug("Successfully calculated booking price")
+	}
+}
+
+// handleAddTariff
+//
+// @Summary Add tariff
+// @Description Creates function which adds a price per hour for a band of the day, optionally only on
+// @Description weekdays or weekends and for a resource type. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param tariff body models.Tariff true "name, days, start_time, end_time, price_per_hour and optional resource_type and priority"
+//
+// @Success 201 {object} models.Tariff "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /tariffs [post]
+func (s *Server) handleAddTariff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newTariff models.Tariff
+
+		if err := json.NewDecoder(r.Body).Decode(&newTariff); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newTariff); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		time := time.Now()
+		newTariff.CreatedAt = time
+		newTariff.UpdatedAt = time
+
+		query := `INSERT INTO tariffs (name,resource_type,days,start_time,end_time,price_per_hour,priority,disabled,created_at,updated_at)
+			VALUES ($1,$2,$3,$4::time,$5::time,$6,$7,$8,$9,$10) RETURNING id`
+
+		err := s.database.QueryRow(context.Background(), query, newTariff.Name, newTariff.ResourceType, newTariff.Days, newTariff.StartTime, newTariff.EndTime,
+			newTariff.PricePerHour, newTariff.Priority, newTariff.Disabled, newTariff.CreatedAt, newTariff.UpdatedAt).Scan(&newTariff.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newTariff)
+		s.logger.Debug("Successefully added tariff to database")
+	}
+}
+
+// handleGetTariff
+//
+// @Summary Get tariff
+// @Description Creates function which retrieves tariff specified by id from database
+// @Produces json
+//
+// @Param id path int true "Tariff ID"
+//
+// @Success 200 {object} models.Tariff "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /tariffs/{id} [get]
+func (s *Server) handleGetTariff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var tariff models.Tariff
+
+		query := "SELECT " + tariffColumns + " FROM tariffs WHERE id=$1"
+
+		err := scanTariff(s.database.QueryRow(context.Background(), query, id), &tariff)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, tariff)
+		s.logger.Debug("Successfully retrieved tariff data")
+	}
+}
+
+// handleGetTariffs
+//
+// @Summary Get tariffs
+// @Description Creates function which retrieves all tariffs, optionally only those which apply to a resource type
+// @Produces json
+//
+// @Param resource_type query string false "Resource type"
+//
+// @Success 200 {array} models.Tariff "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /tariffs [get]
+func (s *Server) handleGetTariffs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var tariffList []models.Tariff
+
+		resourceType := r.URL.Query().Get("resource_type")
+
+		query := `SELECT ` + tariffColumns + ` FROM tariffs
+			WHERE $1 = '' OR resource_type IS NULL OR resource_type = $1 ORDER BY priority DESC, resource_type IS NULL, id`
+		data, err := s.database.Query(context.Background(), query, resourceType)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var tariff models.Tariff
+			err = scanTariff(data, &tariff)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			tariffList = append(tariffList, tariff)
+		}
+
+		if len(tariffList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, tariffList)
+		s.logger.Debug("Successfully retrieved tariffs data")
+	}
+}
+
+// handleUpdateTariff
+//
+// @Summary Replace tariff
+// @Description Creates function which replaces tariff specified by id. Prices of existing bookings are kept,
+// @Description bookings created or rescheduled afterwards are priced by the new tariff. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Tariff ID"
+// @Param tariff body models.Tariff true "name, days, start_time, end_time, price_per_hour and optional resource_type and priority"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /tariffs/{id} [put]
+func (s *Server) handleUpdateTariff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newTariffData models.Tariff
+
+		if err := json.NewDecoder(r.Body).Decode(&newTariffData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newTariffData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := `UPDATE tariffs SET name=$2, resource_type=$3, days=$4, start_time=$5::time, end_time=$6::time, price_per_hour=$7,
+			priority=$8, disabled=$9, updated_at=$10 WHERE id=$1`
+
+		tag, err := s.database.Exec(context.Background(), query, id, newTariffData.Name, newTariffData.ResourceType, newTariffData.Days, newTariffData.StartTime,
+			newTariffData.EndTime, newTariffData.PricePerHour, newTariffData.Priority, newTariffData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated tariff in database")
+	}
+}
+
+// handleDeleteTariff
+//
+// @Summary Delete tariff
+// @Description Creates function which deletes tariff specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Tariff ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /tariffs/{id} [delete]
+func (s *Server) handleDeleteTariff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM tariffs WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified tariff; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified tariff; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified tariff from database")
+	}
+}
+
+// handleAddDiscount
+//
+// @Summary Add discount
+// @Description Creates function which adds a percentage discount of booking prices, optionally only for a user
+// @Description role and/or a resource type. Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param discount body models.Discount true "name, percent and optional role and resource_type scope"
+//
+// @Success 201 {object} models.Discount "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /discounts [post]
+func (s *Server) handleAddDiscount() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newDiscount models.Discount
+
+		if err := json.NewDecoder(r.Body).Decode(&newDiscount); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newDiscount); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		time := time.Now()
+		newDiscount.CreatedAt = time
+		newDiscount.UpdatedAt = time
+
+		query := "INSERT INTO discounts (name,percent,role,resource_type,disabled,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newDiscount.Name, newDiscount.Percent, newDiscount.Role, newDiscount.ResourceType, newDiscount.Disabled, newDiscount.CreatedAt, newDiscount.UpdatedAt).Scan(&newDiscount.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newDiscount)
+		s.logger.Debug("Successefully added discount to database")
+	}
+}
+
+// handleGetDiscounts
+//
+// @Summary Get discounts
+// @Description Creates function which retrieves all discounts, optionally only those which apply to a user role
+// @Produces json
+//
+// @Param role query string false "User role"
+//
+// @Success 200 {array} models.Discount "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /discounts [get]
+func (s *Server) handleGetDiscounts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var discountList []models.Discount
+
+		role := r.URL.Query().Get("role")
+
+		query := "SELECT " + discountColumns + " FROM discounts WHERE $1 = '' OR role IS NULL OR role = $1 ORDER BY id"
+		data, err := s.database.Query(context.Background(), query, role)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var discount models.Discount
+			err = scanDiscount(data, &discount)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			discountList = append(discountList, discount)
+		}
+
+		if len(discountList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, discountList)
+		s.logger.Debug("Successfully retrieved discounts data")
+	}
+}
+
+// handleUpdateDiscount
+//
+// @Summary Replace discount
+// @Description Creates function which replaces discount specified by id. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Discount ID"
+// @Param discount body models.Discount true "name, percent and optional role and resource_type scope"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /discounts/{id} [put]
+func (s *Server) handleUpdateDiscount() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newDiscountData models.Discount
+
+		if err := json.NewDecoder(r.Body).Decode(&newDiscountData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newDiscountData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "UPDATE discounts SET name=$2, percent=$3, role=$4, resource_type=$5, disabled=$6, updated_at=$7 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newDiscountData.Name, newDiscountData.Percent, newDiscountData.Role, newDiscountData.ResourceType, newDiscountData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated discount in database")
+	}
+}
+
+// handleDeleteDiscount
+//
+// @Summary Delete discount
+// @Description Creates function which deletes discount specified by id. Requires basic auth of an admin.
+//
+// @Param id path int true "Discount ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /discounts/{id} [delete]
+func (s *Server) handleDeleteDiscount() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM discounts WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified discount; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified discount; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified discount from database")
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// zoneKey is the context key of the time zone which times in responses are rendered in
+type zoneKey struct{}
+
+// ContextWithZone returns ctx of a request whose response renders times in loc
+func ContextWithZone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, zoneKey{}, loc)
+}
+
+// ZoneFromContext returns the time zone which times in the response to the request of ctx are
+// rendered in, or nil outside of a request
+func ZoneFromContext(ctx context.Context) *time.Location {
+	loc, _ := ctx.Value(zoneKey{}).(*time.Location)
+	return loc
+}
+
+// withZone adds the time zone given by the tz query parameter (IANA name, e.g. Europe/Moscow)
+// or, by default, the venue time zone to the context of requests
+func (s *Server) withZone(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loc := s.location
+
+		if tz := r.URL.Query().Get("tz"); tz != "" {
+			var err error
+			loc, err = time.LoadLocation(tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Incorrect input data: tz: %s", err), http.StatusBadRequest)
+				s.logger.Debug(fmt.Sprintf("Incorrect input data: tz: %s", err))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithZone(r.Context(), loc)))
+	})
+}
+
+// writeJSON writes v as the JSON response to r with its times in the zone of r
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	return json.NewEncoder(w).Encode(inZone(v, ZoneFromContext(r.Context())))
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// inZone returns a copy of v with every time in its exported fields, elements and values
+// converted to loc; v itself is not changed. Zero times are kept, as is v with a nil loc.
+func inZone(v any, loc *time.Location) any {
+	if v == nil || loc == nil {
+		return v
+	}
+	return zoneValue(reflect.ValueOf(v), loc).Interface()
+}
+
+func zoneValue(v reflect.Value, loc *time.Location) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if t := v.Interface().(time.Time); !t.IsZero() {
+				return reflect.ValueOf(t.In(loc))
+			}
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if field := out.Field(i); field.CanSet() {
+				field.Set(zoneValue(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(zoneValue(v.Elem(), loc))
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(zoneValue(v.Index(i), loc))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(zoneValue(v.Index(i), loc))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			out.SetMapIndex(it.Key(), zoneValue(it.Value(), loc))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(zoneValue(v.Elem(), loc))
+		return out
+	}
+	return v
+}
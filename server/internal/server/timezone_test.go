@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+)
+
+func TestInZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC)
+	expires := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	booking := models.Booking{Id: 1, StartTime: start, EndTime: start.Add(time.Hour), Text: "2025-03-03T13:00:00Z", HoldExpiresAt: &expires}
+	entry := models.WaitlistEntry{Id: 2, StartTime: start}
+
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{
+			name: "struct with a pointer; text which looks like a time is kept",
+			in:   booking,
+			want: `"end_time":"2025-03-03T15:00:00+01:00","party_size":0,"text":"2025-03-03T13:00:00Z"`,
+		},
+		{
+			name: "pointer",
+			in:   &booking,
+			want: `"hold_expires_at":"2025-07-01T12:00:00+02:00"`,
+		},
+		{
+			name: "slice",
+			in:   []models.WaitlistEntry{entry},
+			want: `"start_time":"2025-03-03T14:00:00+01:00"`,
+		},
+		{
+			name: "map of interfaces",
+			in:   map[string]any{"deadline": start},
+			want: `{"deadline":"2025-03-03T14:00:00+01:00"}`,
+		},
+		{
+			name: "zero time",
+			in:   models.WaitlistEntry{},
+			want: `"start_time":"0001-01-01T00:00:00Z"`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(inZone(tt.in, loc))
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if !json.Valid(got) || !strings.Contains(string(got), tt.want) {
+			t.Errorf("%s: inZone = %s, want it to contain %s", tt.name, got, tt.want)
+		}
+	}
+
+	// the value is copied, not converted in place
+	if booking.StartTime.Location() != time.UTC || booking.HoldExpiresAt.Location() != time.UTC {
+		t.Errorf("inZone changed its argument: %+v", booking)
+	}
+}
+
+func TestInZoneKeepsInstants(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := models.Booking{StartTime: time.Date(2025, 3, 9, 6, 30, 0, 0, time.UTC), EndTime: time.Date(2025, 3, 9, 7, 30, 0, 0, time.UTC)}
+	out := inZone(in, loc).(models.Booking)
+	if !out.StartTime.Equal(in.StartTime) || !out.EndTime.Equal(in.EndTime) {
+		t.Errorf("times changed from %s-%s to %s-%s", in.StartTime, in.EndTime, out.StartTime, out.EndTime)
+	}
+	if out.StartTime.Location() != loc {
+		t.Errorf("start is in %s, want %s", out.StartTime.Location(), loc)
+	}
+}
+
+func TestWithZone(t *testing.T) {
+	s := &Server{location: time.UTC}
+	handler := s.withZone(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, map[string]any{"start_time": time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC)})
+	}))
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/booking/1?tz=Europe/Berlin", "2025-03-03T14:00:00+01:00"},
+		{"/booking/1", "2025-03-03T13:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		var got map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"start_time": tt.want}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: response = %v, want %v", tt.target, got, want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
 	"github.com/alexey-dobry/booking-service/server/internal/validator"
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5"
@@ -18,6 +19,259 @@ import (
 
 //сделать get users!
 
+// CreateUser validates u and adds it as a member; the password is stored as bcrypt hash.
+// The created user is returned without the hash.
+func (s *Server) CreateUser(ctx context.Context, u models.User) (models.User, error) {
+	if err := validator.V.Struct(u); err != nil {
+		return u, newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(u.Password), passwordCost)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to hash password; additional info: %s", err)
+	}
+
+	query := "INSERT INTO users (username,password,created_at,updated_at) VALUES ($1,$2,$3,$4) RETURNING id"
+
+	time := time.Now()
+	u.CreatedAt = time
+	u.UpdatedAt = time
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, query, u.Username, password, u.CreatedAt, u.UpdatedAt).Scan(&u.Id); err != nil {
+		return u, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+
+	created, err := getUser(ctx, tx, u.Id)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+
+	if err := s.publish(ctx, tx, outbox.UserCreated, created); err != nil {
+		return u, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return u, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return created, nil
+}
+
+// GetUser returns user specified by id without the password hash
+func (s *Server) GetUser(ctx context.Context, id int) (models.User, error) {
+	user, err := getUser(ctx, s.database, id)
+	if err == pgx.ErrNoRows {
+		return user, notFound(id)
+	} else if err != nil {
+		return user, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns all users ordered by id without password hashes
+func (s *Server) ListUsers(ctx context.Context) ([]models.User, error) {
+	query := "SELECT id, username, role, priority, created_at, updated_at FROM users ORDER BY id"
+	data, err := s.database.Query(ctx, query)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	defer data.Close()
+
+	var userList []models.User
+	for data.Next() {
+		var User models.User
+		err = data.Scan(&User.Id, &User.Username, &User.Role, &User.Priority, &User.CreatedAt, &User.UpdatedAt)
+		if err != nil {
+			return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+		}
+		userList = append(userList, User)
+	}
+	if err := data.Err(); err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return userList, nil
+}
+
+// UsersByIds returns users specified by ids in a single query without password hashes.
+// Users which do not exist are left out.
+func (s *Server) UsersByIds(ctx context.Context, ids []int) (map[int]models.User, error) {
+	query := "SELECT id, username, role, priority, created_at, updated_at FROM users WHERE id=ANY($1)"
+	data, err := s.database.Query(ctx, query, ids)
+	if err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	defer data.Close()
+
+	users := make(map[int]models.User, len(ids))
+	for data.Next() {
+		var User models.User
+		err = data.Scan(&User.Id, &User.Username, &User.Role, &User.Priority, &User.CreatedAt, &User.UpdatedAt)
+		if err != nil {
+			return nil, newError(KindInternal, "Failed to write data into object; additional info: %s", err)
+		}
+		users[User.Id] = User
+	}
+	if err := data.Err(); err != nil {
+		return nil, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	return users, nil
+}
+
+// UpdateUser changes username, password and role of user specified by id to those set in u and
+// returns the user without the password hash. Callers make sure only admins change roles.
+func (s *Server) UpdateUser(ctx context.Context, id int, u models.User) (models.User, error) {
+	var sets []string
+	args := []any{time.Now(), id}
+
+	if u.Password != "" {
+		if err := validator.V.Var(u.Password, "required,excludes=\\/#@$"); err != nil {
+			return u, newError(KindInvalid, "Incorrect input data: %s", err)
+		}
+		password, err := bcrypt.GenerateFromPassword([]byte(u.Password), passwordCost)
+		if err != nil {
+			return u, newError(KindInternal, "Failed to hash password; additional info: %s", err)
+		}
+		args = append(args, password)
+		sets = append(sets, fmt.Sprintf("password=$%d", len(args)))
+	}
+	if u.Username != "" {
+		if err := validator.V.Var(u.Username, "required,min=6,max=20,excludes=\\/#@$"); err != nil {
+			return u, newError(KindInvalid, "Incorrect input data: %s", err)
+		}
+		args = append(args, u.Username)
+		sets = append(sets, fmt.Sprintf("username=$%d", len(args)))
+	}
+	if u.Role != "" {
+		if err := validator.V.Var(u.Role, "required,oneof=member staff admin"); err != nil {
+			return u, newError(KindInvalid, "Incorrect input data: %s", err)
+		}
+		args = append(args, u.Role)
+		sets = append(sets, fmt.Sprintf("role=$%d", len(args)))
+	}
+
+	sets = append(sets, "updated_at=$1")
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id=$2", strings.Join(sets, ","))
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to add data to database; additional info: %s", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return u, notFound(id)
+	}
+
+	updated, err := getUser(ctx, tx, id)
+	if err != nil {
+		return u, newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+
+	if err := s.publish(ctx, tx, outbox.UserUpdated, updated); err != nil {
+		return u, newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return u, newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return updated, nil
+}
+
+// UserByUsername returns user with username without the password hash
+func (s *Server) UserByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+
+	query := "SELECT id, username, role, priority, created_at, updated_at FROM users WHERE username=$1"
+
+	err := s.database.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Role, &user.Priority, &user.CreatedAt, &user.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return user, newError(KindNotFound, "No user with username %q was found in database", username)
+	} else if err != nil {
+		return user, newError(KindInternal, "Internal error; more info: %s", err)
+	}
+	return user, nil
+}
+
+// SetUserRole sets role of user specified by id to member, staff or admin
+func (s *Server) SetUserRole(ctx context.Context, id int, role string) error {
+	if err := validator.V.Var(role, "required,oneof=member staff admin"); err != nil {
+		return newError(KindInvalid, "Incorrect input data: %s", err)
+	}
+
+	query := "UPDATE users SET role=$1, updated_at=$2 WHERE id=$3"
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, role, time.Now(), id)
+	if err != nil {
+		return newError(KindInternal, "Failed to update data in database; additional info: %s", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound(id)
+	}
+
+	if err := s.publishUser(ctx, tx, outbox.UserUpdated, id); err != nil {
+		return newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return nil
+}
+
+// DeleteUser deletes user specified by id. Deleting a user who does not exist is not an error,
+// deleting a user who has ledger entries is a conflict.
+func (s *Server) DeleteUser(ctx context.Context, id int) error {
+	var deleted models.User
+
+	query := "DELETE FROM users WHERE id=$1 RETURNING id, username, role, priority, created_at, updated_at"
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return newError(KindInternal, "Failed to start transaction; additional info: %s", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the ledger is kept for accounting, so users who have an entry can not be deleted
+	var hasLedger bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM ledger_entries WHERE user_id=$1)", id).Scan(&hasLedger); err != nil {
+		return newError(KindInternal, "Failed to retrieve data from database; additional info: %s", err)
+	}
+	if hasLedger {
+		return newError(KindConflict, "User {%d} has ledger entries and can not be deleted", id)
+	}
+
+	err = tx.QueryRow(ctx, query, id).Scan(&deleted.Id, &deleted.Username, &deleted.Role, &deleted.Priority, &deleted.CreatedAt, &deleted.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return newError(KindInternal, "Failed to delete specified user; additional info: %s", err)
+	}
+
+	if err := s.publish(ctx, tx, outbox.UserDeleted, deleted); err != nil {
+		return newError(KindInternal, "Failed to publish event; additional info: %s", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return newError(KindInternal, "Failed to commit transaction; additional info: %s", err)
+	}
+	return nil
+}
+
 // handleAddUser
 //
 // @Summary Add new user to database
@@ -26,8 +280,8 @@ import (
 //
 // @Param Username formData string true "6 <= length <= 20"
 // @Param password formData string true "length = 14"
-// @Param CreatedAt formData string true "format = YYYY-MM-DD HH:MM:SS"
-// @Param UpdatedAt formData string true "format = YYYY-MM-DD HH:MM:SS"
+// @Param CreatedAt formData string false "RFC 3339, set by server"
+// @Param UpdatedAt formData string false "RFC 3339, set by server"
 //
 // @Success 200 {object} integer "ok"
 // @Failure 400 {object} integer "Wrong ID"
@@ -45,24 +299,8 @@ func (s *Server) handleAddUser() http.HandlerFunc {
 			return
 		}
 
-		if err := validator.V.Struct(newUser); err != nil {
-			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
-			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
-			return
-		}
-
-		password, _ := bcrypt.GenerateFromPassword([]byte(newUser.Password), 14)
-
-		query := "INSERT INTO users (username,password,created_at,updated_at) VALUES ($1,$2,$3,$4)"
-
-		time := time.Now()
-		newUser.CreatedAt = time
-		newUser.UpdatedAt = time
-
-		_, err := s.database.Exec(context.Background(), query, newUser.Username, password, newUser.CreatedAt, newUser.UpdatedAt)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+		if _, err := s.CreateUser(r.Context(), newUser); err != nil {
+			s.writeError(w, err)
 			return
 		}
 
@@ -89,25 +327,13 @@ func (s *Server) handleGetUser() http.HandlerFunc {
 
 		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-		var User models.User
-
-		query := "SELECT id, username, password, created_at, updated_at FROM users WHERE id=$1;"
-
-		data := s.database.QueryRow(context.Background(), query, id)
-
-		err := data.Scan(&User.Id, &User.Username, &User.Password, &User.CreatedAt, &User.UpdatedAt)
-
-		if err == pgx.ErrNoRows {
-			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
-			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
-			return
-		} else if err != nil {
-			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+		User, err := s.GetUser(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(User)
+		writeJSON(w, r, User)
 		s.logger.Debug("Successfully retrieved user data")
 	}
 }
@@ -126,33 +352,18 @@ func (s *Server) handleGetUsers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		var userList []models.User
-
-		query := "SELECT id, username, password, create_time, update_time FROM users"
-		data, err := s.database.Query(context.Background(), query)
+		userList, err := s.ListUsers(r.Context())
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			s.writeError(w, err)
 			return
 		}
 
-		for data.Next() {
-			var User models.User
-			err = data.Scan(&User.Id, &User.Username, &User.Password, &User.CreatedAt, &User.UpdatedAt)
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
-				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
-				return
-			}
-			userList = append(userList, User)
-		}
-
 		if len(userList) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(userList)
+		writeJSON(w, r, userList)
 		s.logger.Debug("Successfully retrieved bookings data")
 	}
 }
@@ -160,13 +371,16 @@ func (s *Server) handleGetUsers() http.HandlerFunc {
 // handleUpdateUser
 //
 // @Summary Update user data
-// @Description Creates function which updates data of user specified by id in database
+// @Description Creates function which updates data of user specified by id in database.
+// @Description Requires basic auth of the user or an admin; only admins can change the role.
 // @Accept json
 //
 // @Param id path int true "User ID"
 //
 // @Success 200 {object} integer "ok"
 // @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
 // @Failure 500 {object} integer "Error scanning data from db response"
 // @Router /user/{id} [put]
 func (s *Server) handleUpdateUser() http.HandlerFunc {
@@ -183,69 +397,167 @@ func (s *Server) handleUpdateUser() http.HandlerFunc {
 			return
 		}
 
-		password, _ := bcrypt.GenerateFromPassword([]byte(newUserData.Password), 14)
+		if err := AuthorizeAccount(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+		if user, _ := userFromContext(r.Context()); newUserData.Role != "" && user.Role != models.RoleAdmin {
+			s.writeError(w, newError(KindForbidden, "Forbidden: only admins can change roles"))
+			return
+		}
+
+		if _, err := s.UpdateUser(r.Context(), id, newUserData); err != nil {
+			s.writeError(w, err)
+			return
+		}
 
-		time := time.Now()
-		newUserData.UpdatedAt = time
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated user data in database")
+	}
+}
 
-		var builder strings.Builder
+// handleUpdateUserPriority
+//
+// @Summary Update user waitlist priority
+// @Description Creates function which lets staff designate user specified by id as priority member,
+// @Description who is served before others from waitlists. Requires basic auth of staff or an admin.
+// @Accept json
+//
+// @Param id path int true "User ID"
+// @Param priority body bool true "json object with priority flag"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /user/{id}/priority [put]
+func (s *Server) handleUpdateUserPriority() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-		if newUserData.Password != "" {
-			if err := validator.V.Var(newUserData.Password, "required,excludes=\\/#@$"); err != nil {
-				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
-				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
-				return
-			}
-			builder.WriteString("password='")
-			builder.WriteString(string(password))
-			builder.WriteString("',")
+		var newPriorityData struct {
+			Priority bool `json:"priority"`
 		}
-		if newUserData.Username != "" {
-			if err := validator.V.Var(newUserData.Username, "required,min=6,max=20,excludes=\\/#@$"); err != nil {
-				http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
-				s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
-				return
-			}
-			builder.WriteString("username='")
-			builder.WriteString(newUserData.Username)
-			builder.WriteString("',")
+
+		if err := json.NewDecoder(r.Body).Decode(&newPriorityData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
 		}
 
-		query := fmt.Sprintf("UPDATE users SET %s updated_at=$1 WHERE id=$2", builder.String())
+		query := "UPDATE users SET priority=$1, updated_at=$2 WHERE id=$3"
+
+		ctx := context.Background()
 
-		_, err := s.database.Exec(context.Background(), query, newUserData.UpdatedAt, id)
+		tx, err := s.database.Begin(ctx)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s; querystr: %s", err, query), http.StatusInternalServerError)
-			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		tag, err := tx.Exec(ctx, query, newPriorityData.Priority, time.Now(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		if err := s.publishUser(ctx, tx, outbox.UserUpdated, id); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		s.logger.Debug("Successefully updated user data in database")
+		s.logger.Debug("Successefully updated user priority in database")
+	}
+}
+
+// handleUpdateUserRole
+//
+// @Summary Update user role
+// @Description Creates function which sets role (member, staff or admin) of user specified by id.
+// @Description Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "User ID"
+// @Param role body string true "json object with role field"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /user/{id}/role [put]
+func (s *Server) handleUpdateUserRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newRoleData struct {
+			Role string `json:"role"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&newRoleData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := s.SetUserRole(r.Context(), id, newRoleData.Role); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated user role in database")
 	}
 }
 
 // handleDeleteUser
 //
 // @Summary Delete specified user data
-// @Description Creates function which deletes data of user specified by id from database
+// @Description Creates function which deletes data of user specified by id from database.
+// @Description Requires basic auth of the user or an admin.
 //
 // @Param id path int true "User ID"
 //
 // @Success 200 {object} integer "ok"
 // @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 409 {object} integer "User has ledger entries"
 // @Router /user/{id} [delete]
 func (s *Server) handleDeleteUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		id := mux.Vars(r)["id"]
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-		query := "DELETE FROM users WHERE id=$1"
-		_, err := s.database.Exec(context.Background(), query, id)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to delete specified user; additional info: %s", err), http.StatusBadRequest)
-			s.logger.Error(fmt.Sprintf("Failed to delete specified user; additional info: %s", err))
+		if err := AuthorizeAccount(r.Context(), id); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if err := s.DeleteUser(r.Context(), id); err != nil {
+			s.writeError(w, err)
 			return
 		}
 
@@ -0,0 +1,640 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/outbox"
+	"github.com/alexey-dobry/booking-service/server/internal/schedule"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// holdDuration is how long a waitlisted user has to confirm an offered booking
+const holdDuration = 15 * time.Minute
+
+// holdExpiryInterval is how often expired holds are released
+const holdExpiryInterval = time.Minute
+
+const waitlistColumns = "w.id, w.user_id, w.resource_id, w.start_time, w.end_time, w.party_size, w.text, w.status, u.priority, w.booking_id, b.hold_expires_at, w.created_at"
+
+const waitlistFrom = "waitlist_entries w JOIN users u ON u.id = w.user_id LEFT JOIN bookings b ON b.id = w.booking_id"
+
+// waitlistOrder puts members designated by staff first, then keeps FIFO order
+const waitlistOrder = "u.priority DESC, w.created_at, w.id"
+
+func scanWaitlistEntry(row pgx.Row, e *models.WaitlistEntry) error {
+	return row.Scan(&e.Id, &e.UserId, &e.ResourceId, &e.StartTime, &e.EndTime, &e.PartySize, &e.Text, &e.Status, &e.Priority, &e.BookingId, &e.HoldExpiresAt, &e.CreatedAt)
+}
+
+func getWaitlistEntry(ctx context.Context, q querier, id int) (models.WaitlistEntry, error) {
+	var e models.WaitlistEntry
+	err := scanWaitlistEntry(q.QueryRow(ctx, "SELECT "+waitlistColumns+" FROM "+waitlistFrom+" WHERE w.id=$1", id), &e)
+	return e, err
+}
+
+// addToWaitlist queues e and immediately tries to offer it, in case the window is already free
+func (s *Server) addToWaitlist(ctx context.Context, tx pgx.Tx, e *models.WaitlistEntry) error {
+	query := "INSERT INTO waitlist_entries (user_id,resource_id,start_time,end_time,party_size,text,status,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
+
+	err := tx.QueryRow(ctx, query, e.UserId, e.ResourceId, e.StartTime, e.EndTime, e.PartySize, e.Text, models.WaitlistWaiting, time.Now()).Scan(&e.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.promoteWaitlist(ctx, tx, e.ResourceId); err != nil {
+		return err
+	}
+
+	*e, err = getWaitlistEntry(ctx, tx, e.Id)
+	return err
+}
+
+// promoteWaitlist offers free time on resourceId to waiting users. Entries are visited in
+// queue order and every entry whose whole window is free, and whose user meets the booking
+// policy and can afford the booking, gets a held booking which blocks the slot until the user
+// confirms it or the hold expires. The resource must be locked.
+func (s *Server) promoteWaitlist(ctx context.Context, tx pgx.Tx, resourceId int) ([]models.WaitlistEntry, error) {
+	now := time.Now()
+
+	query := "SELECT " + waitlistColumns + " FROM " + waitlistFrom + " WHERE w.resource_id=$1 AND w.status=$2 AND w.start_time > $3 ORDER BY " + waitlistOrder
+	rows, err := tx.Query(ctx, query, resourceId, models.WaitlistWaiting, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var waiting []models.WaitlistEntry
+	for rows.Next() {
+		var e models.WaitlistEntry
+		if err := scanWaitlistEntry(rows, &e); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		waiting = append(waiting, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var offered []models.WaitlistEntry
+	for _, e := range waiting {
+		conflictId, err := findOverlap(ctx, tx, resourceId, e.StartTime, e.EndTime, e.PartySize, 0)
+		if err != nil {
+			return offered, err
+		}
+		if conflictId != 0 {
+			continue
+		}
+
+		// the window may have been closed for maintenance or a holiday since the user joined
+		err = s.checkSchedule(ctx, tx, resourceId, e.StartTime, e.EndTime)
+		var violation *schedule.Violation
+		if errors.As(err, &violation) {
+			continue
+		} else if err != nil {
+			return offered, err
+		}
+
+		expiresAt := now.Add(holdDuration)
+
+		held := models.Booking{UserId: e.UserId, ResourceId: e.ResourceId, StartTime: e.StartTime, EndTime: e.EndTime, PartySize: e.PartySize}
+		if err := s.priceBooking(ctx, tx, &held); err != nil {
+			return offered, err
+		}
+
+		// the user must still be allowed to book and able to pay, as when booking directly;
+		// otherwise the entry keeps waiting and the time goes to the next one
+		rules, err := loadPolicy(ctx, tx, resourceId, e.UserId)
+		if err != nil {
+			return offered, err
+		}
+		violations, err := s.checkPolicy(ctx, tx, rules, held)
+		if err != nil {
+			return offered, err
+		}
+		if len(violations) > 0 {
+			continue
+		}
+		if err := s.checkFunds(ctx, tx, e.UserId, held.Price); errorKind(err) == KindInsufficientFunds {
+			continue
+		} else if err != nil {
+			return offered, err
+		}
+
+		query := "INSERT INTO bookings (user_id,resource_id,start_time,end_time,party_size,text,status,hold_expires_at,price,price_breakdown) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING " + bookingColumns
+		err = scanBooking(tx.QueryRow(ctx, query, e.UserId, e.ResourceId, e.StartTime, e.EndTime, e.PartySize, e.Text, models.BookingHeld, expiresAt, held.Price, held.PriceBreakdown), &held)
+		if err != nil {
+			return offered, err
+		}
+		if err := s.publish(ctx, tx, outbox.BookingCreated, held); err != nil {
+			return offered, err
+		}
+		bookingId := held.Id
+
+		if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$2, booking_id=$3 WHERE id=$1", e.Id, models.WaitlistOffered, bookingId); err != nil {
+			return offered, err
+		}
+
+		e.Status, e.BookingId, e.HoldExpiresAt = models.WaitlistOffered, &bookingId, &expiresAt
+		offered = append(offered, e)
+	}
+
+	return offered, nil
+}
+
+// expireHolds releases held bookings which were not confirmed in time, passes the
+// freed time on to the next users in the queue and expires entries whose window has started
+func (s *Server) expireHolds(ctx context.Context) error {
+	now := time.Now()
+
+	tx, err := s.database.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT DISTINCT resource_id FROM bookings WHERE status=$1 AND hold_expires_at < $2", models.BookingHeld, now)
+	if err != nil {
+		return err
+	}
+	resourceIds, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+
+	if err := lockResources(ctx, tx, resourceIds...); err != nil {
+		return err
+	}
+
+	expired := "SELECT id FROM bookings WHERE status=$2 AND hold_expires_at < $3 AND resource_id = ANY($4)"
+	if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$1 WHERE booking_id IN ("+expired+")", models.WaitlistExpired, models.BookingHeld, now, resourceIds); err != nil {
+		return err
+	}
+	released, err := cancelBookingsWhere(ctx, tx, "status=$1 AND hold_expires_at < $2 AND resource_id = ANY($3)", models.BookingHeld, now, resourceIds)
+	if err != nil {
+		return err
+	}
+	if err := s.publishBookings(ctx, tx, outbox.BookingCancelled, released); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$1 WHERE status=$2 AND start_time <= $3", models.WaitlistExpired, models.WaitlistWaiting, now); err != nil {
+		return err
+	}
+
+	for _, resourceId := range resourceIds {
+		offered, err := s.promoteWaitlist(ctx, tx, resourceId)
+		if err != nil {
+			return err
+		}
+		for _, e := range offered {
+			s.logger.Debug(fmt.Sprintf("Waitlist entry {%d} was offered booking {%d}", e.Id, *e.BookingId))
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// runHoldExpiry periodically calls expireHolds until ctx is cancelled
+func (s *Server) runHoldExpiry(ctx context.Context) {
+	ticker := time.NewTicker(holdExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.expireHolds(ctx); err != nil {
+				s.logger.Error(fmt.Sprintf("Failed to expire waitlist holds; additional info: %s", err))
+			}
+		}
+	}
+}
+
+// handleAddWaitlistEntry
+//
+// @Summary Adds user to waitlist
+// @Description Creates function which queues user for a resource and time window. As soon as the window is free
+// @Description (now or after a booking is cancelled or shortened) a held booking is offered which must be
+// @Description confirmed before hold_expires_at. Members with priority are served first, others in FIFO order.
+// @Description Requires basic auth of the user of the entry, staff or an admin.
+// @Accept json
+// @Produces json
+//
+// @Param entry body models.WaitlistEntry true "user_id, resource_id, start_time, end_time, text"
+//
+// @Success 201 {object} models.WaitlistEntry "ok"
+// @Failure 400 {array} policy.Violation "Incorrect input data or requested booking breaks policy rules (list of violations)"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /waitlist [post]
+func (s *Server) handleAddWaitlistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newEntry models.WaitlistEntry
+
+		if err := json.NewDecoder(r.Body).Decode(&newEntry); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newEntry); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if !newEntry.EndTime.After(newEntry.StartTime) {
+			http.Error(w, "TimeError: end_time is before start_time", http.StatusBadRequest)
+			s.logger.Debug("TimeError: end_time is before start_time")
+			return
+		}
+
+		if err := AuthorizeUser(r.Context(), newEntry.UserId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		if newEntry.PartySize == 0 {
+			newEntry.PartySize = 1
+		}
+		if err := checkPartySize(ctx, tx, newEntry.ResourceId, newEntry.PartySize); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if !s.checkBookingSchedule(w, tx, newEntry.ResourceId, newEntry.StartTime, newEntry.EndTime) {
+			return
+		}
+
+		if err := lockResource(ctx, tx, newEntry.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+			return
+		}
+
+		booking := models.Booking{UserId: newEntry.UserId, ResourceId: newEntry.ResourceId, StartTime: newEntry.StartTime, EndTime: newEntry.EndTime, PartySize: newEntry.PartySize}
+		if !s.checkBookingPolicy(w, tx, booking) {
+			return
+		}
+
+		if err := s.addToWaitlist(ctx, tx, &newEntry); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newEntry)
+		s.logger.Debug("Successefully added waitlist entry to database")
+	}
+}
+
+// handleGetWaitlist
+//
+// @Summary Get waitlist
+// @Description Creates function which retrieves active (waiting and offered) waitlist entries in queue order,
+// @Description optionally filtered by resource. Requires basic auth; members only get their own entries.
+// @Produces json
+//
+// @Param resource_id query int false "Resource ID"
+//
+// @Success 200 {array} models.WaitlistEntry "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /waitlist [get]
+func (s *Server) handleGetWaitlist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resourceId, _ := strconv.Atoi(r.URL.Query().Get("resource_id"))
+
+		// staff and admins see the whole queue, members their own entries
+		userId := 0
+		if user, _ := userFromContext(r.Context()); user.Role == models.RoleMember {
+			userId = user.Id
+		}
+
+		var entryList []models.WaitlistEntry
+
+		query := "SELECT " + waitlistColumns + " FROM " + waitlistFrom + " WHERE w.status IN ($1,$2) AND ($3 = 0 OR w.resource_id = $3) AND ($4 = 0 OR w.user_id = $4) ORDER BY w.resource_id, " + waitlistOrder
+		data, err := s.database.Query(context.Background(), query, models.WaitlistWaiting, models.WaitlistOffered, resourceId, userId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+		defer data.Close()
+
+		for data.Next() {
+			var Entry models.WaitlistEntry
+			err = scanWaitlistEntry(data, &Entry)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			entryList = append(entryList, Entry)
+		}
+
+		if len(entryList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, entryList)
+		s.logger.Debug("Successfully retrieved waitlist data")
+	}
+}
+
+// handleGetWaitlistEntry
+//
+// @Summary Get waitlist entry
+// @Description Creates function which retrieves waitlist entry specified by id. Clients poll it to find out
+// @Description whether a booking was offered. Requires basic auth of the user of the entry, staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Waitlist entry ID"
+//
+// @Success 200 {object} models.WaitlistEntry "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /waitlist/{id} [get]
+func (s *Server) handleGetWaitlistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		Entry, err := getWaitlistEntry(context.Background(), s.database, id)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		if err := AuthorizeUser(r.Context(), Entry.UserId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		writeJSON(w, r, Entry)
+		s.logger.Debug("Successfully retrieved waitlist entry data")
+	}
+}
+
+// handleConfirmWaitlistEntry
+//
+// @Summary Confirm offered booking
+// @Description Creates function which turns the held booking offered to waitlist entry specified by id
+// @Description into a confirmed booking, or a pending one if the resource requires approval.
+// @Description Requires basic auth of the user of the booking, staff or an admin.
+// @Produces json
+//
+// @Param id path int true "Waitlist entry ID"
+//
+// @Success 200 {object} models.Booking "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 402 {object} integer "Booking costs more than the balance of the user"
+// @Failure 409 {object} integer "Nothing was offered or the offer expired"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /waitlist/{id}/confirm [post]
+func (s *Server) handleConfirmWaitlistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		entry, err := getWaitlistEntry(ctx, tx, id)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		if err := AuthorizeUser(r.Context(), entry.UserId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if entry.Status != models.WaitlistOffered || entry.BookingId == nil {
+			http.Error(w, fmt.Sprintf("Waitlist entry {%d} has no active offer; status: %s", id, entry.Status), http.StatusConflict)
+			s.logger.Debug(fmt.Sprintf("Waitlist entry {%d} has no active offer; status: %s", id, entry.Status))
+			return
+		}
+
+		// the held booking may have been moved to another resource, so its current one is locked
+		held, err := lockBooking(ctx, tx, *entry.BookingId)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		// the offer may have expired or been declined while waiting for the lock
+		if entry, err = getWaitlistEntry(ctx, tx, id); err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		if entry.Status != models.WaitlistOffered || entry.BookingId == nil || *entry.BookingId != held.Id || held.Status != models.BookingHeld ||
+			entry.HoldExpiresAt == nil || entry.HoldExpiresAt.Before(time.Now()) {
+			http.Error(w, fmt.Sprintf("Waitlist entry {%d} has no active offer; status: %s", id, entry.Status), http.StatusConflict)
+			s.logger.Debug(fmt.Sprintf("Waitlist entry {%d} has no active offer; status: %s", id, entry.Status))
+			return
+		}
+
+		// on resources which require approval the confirmed offer still waits for review by staff
+		status, err := newBookingStatus(ctx, tx, held.ResourceId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to check resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to check resource; additional info: %s", err))
+			return
+		}
+
+		query := "UPDATE bookings SET status=$2, hold_expires_at=NULL, sequence=sequence+1, updated_at=NOW() WHERE id=$1 AND status=$3"
+		tag, err := tx.Exec(ctx, query, held.Id, status, models.BookingHeld)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("Waitlist entry {%d} has no active offer; booking {%d} is no longer held", id, held.Id), http.StatusConflict)
+			s.logger.Debug(fmt.Sprintf("Waitlist entry {%d} has no active offer; booking {%d} is no longer held", id, held.Id))
+			return
+		}
+		if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$2 WHERE id=$1", id, models.WaitlistFulfilled); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+
+		Booking, err := getBooking(ctx, tx, *entry.BookingId)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+
+		// held bookings are charged once they are confirmed
+		if err := s.chargeBooking(ctx, tx, Booking); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if err := requestApproval(ctx, tx, Booking); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to request approval; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to request approval; additional info: %s", err))
+			return
+		}
+
+		if err := s.publish(ctx, tx, outbox.BookingUpdated, Booking); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to publish event; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to publish event; additional info: %s", err))
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		writeJSON(w, r, Booking)
+		s.logger.Debug("Successefully confirmed offered booking")
+	}
+}
+
+// handleDeleteWaitlistEntry
+//
+// @Summary Leave waitlist
+// @Description Creates function which removes waitlist entry specified by id. A pending offer is declined
+// @Description and passed on to the next user in the queue. Requires basic auth of the user of the entry,
+// @Description staff or an admin.
+//
+// @Param id path int true "Waitlist entry ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /waitlist/{id} [delete]
+func (s *Server) handleDeleteWaitlistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		ctx := context.Background()
+
+		tx, err := s.database.Begin(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to start transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to start transaction; additional info: %s", err))
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		entry, err := getWaitlistEntry(ctx, tx, id)
+		if err == pgx.ErrNoRows {
+			w.WriteHeader(http.StatusOK)
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified waitlist entry; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified waitlist entry; additional info: %s", err))
+			return
+		}
+
+		if err := AuthorizeUser(r.Context(), entry.UserId); err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		if err := lockResource(ctx, tx, entry.ResourceId); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to lock resource; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to lock resource; additional info: %s", err))
+			return
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE waitlist_entries SET status=$2 WHERE id=$1", id, models.WaitlistCancelled); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified waitlist entry; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified waitlist entry; additional info: %s", err))
+			return
+		}
+
+		if entry.Status == models.WaitlistOffered && entry.BookingId != nil {
+			released, err := cancelBookingsWhere(ctx, tx, "id=$1 AND status=$2", *entry.BookingId, models.BookingHeld)
+			if err == nil {
+				err = s.publishBookings(ctx, tx, outbox.BookingCancelled, released)
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to release held booking; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to release held booking; additional info: %s", err))
+				return
+			}
+			if _, err := s.promoteWaitlist(ctx, tx, entry.ResourceId); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to process waitlist; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to process waitlist; additional info: %s", err))
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to commit transaction; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to commit transaction; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully removed specified waitlist entry")
+	}
+}
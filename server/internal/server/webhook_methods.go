@@ -0,0 +1,520 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/validator"
+	"github.com/alexey-dobry/booking-service/server/internal/webhook"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// webhookInterval is how often due deliveries are looked for when no events were relayed
+	webhookInterval = 5 * time.Second
+	// webhookTimeout limits a single delivery attempt
+	webhookTimeout = 10 * time.Second
+	// webhookLease postpones claimed deliveries, so they are retried if the server stops mid-attempt
+	webhookLease = 2 * time.Minute
+	// webhookBatch is the number of deliveries claimed at once
+	webhookBatch = 20
+	// deliveryLogLimit is the number of deliveries returned by the delivery log
+	deliveryLogLimit = 100
+)
+
+const webhookColumns = "id, url, events, secret, disabled, created_at, updated_at"
+
+const deliveryColumns = "id, webhook_id, event_id, event, payload, status, attempts, next_attempt_at, response_code, last_error, created_at, delivered_at"
+
+func scanWebhook(row pgx.Row, h *models.Webhook) error {
+	return row.Scan(&h.Id, &h.Url, &h.Events, &h.Secret, &h.Disabled, &h.CreatedAt, &h.UpdatedAt)
+}
+
+func scanDelivery(row pgx.Row, d *models.WebhookDelivery) error {
+	return row.Scan(&d.Id, &d.WebhookId, &d.EventId, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.ResponseCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+}
+
+// wakeWebhooks makes the delivery loop look for due deliveries without waiting for the next tick
+func (s *Server) wakeWebhooks() {
+	select {
+	case s.webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// claimedDelivery is a delivery taken by this server together with its webhook
+type claimedDelivery struct {
+	id       int64
+	eventId  string
+	event    string
+	payload  []byte
+	attempts int
+	url      string
+	secret   string
+}
+
+// retryDelivery returns status of a delivery after attempts failed ones and when it is
+// attempted next; deliveries which failed webhook.MaxAttempts times are dead
+func retryDelivery(attempts int, now time.Time) (string, *time.Time) {
+	if attempts >= webhook.MaxAttempts {
+		return models.DeliveryDead, nil
+	}
+	next := now.Add(webhook.Backoff(attempts))
+	return models.DeliveryPending, &next
+}
+
+// deliverWebhooks sends a batch of due deliveries and returns how many were attempted.
+// Deliveries are claimed by moving their next attempt past the lease, so several
+// servers can share the queue and a crash mid-attempt only delays the delivery.
+func (s *Server) deliverWebhooks(ctx context.Context) (int, error) {
+	query := `UPDATE webhook_deliveries d SET next_attempt_at=$1 FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status=$2 AND next_attempt_at <= NOW() AND webhook_id IN (SELECT id FROM webhooks WHERE NOT disabled)
+			ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING d.id, d.event_id, d.event, d.payload, d.attempts, w.url, w.secret`
+	rows, err := s.database.Query(ctx, query, time.Now().Add(webhookLease), models.DeliveryPending, webhookBatch)
+	if err != nil {
+		return 0, err
+	}
+	claimed, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (claimedDelivery, error) {
+		var d claimedDelivery
+		err := row.Scan(&d.id, &d.eventId, &d.event, &d.payload, &d.attempts, &d.url, &d.secret)
+		return d, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range claimed {
+		result, sendErr := webhook.Send(ctx, s.webhookClient, d.url, d.secret, d.event, d.eventId, d.payload)
+
+		var responseCode *int
+		if result.StatusCode != 0 {
+			responseCode = &result.StatusCode
+		}
+
+		if sendErr == nil {
+			query := "UPDATE webhook_deliveries SET status=$2, attempts=attempts+1, response_code=$3, last_error=NULL, next_attempt_at=NULL, delivered_at=NOW() WHERE id=$1"
+			if _, err := s.database.Exec(ctx, query, d.id, models.DeliveryDelivered, responseCode); err != nil {
+				return len(claimed), err
+			}
+			s.logger.Debug(fmt.Sprintf("Webhook delivery {%d} of %s event was delivered", d.id, d.event))
+			continue
+		}
+
+		attempts := d.attempts + 1
+		status, nextAttemptAt := retryDelivery(attempts, time.Now())
+
+		lastError := sendErr.Error()
+		if result.Body != "" {
+			lastError = fmt.Sprintf("%s: %s", lastError, result.Body)
+		}
+
+		query := "UPDATE webhook_deliveries SET status=$2, attempts=$3, response_code=$4, last_error=$5, next_attempt_at=$6 WHERE id=$1"
+		if _, err := s.database.Exec(ctx, query, d.id, status, attempts, responseCode, lastError, nextAttemptAt); err != nil {
+			return len(claimed), err
+		}
+		s.logger.Debug(fmt.Sprintf("Webhook delivery {%d} of %s event failed, attempt %d; additional info: %s", d.id, d.event, attempts, lastError))
+	}
+
+	return len(claimed), nil
+}
+
+// runWebhookDelivery delivers webhooks periodically and right after events are relayed
+// until ctx is cancelled
+func (s *Server) runWebhookDelivery(ctx context.Context) {
+	ticker := time.NewTicker(webhookInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.webhookWake:
+		}
+
+		// full batches mean more deliveries are probably due
+		for {
+			n, err := s.deliverWebhooks(ctx)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("Failed to deliver webhooks; additional info: %s", err))
+				break
+			}
+			if n < webhookBatch {
+				break
+			}
+		}
+	}
+}
+
+// writeDeliveries responds with deliveries selected by query
+func (s *Server) writeDeliveries(w http.ResponseWriter, r *http.Request, query string, args ...any) {
+	var deliveryList []models.WebhookDelivery
+
+	data, err := s.database.Query(context.Background(), query, args...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+		s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+		return
+	}
+
+	for data.Next() {
+		var delivery models.WebhookDelivery
+		err = scanDelivery(data, &delivery)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+			return
+		}
+		deliveryList = append(deliveryList, delivery)
+	}
+
+	if len(deliveryList) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	writeJSON(w, r, deliveryList)
+	s.logger.Debug("Successfully retrieved webhook deliveries data")
+}
+
+// handleAddWebhook
+//
+// @Summary Add webhook subscription
+// @Description Creates function which subscribes url to events (booking.created, booking.updated, booking.cancelled,
+// @Description user.created, user.updated, user.deleted or * for all). Each event is posted as json with an
+// @Description X-Webhook-Signature header: sha256= and hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" keyed with
+// @Description the secret. A secret is generated when none is given; it is only returned here.
+// @Description Requires basic auth of an admin.
+// @Accept json
+// @Produces json
+//
+// @Param webhook body models.Webhook true "url, events and optional secret"
+//
+// @Success 201 {object} models.Webhook "ok"
+// @Failure 400 {object} integer "Incorrect input data"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error adding data to database"
+// @Router /webhooks [post]
+func (s *Server) handleAddWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var newWebhook models.Webhook
+
+		if err := json.NewDecoder(r.Body).Decode(&newWebhook); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newWebhook); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		if newWebhook.Secret == "" {
+			secret, err := newToken(24)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to generate secret; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to generate secret; additional info: %s", err))
+				return
+			}
+			newWebhook.Secret = secret
+		}
+
+		time := time.Now()
+		newWebhook.CreatedAt = time
+		newWebhook.UpdatedAt = time
+
+		query := "INSERT INTO webhooks (url,events,secret,disabled,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
+
+		err := s.database.QueryRow(context.Background(), query, newWebhook.Url, newWebhook.Events, newWebhook.Secret, newWebhook.Disabled, newWebhook.CreatedAt, newWebhook.UpdatedAt).Scan(&newWebhook.Id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to add data to database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to add data to database; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, newWebhook)
+		s.logger.Debug("Successefully added webhook to database")
+	}
+}
+
+// handleGetWebhook
+//
+// @Summary Get webhook subscription
+// @Description Creates function which retrieves webhook specified by id, without its secret.
+// @Description Requires basic auth of an admin.
+// @Produces json
+//
+// @Param id path int true "Webhook ID"
+//
+// @Success 200 {object} models.Webhook "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /webhooks/{id} [get]
+func (s *Server) handleGetWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var hook models.Webhook
+
+		err := scanWebhook(s.database.QueryRow(context.Background(), "SELECT "+webhookColumns+" FROM webhooks WHERE id=$1", id), &hook)
+		if err == pgx.ErrNoRows {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Internal error; more info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Internal error; more info: %s", err))
+			return
+		}
+		hook.Secret = ""
+
+		writeJSON(w, r, hook)
+		s.logger.Debug("Successfully retrieved webhook data")
+	}
+}
+
+// handleGetWebhooks
+//
+// @Summary Get webhook subscriptions
+// @Description Creates function which retrieves all webhooks, without their secrets. Requires basic auth of an admin.
+// @Produces json
+//
+// @Success 200 {array} models.Webhook "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /webhooks [get]
+func (s *Server) handleGetWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var webhookList []models.Webhook
+
+		data, err := s.database.Query(context.Background(), "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to retrieve data from database; additional info: %s", err))
+			return
+		}
+
+		for data.Next() {
+			var hook models.Webhook
+			err = scanWebhook(data, &hook)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to write data into object; additional info: %s", err), http.StatusInternalServerError)
+				s.logger.Error(fmt.Sprintf("Failed to write data into object; additional info: %s", err))
+				return
+			}
+			hook.Secret = ""
+			webhookList = append(webhookList, hook)
+		}
+
+		if len(webhookList) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, webhookList)
+		s.logger.Debug("Successfully retrieved webhooks data")
+	}
+}
+
+// handleUpdateWebhook
+//
+// @Summary Replace webhook subscription
+// @Description Creates function which replaces url, events and disabled flag of webhook specified by id.
+// @Description The secret is only changed when given. Deliveries of a disabled webhook wait until it is
+// @Description enabled again. Requires basic auth of an admin.
+// @Accept json
+//
+// @Param id path int true "Webhook ID"
+// @Param webhook body models.Webhook true "url, events, disabled and optional new secret"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /webhooks/{id} [put]
+func (s *Server) handleUpdateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var newWebhookData models.Webhook
+
+		if err := json.NewDecoder(r.Body).Decode(&newWebhookData); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode json; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Failed to decode json; additional info: %s", err))
+			return
+		}
+
+		if err := validator.V.Struct(newWebhookData); err != nil {
+			http.Error(w, fmt.Sprintf("Incorrect input data: %s", err), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("Incorrect input data: %s", err))
+			return
+		}
+
+		query := "UPDATE webhooks SET url=$2, events=$3, secret=COALESCE(NULLIF($4, ''), secret), disabled=$5, updated_at=$6 WHERE id=$1"
+
+		tag, err := s.database.Exec(context.Background(), query, id, newWebhookData.Url, newWebhookData.Events, newWebhookData.Secret, newWebhookData.Disabled, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No entry with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No entry with id {%d} was found in database", id))
+			return
+		}
+
+		// deliveries may have been waiting for the webhook to be enabled
+		s.wakeWebhooks()
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully updated webhook in database")
+	}
+}
+
+// handleDeleteWebhook
+//
+// @Summary Delete webhook subscription
+// @Description Creates function which deletes webhook specified by id together with its delivery log.
+// @Description Requires basic auth of an admin.
+//
+// @Param id path int true "Webhook ID"
+//
+// @Success 200 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong Id"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Router /webhooks/{id} [delete]
+func (s *Server) handleDeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := mux.Vars(r)["id"]
+
+		_, err := s.database.Exec(context.Background(), "DELETE FROM webhooks WHERE id=$1", id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to delete specified webhook; additional info: %s", err), http.StatusBadRequest)
+			s.logger.Error(fmt.Sprintf("Failed to delete specified webhook; additional info: %s", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		s.logger.Debug("Successefully deleted specified webhook from database")
+	}
+}
+
+// handleGetWebhookDeliveries
+//
+// @Summary Get webhook delivery log
+// @Description Creates function which retrieves the latest 100 deliveries of webhook specified by id, newest first,
+// @Description with status, number of attempts, last response code and error. Requires basic auth of an admin.
+// @Produces json
+//
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending | delivered | dead"
+//
+// @Success 200 {array} models.WebhookDelivery "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /webhooks/{id}/deliveries [get]
+func (s *Server) handleGetWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE webhook_id=$1 AND ($2 = '' OR status = $2) ORDER BY id DESC LIMIT $3"
+		s.writeDeliveries(w, r, query, id, r.URL.Query().Get("status"), deliveryLogLimit)
+	}
+}
+
+// handleGetDeadDeliveries
+//
+// @Summary Get dead webhook deliveries
+// @Description Creates function which retrieves deliveries of all webhooks which failed every attempt and are no
+// @Description longer retried, oldest first. Requires basic auth of an admin.
+// @Produces json
+//
+// @Success 200 {array} models.WebhookDelivery "ok"
+// @Success 204 {object} integer "no content"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error scanning data from db response"
+// @Router /webhook-deliveries/dead [get]
+func (s *Server) handleGetDeadDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE status=$1 ORDER BY id"
+		s.writeDeliveries(w, r, query, models.DeliveryDead)
+	}
+}
+
+// handleRetryDelivery
+//
+// @Summary Retry webhook delivery
+// @Description Creates function which queues delivery specified by id again with a fresh set of attempts,
+// @Description e.g. after the receiver of a dead delivery was fixed. Requires basic auth of an admin.
+//
+// @Param id path int true "Delivery ID"
+//
+// @Success 202 {object} integer "ok"
+// @Failure 400 {object} integer "Wrong ID"
+// @Failure 401 {object} integer "Unauthorized"
+// @Failure 403 {object} integer "Forbidden"
+// @Failure 500 {object} integer "Error updating data in database"
+// @Router /webhook-deliveries/{id}/retry [post]
+func (s *Server) handleRetryDelivery() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+		query := "UPDATE webhook_deliveries SET status=$2, attempts=0, next_attempt_at=NOW() WHERE id=$1 AND status <> $2"
+
+		tag, err := s.database.Exec(context.Background(), query, id, models.DeliveryPending)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update data in database; additional info: %s", err), http.StatusInternalServerError)
+			s.logger.Error(fmt.Sprintf("Failed to update data in database; additional info: %s", err))
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			http.Error(w, fmt.Sprintf("No finished delivery with id {%d} was found in database", id), http.StatusBadRequest)
+			s.logger.Debug(fmt.Sprintf("No finished delivery with id {%d} was found in database", id))
+			return
+		}
+
+		s.wakeWebhooks()
+
+		w.WriteHeader(http.StatusAccepted)
+		s.logger.Debug(fmt.Sprintf("Successefully queued webhook delivery {%d} again", id))
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexey-dobry/booking-service/server/internal/models"
+	"github.com/alexey-dobry/booking-service/server/internal/webhook"
+)
+
+func TestRetryDelivery(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	status, next := retryDelivery(1, now)
+	if status != models.DeliveryPending || next == nil || !next.Equal(now.Add(30*time.Second)) {
+		t.Errorf("after the first failure = %s, %v, want pending in 30s", status, next)
+	}
+
+	status, next = retryDelivery(webhook.MaxAttempts-1, now)
+	if status != models.DeliveryPending || next == nil || !next.Equal(now.Add(webhook.Backoff(webhook.MaxAttempts-1))) {
+		t.Errorf("before the last attempt = %s, %v, want pending", status, next)
+	}
+
+	status, next = retryDelivery(webhook.MaxAttempts, now)
+	if status != models.DeliveryDead || next != nil {
+		t.Errorf("after the last attempt = %s, %v, want dead without a next attempt", status, next)
+	}
+}
@@ -1,7 +1,26 @@
 package validator
 
 import (
+	"time"
+
 	"github.com/go-playground/validator"
 )
 
 var V = validator.New()
+
+func init() {
+	V.RegisterValidation("clock", isClock)
+	V.RegisterValidation("date", isDate)
+}
+
+// isClock checks that field is a time of day in HH:MM format
+func isClock(fl validator.FieldLevel) bool {
+	_, err := time.Parse("15:04", fl.Field().String())
+	return err == nil
+}
+
+// isDate checks that field is a date in YYYY-MM-DD format
+func isDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.DateOnly, fl.Field().String())
+	return err == nil
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AllEvents subscribes a webhook to every event type
+const AllEvents = "*"
+
+// Headers of delivery requests
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderId        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	// MaxAttempts is the number of failed attempts after which a delivery is dead
+	MaxAttempts = 8
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	// only the beginning of a response body is kept in the delivery log
+	maxResponseBody = 512
+)
+
+// Sign returns signature of body sent at timestamp: hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with secret, prefixed with "sha256=". Receivers should
+// recompute it and reject requests with old timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body sent at timestamp
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Backoff returns delay before the next attempt after attempts failed ones:
+// 30s, 1m, 2m, ... doubling up to 6h
+func Backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Result describes a delivery attempt
+type Result struct {
+	StatusCode int
+	Body       string
+}
+
+// Send posts signed body of event eventId to url. Any 2xx response is a success;
+// other responses are returned as an error together with the result.
+func Send(ctx context.Context, client *http.Client, url, secret, eventType, eventId string, body []byte) (Result, error) {
+	var result Result
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "booking-service-webhooks")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderId, eventId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result = Result{StatusCode: resp.StatusCode, Body: string(data)}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+	return result, nil
+}
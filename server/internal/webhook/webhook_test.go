@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	// printf '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+
+	if got := Sign("secret", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if !Verify("secret", 1700000000, body, want) {
+		t.Error("Verify rejected a valid signature")
+	}
+	if Verify("secret", 1700000001, body, want) {
+		t.Error("Verify accepted a signature of another timestamp")
+	}
+	if Verify("other", 1700000000, body, want) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{10, 4*time.Hour + 16*time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	var failures int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil || !Verify("secret", timestamp, data, r.Header.Get(HeaderSignature)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != "booking.created" || r.Header.Get(HeaderId) != "event-1" {
+			http.Error(w, "bad headers", http.StatusBadRequest)
+			return
+		}
+		// the receiver is down for the first attempt
+		if failures == 0 {
+			failures++
+			http.Error(w, strings.Repeat("x", 2*maxResponseBody), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	result, err := Send(context.Background(), receiver.Client(), receiver.URL, "secret", "booking.created", "event-1", body)
+	if err == nil {
+		t.Fatal("Send returned no error for a 503 response")
+	}
+	if result.StatusCode != http.StatusServiceUnavailable || len(result.Body) != maxResponseBody {
+		t.Errorf("result = %d with %d bytes of body, want 503 with %d", result.StatusCode, len(result.Body), maxResponseBody)
+	}
+
+	// the retry is signed again and accepted
+	result, err = Send(context.Background(), receiver.Client(), receiver.URL, "secret", "booking.created", "event-1", body)
+	if err != nil {
+		t.Fatalf("retry failed: %s", err)
+	}
+	if result.StatusCode != http.StatusNoContent {
+		t.Errorf("retry status = %d, want 204", result.StatusCode)
+	}
+}
+
+func TestSendWrongSecret(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify("secret", timestamp, data, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer receiver.Close()
+
+	result, err := Send(context.Background(), receiver.Client(), receiver.URL, "other", "booking.created", "event-1", []byte(`{}`))
+	if err == nil || result.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Send = %d, %v, want a 401 error", result.StatusCode, err)
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	result, err := Send(context.Background(), http.DefaultClient, url, "secret", "booking.created", "event-1", []byte(`{}`))
+	if err == nil || result.StatusCode != 0 {
+		t.Errorf("Send = %d, %v, want a connection error without status", result.StatusCode, err)
+	}
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS resources (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  type TEXT NOT NULL,
+  zone TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+-- bookings created before resources existed are moved to a generic resource
+INSERT INTO resources (name, type, created_at, updated_at)
+SELECT 'general', 'general', NOW(), NOW()
+WHERE EXISTS (SELECT 1 FROM bookings);
+
+ALTER TABLE bookings ADD COLUMN resource_id INT;
+UPDATE bookings SET resource_id = (SELECT id FROM resources WHERE name = 'general');
+ALTER TABLE bookings ALTER COLUMN resource_id SET NOT NULL;
+ALTER TABLE bookings ADD CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE;
+
+CREATE INDEX bookings_resource_time_idx ON bookings (resource_id, start_time, end_time);
+
+-- +goose Down
+DROP INDEX bookings_resource_time_idx;
+ALTER TABLE bookings DROP COLUMN resource_id;
+DROP TABLE resources;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS booking_series (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL,
+  resource_id INT NOT NULL,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  rrule TEXT NOT NULL,
+  exdates TIMESTAMP[] NOT NULL DEFAULT '{}',
+  text TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+ALTER TABLE bookings ADD COLUMN series_id INT;
+ALTER TABLE bookings ADD CONSTRAINT fk_series FOREIGN KEY (series_id) REFERENCES booking_series (id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE;
+
+CREATE INDEX bookings_series_idx ON bookings (series_id, start_time);
+
+-- +goose Down
+DROP INDEX bookings_series_idx;
+ALTER TABLE bookings DROP COLUMN series_id;
+DROP TABLE booking_series;
@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN priority BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE bookings ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+ALTER TABLE bookings ADD COLUMN hold_expires_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL,
+  resource_id INT NOT NULL,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  text TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'waiting',
+  booking_id INT,
+  created_at TIMESTAMP NOT NULL,
+
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings (id)
+    ON DELETE SET NULL
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX waitlist_entries_queue_idx ON waitlist_entries (resource_id, status, created_at);
+CREATE INDEX bookings_hold_idx ON bookings (hold_expires_at) WHERE status = 'held';
+
+-- +goose Down
+DROP INDEX bookings_hold_idx;
+DROP TABLE waitlist_entries;
+ALTER TABLE bookings DROP COLUMN hold_expires_at;
+ALTER TABLE bookings DROP COLUMN status;
+ALTER TABLE users DROP COLUMN priority;
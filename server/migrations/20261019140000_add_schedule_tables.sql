@@ -0,0 +1,49 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+CREATE TABLE IF NOT EXISTS opening_hours (
+  id SERIAL PRIMARY KEY,
+  resource_id INT,
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  open_time TIME NOT NULL,
+  close_time TIME NOT NULL,
+
+  CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS schedule_exceptions (
+  id SERIAL PRIMARY KEY,
+  resource_id INT,
+  date DATE NOT NULL,
+  closed BOOLEAN NOT NULL,
+  open_time TIME,
+  close_time TIME,
+  reason TEXT NOT NULL DEFAULT '',
+
+  CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id SERIAL PRIMARY KEY,
+  resource_id INT NOT NULL,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+
+  CONSTRAINT fk_resource FOREIGN KEY (resource_id) REFERENCES resources (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX schedule_exceptions_date_idx ON schedule_exceptions (date);
+CREATE INDEX maintenance_windows_resource_idx ON maintenance_windows (resource_id, start_time);
+
+-- +goose Down
+DROP TABLE maintenance_windows;
+DROP TABLE schedule_exceptions;
+DROP TABLE opening_hours;
+ALTER TABLE users DROP COLUMN role;
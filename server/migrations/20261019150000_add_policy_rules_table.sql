@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_rules (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  value INT NOT NULL CHECK (value > 0),
+  resource_type TEXT,
+  role TEXT,
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX bookings_user_time_idx ON bookings (user_id, end_time);
+
+-- +goose Down
+DROP INDEX bookings_user_time_idx;
+DROP TABLE policy_rules;
@@ -0,0 +1,79 @@
+-- +goose Up
+-- Existing values were written as wall clock time of the venue without offset, so they are
+-- converted in the venue time zone passed by the migrator (VENUE_TIMEZONE, UTC by default)
+SELECT set_config('TimeZone', COALESCE(NULLIF(current_setting('booking.venue_timezone', true), ''), 'UTC'), true);
+
+ALTER TABLE booking_series ALTER COLUMN exdates DROP DEFAULT;
+
+ALTER TABLE users
+  ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+  ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE resources
+  ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+  ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE bookings
+  ALTER COLUMN start_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN end_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN hold_expires_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE booking_series
+  ALTER COLUMN start_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN end_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+  ALTER COLUMN exdates TYPE TIMESTAMPTZ[];
+
+ALTER TABLE waitlist_entries
+  ALTER COLUMN start_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN end_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE maintenance_windows
+  ALTER COLUMN start_time TYPE TIMESTAMPTZ,
+  ALTER COLUMN end_time TYPE TIMESTAMPTZ;
+
+ALTER TABLE policy_rules
+  ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+  ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE booking_series ALTER COLUMN exdates SET DEFAULT '{}';
+
+-- +goose Down
+SELECT set_config('TimeZone', COALESCE(NULLIF(current_setting('booking.venue_timezone', true), ''), 'UTC'), true);
+
+ALTER TABLE booking_series ALTER COLUMN exdates DROP DEFAULT;
+
+ALTER TABLE users
+  ALTER COLUMN created_at TYPE TIMESTAMP,
+  ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE resources
+  ALTER COLUMN created_at TYPE TIMESTAMP,
+  ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE bookings
+  ALTER COLUMN start_time TYPE TIMESTAMP,
+  ALTER COLUMN end_time TYPE TIMESTAMP,
+  ALTER COLUMN hold_expires_at TYPE TIMESTAMP;
+
+ALTER TABLE booking_series
+  ALTER COLUMN start_time TYPE TIMESTAMP,
+  ALTER COLUMN end_time TYPE TIMESTAMP,
+  ALTER COLUMN created_at TYPE TIMESTAMP,
+  ALTER COLUMN exdates TYPE TIMESTAMP[];
+
+ALTER TABLE waitlist_entries
+  ALTER COLUMN start_time TYPE TIMESTAMP,
+  ALTER COLUMN end_time TYPE TIMESTAMP,
+  ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE maintenance_windows
+  ALTER COLUMN start_time TYPE TIMESTAMP,
+  ALTER COLUMN end_time TYPE TIMESTAMP;
+
+ALTER TABLE policy_rules
+  ALTER COLUMN created_at TYPE TIMESTAMP,
+  ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE booking_series ALTER COLUMN exdates SET DEFAULT '{}';
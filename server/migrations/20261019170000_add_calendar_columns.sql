@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE bookings ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE users ADD COLUMN calendar_token TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN calendar_token;
+-- cancelled bookings did not exist before
+DELETE FROM bookings WHERE status = 'cancelled';
+ALTER TABLE bookings DROP COLUMN updated_at;
+ALTER TABLE bookings DROP COLUMN sequence;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Every replica listens on the outbox channel to stream events to its own subscribers
+-- +goose StatementBegin
+CREATE FUNCTION notify_outbox() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('outbox', NEW.position::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER outbox_notify AFTER INSERT ON outbox
+  FOR EACH ROW EXECUTE FUNCTION notify_outbox();
+
+-- +goose Down
+DROP TRIGGER outbox_notify ON outbox;
+DROP FUNCTION notify_outbox();
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tariffs (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  resource_type TEXT,
+  days TEXT NOT NULL DEFAULT 'all',
+  start_time TIME NOT NULL,
+  end_time TIME NOT NULL,
+  price_per_hour INT NOT NULL CHECK (price_per_hour >= 0),
+  priority INT NOT NULL DEFAULT 0,
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS discounts (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  percent INT NOT NULL CHECK (percent BETWEEN 1 AND 100),
+  role TEXT,
+  resource_type TEXT,
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings ADD COLUMN price INT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN price_breakdown JSONB;
+
+-- +goose Down
+ALTER TABLE bookings DROP COLUMN price_breakdown;
+ALTER TABLE bookings DROP COLUMN price;
+DROP TABLE discounts;
+DROP TABLE tariffs;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  user_id INT NOT NULL,
+  kind TEXT NOT NULL,
+  amount INT NOT NULL CHECK (amount <> 0),
+  account TEXT NOT NULL,
+  booking_id INT,
+  balance INT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_by INT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings (id)
+    ON DELETE SET NULL,
+  CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX ledger_entries_user_idx ON ledger_entries (user_id, id);
+CREATE INDEX ledger_entries_booking_idx ON ledger_entries (booking_id);
+
+-- +goose Down
+DROP TABLE ledger_entries;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS cancellation_rules (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  resource_type TEXT,
+  hours_before INT NOT NULL CHECK (hours_before >= 0),
+  fee_percent INT NOT NULL CHECK (fee_percent BETWEEN 0 AND 100),
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings ADD COLUMN cancellation_fee INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE bookings DROP COLUMN cancellation_fee;
+DROP TABLE cancellation_rules;
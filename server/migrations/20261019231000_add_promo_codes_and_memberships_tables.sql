@@ -0,0 +1,73 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS promo_codes (
+  id SERIAL PRIMARY KEY,
+  code TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  value INT NOT NULL CHECK (value > 0),
+  resource_types TEXT[] NOT NULL DEFAULT '{}',
+  valid_from TIMESTAMPTZ,
+  valid_until TIMESTAMPTZ,
+  max_redemptions INT CHECK (max_redemptions > 0),
+  max_per_user INT CHECK (max_per_user > 0),
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT percent_value CHECK (kind <> 'percent' OR value <= 100)
+);
+
+CREATE UNIQUE INDEX promo_codes_code_idx ON promo_codes (UPPER(code));
+
+-- redemptions outlive purged bookings for reports, so codes which were redeemed can only be disabled
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+  id SERIAL PRIMARY KEY,
+  promo_code_id INT NOT NULL,
+  user_id INT NOT NULL,
+  booking_id INT UNIQUE,
+  amount INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_promo_code FOREIGN KEY (promo_code_id) REFERENCES promo_codes (id)
+    ON DELETE RESTRICT,
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings (id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX promo_redemptions_code_idx ON promo_redemptions (promo_code_id, user_id);
+
+CREATE TABLE IF NOT EXISTS membership_plans (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  included_hours INT NOT NULL CHECK (included_hours > 0),
+  resource_types TEXT[] NOT NULL DEFAULT '{}',
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL,
+  plan_id INT NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT fk_plan FOREIGN KEY (plan_id) REFERENCES membership_plans (id)
+    ON DELETE RESTRICT,
+  CONSTRAINT end_after_start CHECK (end_date >= start_date)
+);
+
+CREATE INDEX memberships_user_idx ON memberships (user_id, start_date);
+
+-- +goose Down
+DROP TABLE memberships;
+DROP TABLE membership_plans;
+DROP TABLE promo_redemptions;
+DROP TABLE promo_codes;
@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS booking_groups (
+  id SERIAL PRIMARY KEY,
+  organiser_id INT NOT NULL,
+  start_time TIMESTAMPTZ NOT NULL,
+  end_time TIMESTAMPTZ NOT NULL,
+  text TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_organiser FOREIGN KEY (organiser_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX booking_groups_organiser_idx ON booking_groups (organiser_id, start_time);
+
+ALTER TABLE bookings ADD COLUMN group_id INT;
+ALTER TABLE bookings ADD CONSTRAINT fk_group FOREIGN KEY (group_id) REFERENCES booking_groups (id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE;
+
+CREATE INDEX bookings_group_idx ON bookings (group_id);
+
+CREATE TABLE IF NOT EXISTS group_participants (
+  group_id INT NOT NULL,
+  user_id INT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'invited',
+  invited_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  responded_at TIMESTAMPTZ,
+
+  PRIMARY KEY (group_id, user_id),
+  CONSTRAINT fk_group FOREIGN KEY (group_id) REFERENCES booking_groups (id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX group_participants_user_idx ON group_participants (user_id);
+
+-- +goose Down
+DROP TABLE group_participants;
+DROP INDEX bookings_group_idx;
+ALTER TABLE bookings DROP COLUMN group_id;
+DROP TABLE booking_groups;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE resources ADD COLUMN capacity INT NOT NULL DEFAULT 1 CONSTRAINT positive_capacity CHECK (capacity > 0);
+ALTER TABLE bookings ADD COLUMN party_size INT NOT NULL DEFAULT 1 CONSTRAINT positive_party_size CHECK (party_size > 0);
+ALTER TABLE waitlist_entries ADD COLUMN party_size INT NOT NULL DEFAULT 1 CONSTRAINT positive_party_size CHECK (party_size > 0);
+
+-- +goose Down
+ALTER TABLE waitlist_entries DROP COLUMN party_size;
+ALTER TABLE bookings DROP COLUMN party_size;
+ALTER TABLE resources DROP COLUMN capacity;
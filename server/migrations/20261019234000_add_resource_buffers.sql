@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE resources ADD COLUMN buffer_before INT NOT NULL DEFAULT 0 CONSTRAINT non_negative_buffer_before CHECK (buffer_before >= 0);
+ALTER TABLE resources ADD COLUMN buffer_after INT NOT NULL DEFAULT 0 CONSTRAINT non_negative_buffer_after CHECK (buffer_after >= 0);
+
+-- +goose Down
+ALTER TABLE resources DROP COLUMN buffer_after;
+ALTER TABLE resources DROP COLUMN buffer_before;
@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE resources ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS booking_approvals (
+  booking_id INT PRIMARY KEY,
+  status TEXT NOT NULL DEFAULT 'pending',
+  deadline TIMESTAMPTZ NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  reviewed_by INT,
+  reviewed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings (id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_reviewer FOREIGN KEY (reviewed_by) REFERENCES users (id)
+    ON DELETE SET NULL
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX booking_approvals_status_idx ON booking_approvals (status, deadline);
+
+-- +goose Down
+DROP TABLE booking_approvals;
+ALTER TABLE resources DROP COLUMN requires_approval;
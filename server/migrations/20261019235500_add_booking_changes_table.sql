@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS booking_changes (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL,
+  kind TEXT NOT NULL,
+  old_resource_id INT NOT NULL,
+  new_resource_id INT NOT NULL,
+  old_start_time TIMESTAMPTZ NOT NULL,
+  old_end_time TIMESTAMPTZ NOT NULL,
+  new_start_time TIMESTAMPTZ NOT NULL,
+  new_end_time TIMESTAMPTZ NOT NULL,
+  old_price INT NOT NULL,
+  new_price INT NOT NULL,
+  changed_by INT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings (id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_changed_by FOREIGN KEY (changed_by) REFERENCES users (id)
+    ON DELETE SET NULL
+    ON UPDATE CASCADE
+);
+
+CREATE INDEX booking_changes_booking_idx ON booking_changes (booking_id, created_at);
+
+-- +goose Down
+DROP TABLE booking_changes;
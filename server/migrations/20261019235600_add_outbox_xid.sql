@@ -0,0 +1,14 @@
+-- +goose Up
+-- Positions are taken when events are written but transactions commit in any order, so events are
+-- read in the order of the transactions which wrote them, once no older transaction is running
+ALTER TABLE outbox ADD COLUMN xid XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+DROP INDEX outbox_pending_idx;
+CREATE INDEX outbox_pending_idx ON outbox (xid, position) WHERE delivered_at IS NULL;
+CREATE INDEX outbox_xid_idx ON outbox (xid, position);
+
+-- +goose Down
+DROP INDEX outbox_xid_idx;
+DROP INDEX outbox_pending_idx;
+ALTER TABLE outbox DROP COLUMN xid;
+CREATE INDEX outbox_pending_idx ON outbox (position) WHERE delivered_at IS NULL;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- occurrence_start is the time the recurrence rule gives an occurrence, which stays when the
+-- occurrence is moved; overridden marks occurrences changed on their own
+ALTER TABLE bookings
+  ADD COLUMN occurrence_start TIMESTAMPTZ,
+  ADD COLUMN overridden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- moved occurrences can not be told apart any more, so every occurrence is taken as unchanged
+UPDATE bookings SET occurrence_start = start_time WHERE series_id IS NOT NULL;
+
+CREATE INDEX bookings_occurrence_idx ON bookings (series_id, occurrence_start) WHERE series_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX bookings_occurrence_idx;
+ALTER TABLE bookings
+  DROP COLUMN overridden,
+  DROP COLUMN occurrence_start;
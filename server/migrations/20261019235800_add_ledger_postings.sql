@@ -0,0 +1,60 @@
+-- +goose Up
+-- Every ledger entry is booked as postings which sum to zero: the amount is credited to the
+-- account of its user (account users) and debited from an account of the venue
+CREATE TABLE IF NOT EXISTS ledger_postings (
+  id BIGSERIAL PRIMARY KEY,
+  entry_id BIGINT NOT NULL,
+  account TEXT NOT NULL,
+  user_id INT,
+  amount INT NOT NULL CHECK (amount <> 0),
+
+  CONSTRAINT fk_entry FOREIGN KEY (entry_id) REFERENCES ledger_entries (id)
+    ON DELETE RESTRICT,
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+    ON DELETE RESTRICT
+    ON UPDATE CASCADE,
+  -- only postings to the accounts of users name a user
+  CONSTRAINT user_account CHECK ((account = 'users') = (user_id IS NOT NULL))
+);
+
+CREATE INDEX ledger_postings_entry_idx ON ledger_postings (entry_id);
+CREATE INDEX ledger_postings_user_idx ON ledger_postings (user_id) WHERE user_id IS NOT NULL;
+
+INSERT INTO ledger_postings (entry_id, account, user_id, amount)
+  SELECT id, 'users', user_id, amount FROM ledger_entries
+  UNION ALL
+  SELECT id, account, NULL, -amount FROM ledger_entries
+  ORDER BY 1;
+
+-- +goose StatementBegin
+CREATE FUNCTION check_ledger_balanced() RETURNS trigger AS $$
+DECLARE
+  entry BIGINT;
+BEGIN
+  IF TG_TABLE_NAME = 'ledger_entries' THEN
+    entry := NEW.id;
+  ELSE
+    entry := NEW.entry_id;
+  END IF;
+
+  IF (SELECT COUNT(*) < 2 OR SUM(amount) <> 0 FROM ledger_postings WHERE entry_id = entry) THEN
+    RAISE EXCEPTION 'ledger entry % is not balanced', entry;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- checked at commit, when all postings of an entry were added
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced AFTER INSERT ON ledger_entries
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION check_ledger_balanced();
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced AFTER INSERT OR UPDATE ON ledger_postings
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION check_ledger_balanced();
+
+-- +goose Down
+DROP TRIGGER ledger_postings_balanced ON ledger_postings;
+DROP TRIGGER ledger_entries_balanced ON ledger_entries;
+DROP FUNCTION check_ledger_balanced();
+DROP TABLE ledger_postings;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- ledger entries are kept for accounting, so users who have one can not be deleted
+ALTER TABLE ledger_entries DROP CONSTRAINT fk_user;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+  ON DELETE RESTRICT
+  ON UPDATE CASCADE;
+
+-- +goose Down
+ALTER TABLE ledger_entries DROP CONSTRAINT fk_user;
+ALTER TABLE ledger_entries ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE;
@@ -0,0 +1,8 @@
+// Package migrations embeds the goose migrations of the database schema, so that the binary does
+// not depend on its working directory. New migrations are added as <timestamp>_<name>.sql files.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS